@@ -0,0 +1,179 @@
+package hyper
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/c0c0n3/resto/hyper/wire"
+	"github.com/c0c0n3/resto/mime"
+)
+
+// MediaRange is an element of an "Accept" header: a media type, possibly
+// with wildcards, e.g. "text/*", and the weight the client gives it.
+type MediaRange struct {
+	// The media type or range, e.g. "application/json", "text/*" or
+	// "*/*". Parsed ranges always have lowercase type and subtype.
+	Type mime.MediaType
+	// Any media type parameters other than the weight, e.g. "level=1"
+	// in "text/html;level=1". Parameter names are lowercase.
+	Params map[string]string
+	// The weight, i.e. a number between 0 and 1 where 0 means the media
+	// type isn't acceptable and 1 is the client's favourite.
+	Q float64
+}
+
+// Weighted builds a MediaRange with the given weight.
+func Weighted(mediaType mime.MediaType, q float64) MediaRange {
+	return MediaRange{Type: mediaType, Q: q}
+}
+
+// String formats the range as it appears in an "Accept" header. The
+// weight only shows up if it's less than 1.
+func (r MediaRange) String() string {
+	var out strings.Builder
+	out.WriteString(r.Type.String())
+	names := make([]string, 0, len(r.Params))
+	for name := range r.Params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		out.WriteString(";" + name + "=" + r.Params[name])
+	}
+	if r.Q < 1 {
+		q := strconv.FormatFloat(math.Round(r.Q*1000)/1000, 'f', -1, 64)
+		out.WriteString(";q=" + q)
+	}
+	return out.String()
+}
+
+// Write an "Accept" header with the specified media ranges and their
+// weights, e.g.
+//
+//     WriteWeightedAccept(msg,
+//         Weighted(mime.JSON, 1),
+//         Weighted(mime.YAML, 0.5),
+//     )
+//     // Accept: application/json, application/yaml;q=0.5
+//
+// Weights must be between 0 and 1, both inclusive.
+func WriteWeightedAccept(msg wire.MessageWriter, ranges ...MediaRange) error {
+	if msg == nil {
+		return NilMessageWriterErr()
+	}
+	rs := []string{}
+	for _, r := range ranges {
+		if r.Q < 0 || r.Q > 1 || math.IsNaN(r.Q) {
+			return InvalidHeaderErr("weight out of range: %v", r.Q)
+		}
+		rs = append(rs, r.String())
+	}
+	if len(rs) > 0 {
+		return msg.Header("Accept", strings.Join(rs, ", "))
+	}
+	return nil
+}
+
+// ParseAccept parses the value of an "Accept" header into a list of
+// media ranges, in the same order as they appear in the header. Ranges
+// with no weight get a weight of 1. ParseAccept skips any malformed
+// ranges and any "Accept" extension parameters after the weight.
+func ParseAccept(header string) []MediaRange {
+	ranges := []MediaRange{}
+	for _, element := range strings.Split(header, ",") {
+		if r, ok := parseMediaRange(element); ok {
+			ranges = append(ranges, r)
+		}
+	}
+	return ranges
+}
+
+func parseMediaRange(element string) (MediaRange, bool) {
+	parts := strings.Split(element, ";")
+	mediaType := strings.ToLower(strings.TrimSpace(parts[0]))
+	tpe, subtype, found := strings.Cut(mediaType, "/")
+	if !found || tpe == "" || subtype == "" || (tpe == "*" && subtype != "*") {
+		return MediaRange{}, false
+	}
+
+	r := MediaRange{Type: mime.MediaType(mediaType), Params: map[string]string{}, Q: 1}
+	for _, p := range parts[1:] {
+		name, value, _ := strings.Cut(p, "=")
+		name = strings.ToLower(strings.TrimSpace(name))
+		value = strings.Trim(strings.TrimSpace(value), `"`)
+		if name == "" {
+			continue
+		}
+		if name == "q" {
+			q, err := strconv.ParseFloat(value, 64)
+			if err != nil || q < 0 || q > 1 {
+				return MediaRange{}, false
+			}
+			r.Q = q
+			break // anything after q is an extension param, not ours
+		}
+		r.Params[name] = value
+	}
+	return r, true
+}
+
+// matches tells if the range matches the given media type and, if it
+// does, how specific the match is. The more specific, the higher the
+// returned number, where a match of "*/*" is the least specific.
+func (r MediaRange) matches(offer mime.ParsedType) (int, bool) {
+	tpe, subtype, _ := strings.Cut(r.Type.String(), "/")
+	pattern := mime.ParsedType{Type: tpe, Subtype: subtype, Params: r.Params}
+	if !pattern.Matches(offer) {
+		return 0, false
+	}
+	switch {
+	case tpe == "*":
+		return 0, true
+	case subtype == "*":
+		return 1, true
+	}
+	return 2 + len(r.Params), true
+}
+
+// Negotiate picks the media type, among the ones the server offers, the
+// client prefers according to the given "Accept" header, as explained
+// in RFC 9110, section 12.5.1.
+//
+// Each offered media type gets the weight of the most specific range in
+// the header that matches it, e.g. given "text/*;q=0.5, text/html" the
+// weight of "text/html" is 1 whereas "text/plain" gets 0.5. Negotiate
+// returns the offer with the highest weight, picking the first one in
+// the list if there's a tie, so list offers in order of preference. An
+// empty header means the client accepts anything, so you get the first
+// offer. If no offer is acceptable, i.e. they all have a weight of 0,
+// Negotiate returns false.
+func Negotiate(accept string, offers ...mime.MediaType) (mime.MediaType, bool) {
+	if len(offers) == 0 {
+		return "", false
+	}
+	if strings.TrimSpace(accept) == "" {
+		return offers[0], true
+	}
+	ranges := ParseAccept(accept)
+
+	var best mime.MediaType
+	bestQ := 0.0
+	for _, offer := range offers {
+		parsedOffer, err := offer.Parse()
+		if err != nil {
+			continue
+		}
+		q, specificity := 0.0, -1
+		for _, r := range ranges {
+			if s, ok := r.matches(parsedOffer); ok && s > specificity {
+				q, specificity = r.Q, s
+			}
+		}
+		if q > bestQ {
+			best, bestQ = offer, q
+		}
+	}
+	return best, bestQ > 0
+}
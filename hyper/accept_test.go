@@ -0,0 +1,97 @@
+package hyper
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/c0c0n3/resto/mime"
+	e "github.com/c0c0n3/resto/util/err"
+)
+
+func TestWriteWeightedAccept(t *testing.T) {
+	msg := newMsgWriter(t)
+	WriteWeightedAccept(msg,
+		Weighted(mime.JSON, 1),
+		Weighted(mime.YAML, 0.5),
+		Weighted("*/*", 0.12345),
+		Weighted(mime.PLAIN_TEXT, 0),
+	)
+	want := "application/json, application/yaml;q=0.5, */*;q=0.123, text/plain;q=0"
+	msg.assertHeader("Accept", want)
+}
+
+func TestWriteWeightedAcceptWithParams(t *testing.T) {
+	msg := newMsgWriter(t)
+	r := MediaRange{
+		Type:   "text/html",
+		Params: map[string]string{"level": "1", "charset": "utf-8"},
+		Q:      0.8,
+	}
+	WriteWeightedAccept(msg, r)
+	msg.assertHeader("Accept", "text/html;charset=utf-8;level=1;q=0.8")
+}
+
+func TestWriteWeightedAcceptInvalidWeight(t *testing.T) {
+	for _, q := range []float64{-0.1, 1.1} {
+		msg := newMsgWriter(t)
+		err := WriteWeightedAccept(msg, Weighted(mime.JSON, q))
+		if _, ok := err.(e.Err[InvalidHeader]); !ok {
+			t.Errorf("[%v] want: invalid header err; got: %v", q, err)
+		}
+		msg.assertNoHeader("Accept")
+	}
+}
+
+func TestWriteWeightedAcceptNilWriter(t *testing.T) {
+	err := WriteWeightedAccept(nil, Weighted(mime.JSON, 1))
+	if _, ok := err.(e.Err[NilPtr]); !ok {
+		t.Errorf("want: nil ptr err; got: %v", err)
+	}
+}
+
+func TestParseAccept(t *testing.T) {
+	got := ParseAccept(
+		`Text/HTML;Level="1";q=0.7;ext=x, bogus, */html, application/*;q=2, */*;q=0.1`)
+	want := []MediaRange{
+		{Type: "text/html", Params: map[string]string{"level": "1"}, Q: 0.7},
+		{Type: "*/*", Params: map[string]string{}, Q: 0.1},
+	}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("want: %v; got: %v", want, got)
+	}
+}
+
+func TestParseEmptyAccept(t *testing.T) {
+	if got := ParseAccept(""); len(got) != 0 {
+		t.Errorf("want: no ranges; got: %v", got)
+	}
+}
+
+var negotiateFixtures = []struct {
+	accept string
+	offers []mime.MediaType
+	want   mime.MediaType
+	ok     bool
+}{
+	{"", []mime.MediaType{mime.YAML, mime.JSON}, mime.YAML, true},
+	{"application/json", []mime.MediaType{mime.YAML, mime.JSON}, mime.JSON, true},
+	{"application/json;q=0.5, application/yaml", []mime.MediaType{mime.JSON, mime.YAML}, mime.YAML, true},
+	{"application/*", []mime.MediaType{mime.PLAIN_TEXT, mime.JSON}, mime.JSON, true},
+	{"*/*", []mime.MediaType{mime.PLAIN_TEXT, mime.JSON}, mime.PLAIN_TEXT, true},
+	{"text/*;q=0.5, text/plain;q=0", []mime.MediaType{mime.PLAIN_TEXT}, "", false},
+	{"application/*;q=0.2, application/json", []mime.MediaType{mime.YAML, mime.JSON}, mime.JSON, true},
+	{"*/*;q=0.1, application/yaml;q=0", []mime.MediaType{mime.YAML, mime.JSON}, mime.JSON, true},
+	{"APPLICATION/JSON", []mime.MediaType{mime.YAML, mime.JSON}, mime.JSON, true},
+	{"text/html;level=1, text/html;q=0.4", []mime.MediaType{"text/html", "text/html;level=1"}, "text/html;level=1", true},
+	{"image/png", []mime.MediaType{mime.YAML, mime.JSON}, "", false},
+	{"application/json", []mime.MediaType{}, "", false},
+}
+
+func TestNegotiate(t *testing.T) {
+	for k, f := range negotiateFixtures {
+		got, ok := Negotiate(f.accept, f.offers...)
+		if got != f.want || ok != f.ok {
+			t.Errorf("[%d] want: %s, %v; got: %s, %v", k, f.want, f.ok, got, ok)
+		}
+	}
+}
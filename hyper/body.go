@@ -2,6 +2,7 @@ package hyper
 
 import (
 	"encoding/json"
+	"encoding/xml"
 	"io"
 
 	"github.com/c0c0n3/resto/hyper/wire"
@@ -34,10 +35,22 @@ type BodyDeserializer interface {
 	Deserialize(body io.ReadCloser) error
 }
 
+// BodyHeaders is implemented by BodySerializers that have to write some
+// headers to describe the body, e.g. a multipart body has to write a
+// "Content-Type" header with the boundary that separates its parts.
+type BodyHeaders interface {
+	// WriteHeaders writes the headers to the given message. WriteBody
+	// calls it after Serialize, so the headers can depend on how the
+	// serializer set up the body.
+	WriteHeaders(msg wire.MessageWriter) error
+}
+
 // Write a message body with the given content.
 // Also write a "Content-Length" header with the size of the content
 // if the content objects knows upfront how many bytes it'll write to
-// the body---i.e. non-streaming content.
+// the body---i.e. non-streaming content. If the content implements
+// BodyHeaders, write its headers too. If the message is a
+// wire.BodyTransferWriter, tell it whether the content is streamed.
 func WriteBody(msg wire.MessageWriter, content BodySerializer) error {
 	if msg == nil {
 		return NilMessageWriterErr()
@@ -55,6 +68,16 @@ func WriteBody(msg wire.MessageWriter, content BodySerializer) error {
 			return err
 		}
 	}
+	if headers, ok := content.(BodyHeaders); ok {
+		if err := headers.WriteHeaders(msg); err != nil {
+			return err
+		}
+	}
+	if transfer, ok := msg.(wire.BodyTransferWriter); ok {
+		if err := transfer.BodyTransfer(content.Streaming()); err != nil {
+			return err
+		}
+	}
 	return msg.Body(contentReader)
 }
 
@@ -131,6 +154,26 @@ func (p *JsonBody) Deserialize(reader io.ReadCloser) error {
 	// json-iterator here too.
 }
 
+// XmlBody holds a data structure that needs to be (de-)serialized
+// (from) to an HTTP body octet stream containing XML data.
+type XmlBody struct {
+	Data any
+}
+
+func (p *XmlBody) Streaming() bool {
+	return false
+}
+
+func (p *XmlBody) Serialize() (io.ReadCloser, int, error) {
+	buf, err := xml.Marshal(p.Data)
+	return bytez.NewBufferFrom(buf), len(buf), err
+}
+
+func (p *XmlBody) Deserialize(reader io.ReadCloser) error {
+	decoder := xml.NewDecoder(ensureReader(reader))
+	return decoder.Decode(p.Data)
+}
+
 // StringBody holds a string that needs to be (de-)serialized (from) to
 // an HTTP body octet stream containing text.
 type StringBody struct {
@@ -6,6 +6,7 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/c0c0n3/resto/hyper/wire"
 	e "github.com/c0c0n3/resto/util/err"
 )
 
@@ -94,6 +95,25 @@ func TestWriteStreamingBody(t *testing.T) {
 	}
 }
 
+func TestRecordedStreamingBodyIsNotBuffered(t *testing.T) {
+	record, _ := wire.Record(func(req wire.RequestWriter) error {
+		if err := WriteContentLength(req, 3); err != nil {
+			return err
+		}
+		return WriteBody(req, &StreamingBody{newByteStreamer([]byte{1, 2, 3})})
+	})
+	if ok, err := record.BufferBody(); ok || err != nil {
+		t.Errorf("want: not buffered; got: %v, %v", ok, err)
+	}
+
+	record, _ = wire.Record(func(req wire.RequestWriter) error {
+		return WriteBody(req, &ByteBody{[]byte{1, 2, 3}})
+	})
+	if ok, err := record.BufferBody(); !ok || err != nil {
+		t.Errorf("want: buffered; got: %v, %v", ok, err)
+	}
+}
+
 var readStreamingBodyFixtures = []string{
 	"", "1", "12345678",
 }
@@ -0,0 +1,215 @@
+package cache
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/c0c0n3/resto/hyper"
+	"github.com/c0c0n3/resto/hyper/wire"
+	"github.com/c0c0n3/resto/util/bytez"
+	e "github.com/c0c0n3/resto/util/err"
+)
+
+func nilStoreErr() e.Err[hyper.NilPtr] {
+	return e.Mk[hyper.NilPtr]("nil Store")
+}
+
+// cachedResponse is a ResponseReader to hand a cache entry back to the
+// caller. Each one gets its own copy of the headers and its own body
+// reader, so callers can't mess with the entry.
+type cachedResponse struct {
+	headers http.Header
+	entry   Entry
+}
+
+func newCachedResponse(entry Entry) *cachedResponse {
+	return &cachedResponse{headers: entry.Headers.Clone(), entry: entry}
+}
+
+func (p *cachedResponse) Header(name string) string {
+	return p.headers.Get(name)
+}
+
+func (p *cachedResponse) Headers() map[string][]string {
+	return p.headers
+}
+
+func (p *cachedResponse) Body() io.ReadCloser {
+	return bytez.Reader(p.entry.Body)
+}
+
+func (p *cachedResponse) StatusLine() (code wire.StatusCode, reason string) {
+	return p.entry.StatusCode, p.entry.Reason
+}
+
+type cacher struct {
+	store Store
+	next  wire.Sender
+	now   func() time.Time
+}
+
+// bypass tells if the request isn't one for the cache to deal with.
+// That's the case if the caller sends conditional or range requests
+// themselves, since then they expect to see the server's actual reply.
+func bypass(request *wire.RequestRecorder) bool {
+	if request.Method != wire.GET || request.Url == nil {
+		return true
+	}
+	for _, name := range []string{
+		"If-None-Match", "If-Modified-Since", "If-Match",
+		"If-Unmodified-Since", "If-Range", "Range",
+	} {
+		if request.Headers.Get(name) != "" {
+			return true
+		}
+	}
+	return parseCacheControl(request.Headers).has("no-store")
+}
+
+// invalidate removes the entry for the URL of a request that changed
+// the resource, e.g. a successful PUT.
+func (p *cacher) invalidate(request *wire.RequestRecorder, response wire.ResponseReader) {
+	if request.Url == nil ||
+		request.Method == wire.GET || request.Method == wire.HEAD {
+		return
+	}
+	if code, _ := response.StatusLine(); code.Value() < 400 {
+		p.store.Delete(request.Url.WireFormat())
+	}
+}
+
+func (p *cacher) send(build wire.RequestBuilder) (wire.ResponseReader, error) {
+	if p.store == nil {
+		return nil, nilStoreErr()
+	}
+	request, err := wire.Record(build)
+	if err != nil {
+		return nil, err
+	}
+	if bypass(request) {
+		response, err := p.next(request.Replay)
+		if err == nil {
+			p.invalidate(request, response)
+		}
+		return response, err
+	}
+
+	key := request.Url.WireFormat()
+	entry, found := p.store.Get(key)
+	found = found && entry.matchesVary(request.Headers)
+	if found {
+		directives := parseCacheControl(request.Headers)
+		if !directives.has("no-cache") && entry.fresh(p.now(), directives) {
+			return newCachedResponse(entry), nil
+		}
+		if etag := entry.Headers.Get("ETag"); etag != "" {
+			request.Headers.Set("If-None-Match", etag)
+		}
+		if modified := entry.Headers.Get("Last-Modified"); modified != "" {
+			request.Headers.Set("If-Modified-Since", modified)
+		}
+	}
+
+	response, err := p.next(request.Replay)
+	if err != nil {
+		return nil, err
+	}
+	received := p.now()
+	code, _ := response.StatusLine()
+
+	if found && code.Value() == http.StatusNotModified {
+		discard(response)
+		entry = refresh(entry, response, received)
+		p.store.Put(key, entry)
+		return newCachedResponse(entry), nil
+	}
+	if code.Value() != http.StatusOK {
+		return response, nil
+	}
+	headers := http.Header(response.Headers())
+	if !storable(code.Value(), headers, generatedAt(headers, received)) ||
+		!shareable(request.Headers, headers) {
+		p.store.Delete(key)
+		return response, nil
+	}
+	return p.put(key, request, response, received)
+}
+
+func (p *cacher) put(key string, request *wire.RequestRecorder,
+	response wire.ResponseReader, received time.Time) (wire.ResponseReader, error) {
+	body := response.Body()
+	defer body.Close()
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+
+	code, reason := response.StatusLine()
+	headers := http.Header(response.Headers()).Clone()
+	entry := Entry{
+		StatusCode:     code,
+		Reason:         reason,
+		Headers:        headers,
+		Body:           data,
+		RequestHeaders: varyHeaders(headers, request.Headers),
+		Stored:         generatedAt(headers, received),
+	}
+	p.store.Put(key, entry)
+	return newCachedResponse(entry), nil
+}
+
+// refresh updates the entry with the headers in the 304 response the
+// server sent to revalidate it, as explained in RFC 9111 §4.3.4.
+func refresh(entry Entry, notModified wire.ResponseReader, received time.Time) Entry {
+	headers := entry.Headers.Clone()
+	for name, values := range notModified.Headers() {
+		if http.CanonicalHeaderKey(name) == "Content-Length" {
+			continue
+		}
+		headers[http.CanonicalHeaderKey(name)] = values
+	}
+	entry.Headers = headers
+	entry.Stored = generatedAt(headers, received)
+	return entry
+}
+
+func discard(response wire.ResponseReader) {
+	body := response.Body()
+	io.Copy(io.Discard, io.LimitReader(body, 64*1024))
+	body.Close()
+}
+
+// Middleware builds a Sender decorator to cache responses to GET
+// requests in the given Store.
+//
+// For each GET request, the decorated Sender looks up a response in the
+// Store keyed by request URL. If there's one and it's still fresh, the
+// Sender returns it without contacting the server. If it's gone stale,
+// the Sender adds "If-None-Match" and "If-Modified-Since" headers to the
+// request to revalidate it, using the response "ETag" and "Last-Modified"
+// headers, respectively. If the server replies with a 304, the Sender
+// returns the cached response, which is a 200, with its headers updated
+// from the 304. Otherwise the Sender returns the server's response,
+// caching it if it's a 200 the server didn't mark as "no-store" and
+// which is either fresh for a while or has got a validator. A response
+// that isn't fresh for any time at all, e.g. one with "no-cache", still
+// gets cached if it has a validator, but then the Sender revalidates it
+// every time.
+//
+// The Sender respects the "no-store", "no-cache" and "max-age" request
+// directives. It doesn't touch requests that already have conditional
+// or range headers since the caller must want to see the server's reply
+// in that case. Responses with a "Vary" header only get served to
+// requests with the same values for the headers listed in "Vary".
+// Successful requests with a method other than GET or HEAD evict the
+// response cached for their URL.
+//
+// Store errors never make a request fail. If the Store can't add an
+// entry, you just get the server's response without caching.
+func Middleware(store Store) wire.Middleware {
+	return func(next wire.Sender) wire.Sender {
+		c := &cacher{store: store, next: next, now: time.Now}
+		return c.send
+	}
+}
@@ -0,0 +1,370 @@
+package cache
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/c0c0n3/resto/hyper"
+	"github.com/c0c0n3/resto/hyper/wire"
+	e "github.com/c0c0n3/resto/util/err"
+	"github.com/c0c0n3/resto/yoorel"
+)
+
+// configServer serves a resource with a version number, replying with a
+// 304 to conditional requests for the current version.
+type configServer struct {
+	version  string
+	headers  http.Header
+	requests []*http.Request
+}
+
+func (p *configServer) send(req *http.Request) (*http.Response, error) {
+	p.requests = append(p.requests, req)
+	etag := `"` + p.version + `"`
+	res := &http.Response{
+		StatusCode: 200,
+		Status:     "200 OK",
+		Header:     p.headers.Clone(),
+		Body:       io.NopCloser(strings.NewReader("config " + p.version)),
+	}
+	res.Header.Set("ETag", etag)
+	if req.Method == "GET" && req.Header.Get("If-None-Match") == etag {
+		res.StatusCode = 304
+		res.Status = "304 Not Modified"
+		res.Body = io.NopCloser(strings.NewReader(""))
+	}
+	return res, nil
+}
+
+func (p *configServer) lastRequest() *http.Request {
+	return p.requests[len(p.requests)-1]
+}
+
+type clock struct {
+	t time.Time
+}
+
+func (p *clock) now() time.Time {
+	return p.t
+}
+
+func (p *clock) advance(d time.Duration) {
+	p.t = p.t.Add(d)
+}
+
+func newTestSender(server *configServer) (wire.Sender, *clock) {
+	tick := &clock{t: time.Date(2022, 5, 1, 10, 0, 0, 0, time.UTC)}
+	c := &cacher{
+		store: NewMemoryStore(10),
+		next:  wire.NewSender(server.send),
+		now:   tick.now,
+	}
+	return c.send, tick
+}
+
+func request(verb wire.Method, headers ...string) wire.RequestBuilder {
+	return func(req wire.RequestWriter) error {
+		url := yoorel.BuilderFrom("http://my.api/config").Build().Right()
+		if err := req.RequestLine(verb, url); err != nil {
+			return err
+		}
+		for k := 0; k+1 < len(headers); k += 2 {
+			if err := req.Header(headers[k], headers[k+1]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func assertResponse(t *testing.T, res wire.ResponseReader, err error, body string) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("want: response; got: %v", err)
+	}
+	code, _ := res.StatusLine()
+	data, _ := io.ReadAll(res.Body())
+	if code != 200 || string(data) != body {
+		t.Errorf("want: 200 %s; got: %d %s", body, code, data)
+	}
+}
+
+func TestServeFreshResponseFromCache(t *testing.T) {
+	server := &configServer{
+		version: "v1",
+		headers: http.Header{"Cache-Control": {"max-age=60"}},
+	}
+	send, tick := newTestSender(server)
+
+	res, err := send(request(wire.GET))
+	assertResponse(t, res, err, "config v1")
+	tick.advance(30 * time.Second)
+	res, err = send(request(wire.GET))
+	assertResponse(t, res, err, "config v1")
+
+	if len(server.requests) != 1 {
+		t.Errorf("want: 1 request; got: %d", len(server.requests))
+	}
+}
+
+func TestExpiresWithoutDateGoesByClock(t *testing.T) {
+	server := &configServer{
+		version: "v1",
+		headers: http.Header{"Expires": {"Sun, 01 May 2022 10:01:00 GMT"}},
+	}
+	send, tick := newTestSender(server)
+
+	res, err := send(request(wire.GET))
+	assertResponse(t, res, err, "config v1")
+	tick.advance(59 * time.Second)
+	res, err = send(request(wire.GET))
+	assertResponse(t, res, err, "config v1")
+	if len(server.requests) != 1 {
+		t.Errorf("want: fresh until expires; got: %d requests", len(server.requests))
+	}
+
+	tick.advance(time.Second)
+	send(request(wire.GET))
+	if len(server.requests) != 2 {
+		t.Errorf("want: stale at expires; got: %d requests", len(server.requests))
+	}
+}
+
+func TestDontShareCredentialedResponses(t *testing.T) {
+	for _, name := range []string{"Authorization", "Cookie"} {
+		server := &configServer{
+			version: "v1",
+			headers: http.Header{"Cache-Control": {"max-age=60"}},
+		}
+		send, _ := newTestSender(server)
+
+		res, err := send(request(wire.GET, name, "user a"))
+		assertResponse(t, res, err, "config v1")
+		res, err = send(request(wire.GET, name, "user b"))
+		assertResponse(t, res, err, "config v1")
+
+		if len(server.requests) != 2 {
+			t.Errorf("[%s] want: 2 requests; got: %d", name, len(server.requests))
+		}
+		if got := server.lastRequest().Header.Get(name); got != "user b" {
+			t.Errorf("[%s] want: user b; got: %s", name, got)
+		}
+	}
+}
+
+func TestSharePublicCredentialedResponses(t *testing.T) {
+	server := &configServer{
+		version: "v1",
+		headers: http.Header{"Cache-Control": {"public, max-age=60"}},
+	}
+	send, _ := newTestSender(server)
+
+	res, err := send(request(wire.GET, "Authorization", "user a"))
+	assertResponse(t, res, err, "config v1")
+	res, err = send(request(wire.GET, "Authorization", "user b"))
+	assertResponse(t, res, err, "config v1")
+
+	if len(server.requests) != 1 {
+		t.Errorf("want: 1 request; got: %d", len(server.requests))
+	}
+}
+
+func TestRevalidateStaleResponse(t *testing.T) {
+	server := &configServer{
+		version: "v1",
+		headers: http.Header{"Cache-Control": {"max-age=60"}},
+	}
+	send, tick := newTestSender(server)
+
+	send(request(wire.GET))
+	tick.advance(2 * time.Minute)
+	res, err := send(request(wire.GET))
+
+	assertResponse(t, res, err, "config v1")
+	if got := server.lastRequest().Header.Get("If-None-Match"); got != `"v1"` {
+		t.Errorf("want: If-None-Match v1; got: %s", got)
+	}
+	if len(server.requests) != 2 {
+		t.Errorf("want: 2 requests; got: %d", len(server.requests))
+	}
+
+	tick.advance(30 * time.Second)
+	res, err = send(request(wire.GET))
+	assertResponse(t, res, err, "config v1")
+	if len(server.requests) != 2 {
+		t.Errorf("want: 304 to refresh entry; got: %d requests",
+			len(server.requests))
+	}
+}
+
+func TestReplaceChangedResponse(t *testing.T) {
+	server := &configServer{
+		version: "v1",
+		headers: http.Header{"Cache-Control": {"no-cache"}},
+	}
+	send, _ := newTestSender(server)
+
+	send(request(wire.GET))
+	server.version = "v2"
+	res, err := send(request(wire.GET))
+	assertResponse(t, res, err, "config v2")
+
+	res, err = send(request(wire.GET))
+	assertResponse(t, res, err, "config v2")
+	if got := server.lastRequest().Header.Get("If-None-Match"); got != `"v2"` {
+		t.Errorf("want: If-None-Match v2; got: %s", got)
+	}
+}
+
+func TestRevalidateWithLastModified(t *testing.T) {
+	modified := "Sun, 01 May 2022 09:00:00 GMT"
+	server := &configServer{
+		version: "v1",
+		headers: http.Header{"Last-Modified": {modified}},
+	}
+	send, _ := newTestSender(server)
+
+	send(request(wire.GET))
+	send(request(wire.GET))
+	if got := server.lastRequest().Header.Get("If-Modified-Since"); got != modified {
+		t.Errorf("want: If-Modified-Since; got: %s", got)
+	}
+}
+
+func TestRequestDirectives(t *testing.T) {
+	server := &configServer{
+		version: "v1",
+		headers: http.Header{"Cache-Control": {"max-age=60"}},
+	}
+	send, tick := newTestSender(server)
+
+	send(request(wire.GET))
+	send(request(wire.GET, "Cache-Control", "no-cache"))
+	if len(server.requests) != 2 {
+		t.Errorf("want: no-cache to revalidate; got: %d requests",
+			len(server.requests))
+	}
+
+	tick.advance(20 * time.Second)
+	send(request(wire.GET, "Cache-Control", "max-age=10"))
+	if len(server.requests) != 3 {
+		t.Errorf("want: max-age to revalidate; got: %d requests",
+			len(server.requests))
+	}
+
+	send(request(wire.GET, "Cache-Control", "no-store"))
+	if got := server.lastRequest().Header.Get("If-None-Match"); got != "" {
+		t.Errorf("want: no-store to bypass cache; got: %s", got)
+	}
+}
+
+func TestDontCacheNoStore(t *testing.T) {
+	server := &configServer{
+		version: "v1",
+		headers: http.Header{"Cache-Control": {"no-store"}},
+	}
+	send, _ := newTestSender(server)
+
+	send(request(wire.GET))
+	res, err := send(request(wire.GET))
+	assertResponse(t, res, err, "config v1")
+	if got := server.lastRequest().Header.Get("If-None-Match"); got != "" {
+		t.Errorf("want: no conditional request; got: %s", got)
+	}
+}
+
+func TestPassThroughCallerConditionalRequest(t *testing.T) {
+	server := &configServer{
+		version: "v1",
+		headers: http.Header{"Cache-Control": {"max-age=60"}},
+	}
+	send, _ := newTestSender(server)
+
+	send(request(wire.GET))
+	res, err := send(request(wire.GET, "If-None-Match", `"v1"`))
+	if err != nil {
+		t.Fatalf("want: response; got: %v", err)
+	}
+	if code, _ := res.StatusLine(); code != 304 {
+		t.Errorf("want: 304; got: %d", code)
+	}
+}
+
+func TestVary(t *testing.T) {
+	server := &configServer{
+		version: "v1",
+		headers: http.Header{
+			"Cache-Control": {"max-age=60"},
+			"Vary":          {"Accept"},
+		},
+	}
+	send, _ := newTestSender(server)
+
+	send(request(wire.GET, "Accept", "text/plain"))
+	send(request(wire.GET, "Accept", "text/plain"))
+	if len(server.requests) != 1 {
+		t.Errorf("want: cache hit; got: %d requests", len(server.requests))
+	}
+	send(request(wire.GET, "Accept", "application/json"))
+	if len(server.requests) != 2 {
+		t.Errorf("want: cache miss; got: %d requests", len(server.requests))
+	}
+}
+
+func TestUnsafeMethodInvalidatesEntry(t *testing.T) {
+	server := &configServer{
+		version: "v1",
+		headers: http.Header{"Cache-Control": {"max-age=60"}},
+	}
+	send, _ := newTestSender(server)
+
+	send(request(wire.GET))
+	send(request(wire.PUT))
+	send(request(wire.GET))
+	if len(server.requests) != 3 {
+		t.Errorf("want: PUT to evict entry; got: %d requests",
+			len(server.requests))
+	}
+}
+
+func TestCachedResponseIsolation(t *testing.T) {
+	server := &configServer{
+		version: "v1",
+		headers: http.Header{"Cache-Control": {"max-age=60"}},
+	}
+	send, _ := newTestSender(server)
+
+	res, _ := send(request(wire.GET))
+	res.Headers()["Cache-Control"] = []string{"no-cache"}
+	res, err := send(request(wire.GET))
+
+	assertResponse(t, res, err, "config v1")
+	if len(server.requests) != 1 {
+		t.Errorf("want: entry unchanged; got: %d requests",
+			len(server.requests))
+	}
+}
+
+func TestSendErrors(t *testing.T) {
+	failing := func(req *http.Request) (*http.Response, error) {
+		return nil, errors.New("boom")
+	}
+	send := Middleware(NewMemoryStore(1))(wire.NewSender(failing))
+	if _, err := send(request(wire.GET)); err == nil || err.Error() != "boom" {
+		t.Errorf("want: boom; got: %v", err)
+	}
+
+	send = Middleware(nil)(wire.NewSender(failing))
+	if _, err := send(request(wire.GET)); !isNilPtr(err) {
+		t.Errorf("want: nil ptr err; got: %v", err)
+	}
+}
+
+func isNilPtr(err error) bool {
+	_, ok := err.(e.Err[hyper.NilPtr])
+	return ok
+}
@@ -0,0 +1,73 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+type diskStore struct {
+	dir string
+}
+
+// NewDiskStore builds a Store that keeps each entry in a JSON file in
+// the given directory, creating the directory if it isn't there. Entries
+// stay on disk until you delete them, so they survive restarts, but
+// there's no limit on how much space they take up.
+//
+// The Store writes each file atomically, so concurrent writers can't
+// corrupt an entry. Get treats an entry it can't read back as missing.
+func NewDiskStore(dir string) (Store, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &diskStore{dir: dir}, nil
+}
+
+func (p *diskStore) path(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return filepath.Join(p.dir, hex.EncodeToString(hash[:])+".json")
+}
+
+func (p *diskStore) Get(key string) (Entry, bool) {
+	data, err := os.ReadFile(p.path(key))
+	if err != nil {
+		return Entry{}, false
+	}
+	var entry Entry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return Entry{}, false
+	}
+	return entry, true
+}
+
+func (p *diskStore) Put(key string, entry Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(p.dir, "entry-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // no-op after a successful rename
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), p.path(key))
+}
+
+func (p *diskStore) Delete(key string) error {
+	err := os.Remove(p.path(key))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,62 @@
+package cache
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDiskStoreRoundTrip(t *testing.T) {
+	store, err := NewDiskStore(filepath.Join(t.TempDir(), "cache"))
+	if err != nil {
+		t.Fatalf("want: store; got: %v", err)
+	}
+	entry := Entry{
+		StatusCode:     200,
+		Reason:         "200 OK",
+		Headers:        http.Header{"Etag": {`"v1"`}},
+		Body:           []byte("config"),
+		RequestHeaders: http.Header{"Accept": {"text/plain"}},
+		Stored:         time.Date(2022, 5, 1, 10, 0, 0, 0, time.UTC),
+	}
+	if err := store.Put("http://my.api/config", entry); err != nil {
+		t.Fatalf("want: put; got: %v", err)
+	}
+
+	got, ok := store.Get("http://my.api/config")
+	if !ok || !reflect.DeepEqual(entry, got) {
+		t.Errorf("want: %v; got: %v", entry, got)
+	}
+	if _, ok := store.Get("http://my.api/other"); ok {
+		t.Errorf("want: no entry")
+	}
+
+	if err := store.Delete("http://my.api/config"); err != nil {
+		t.Errorf("want: delete; got: %v", err)
+	}
+	if err := store.Delete("http://my.api/config"); err != nil {
+		t.Errorf("want: no error deleting missing entry; got: %v", err)
+	}
+	if _, ok := store.Get("http://my.api/config"); ok {
+		t.Errorf("want: entry deleted")
+	}
+}
+
+func TestDiskStoreSkipsCorruptEntries(t *testing.T) {
+	dir := t.TempDir()
+	store, _ := NewDiskStore(dir)
+	store.Put("k", entryWithBody("x"))
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	if len(files) != 1 {
+		t.Fatalf("want: 1 file; got: %v", files)
+	}
+	os.WriteFile(files[0], []byte("{not json"), 0o644)
+
+	if _, ok := store.Get("k"); ok {
+		t.Errorf("want: corrupt entry treated as missing")
+	}
+}
@@ -0,0 +1,33 @@
+/*
+Package cache provides a wire.Middleware to cache the responses to GET
+requests on the client side, the way a browser would, so you don't
+have to download the same content over and over again.
+
+The Middleware follows the caching rules in RFC 9111 for a private
+cache, though it only implements the bits you're most likely to need.
+It keeps successful responses around for as long as the server says
+they're fresh through the "Cache-Control" or "Expires" headers. Once a
+response goes stale, the Middleware asks the server if it changed by
+sending a conditional request with the "If-None-Match" and/or
+"If-Modified-Since" headers. If the server replies with a 304, the
+Middleware hands the cached response back to you as a 200, so your
+response handlers don't need to know about caching at all.
+
+Since the same Sender often sends requests on behalf of different
+users, the Middleware doesn't keep the response to a request with an
+"Authorization" or "Cookie" header unless the server marks it "public".
+
+Where the responses go is up to the Store you pass in. This package
+comes with an in-memory LRU Store and a Store that keeps responses on
+disk, but you can easily plug in your own. Here's an example of how
+to cache the responses to the requests a client sends
+
+    send := wire.Chain(
+        wire.NewSender[wire.DefaultClient](),
+        cache.Middleware(cache.NewMemoryStore(100)),
+    )
+    c := client.New(send)
+    err := c.Request(GET("https://my.api/config")).Handle(...)
+
+*/
+package cache
@@ -0,0 +1,168 @@
+package cache
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cacheControl holds the directives in "Cache-Control" headers keyed by
+// lowercase name. Directives with no argument map to an empty string.
+type cacheControl map[string]string
+
+func parseCacheControl(headers http.Header) cacheControl {
+	directives := cacheControl{}
+	for _, line := range headers.Values("Cache-Control") {
+		for _, d := range strings.Split(line, ",") {
+			name, value, _ := strings.Cut(strings.TrimSpace(d), "=")
+			name = strings.ToLower(strings.TrimSpace(name))
+			if name == "" {
+				continue
+			}
+			if _, seen := directives[name]; !seen {
+				directives[name] = strings.Trim(strings.TrimSpace(value), `"`)
+			}
+		}
+	}
+	return directives
+}
+
+func (cc cacheControl) has(directive string) bool {
+	_, ok := cc[directive]
+	return ok
+}
+
+// seconds returns the value of a delta-seconds directive like "max-age".
+func (cc cacheControl) seconds(directive string) (time.Duration, bool) {
+	value, ok := cc[directive]
+	if !ok {
+		return 0, false
+	}
+	secs, err := strconv.ParseInt(value, 10, 32)
+	if err != nil || secs < 0 {
+		return 0, true // invalid means stale, see RFC 9111 §1.2.2
+	}
+	return time.Duration(secs) * time.Second, true
+}
+
+// freshness returns how long a response stays fresh after the server
+// generated it at the given time. Responses with "no-cache" are stale
+// right away, so they always need revalidating. We don't do heuristic
+// freshness, so a response with no explicit expiry is stale right away
+// too.
+func freshness(headers http.Header, generated time.Time) time.Duration {
+	cc := parseCacheControl(headers)
+	if cc.has("no-cache") {
+		return 0
+	}
+	if maxAge, ok := cc.seconds("max-age"); ok {
+		return maxAge
+	}
+	if value := headers.Get("Expires"); value != "" {
+		expires, err := http.ParseTime(value)
+		if err != nil {
+			return 0
+		}
+		date, err := http.ParseTime(headers.Get("Date"))
+		if err != nil {
+			return expires.Sub(generated)
+		}
+		return expires.Sub(date)
+		// NOTE. Without a "Date" header, RFC 9111 §4.2.1 says to use the
+		// time we got the response instead, which generated is as good
+		// as. Since fresh compares the lifetime with the age since
+		// generated, the entry stays fresh until Expires by our clock.
+	}
+	return 0
+}
+
+// generatedAt works out when the server generated a response we got at
+// the given time, going by the "Age" header if there's one.
+func generatedAt(headers http.Header, received time.Time) time.Time {
+	secs, err := strconv.ParseInt(headers.Get("Age"), 10, 32)
+	if err != nil || secs < 0 {
+		return received
+	}
+	return received.Add(-time.Duration(secs) * time.Second)
+}
+
+func hasValidators(headers http.Header) bool {
+	return headers.Get("ETag") != "" || headers.Get("Last-Modified") != ""
+}
+
+// shareable tells if we can cache the given response to a request with
+// the given headers. RFC 9111 §3.5 says a shared cache mustn't keep the
+// response to a request with an "Authorization" header unless the server
+// says it's fine to. Since many callers, e.g. a service forwarding each
+// user's token, share the same Sender, we play it safe and do the same,
+// also for "Cookie" since cookies often tell users apart too. We only
+// take "public" as a go-ahead though.
+func shareable(request http.Header, response http.Header) bool {
+	if request.Get("Authorization") == "" && request.Get("Cookie") == "" {
+		return true
+	}
+	return parseCacheControl(response).has("public")
+}
+
+// storable tells if we can cache the given response to a GET request.
+// We only cache 200s, and only if they're either fresh for a while or
+// we can revalidate them later.
+func storable(code int, headers http.Header, generated time.Time) bool {
+	if code != http.StatusOK {
+		return false
+	}
+	if parseCacheControl(headers).has("no-store") {
+		return false
+	}
+	if strings.TrimSpace(headers.Get("Vary")) == "*" {
+		return false
+	}
+	return freshness(headers, generated) > 0 || hasValidators(headers)
+}
+
+func varyNames(headers http.Header) []string {
+	names := []string{}
+	for _, line := range headers.Values("Vary") {
+		for _, name := range strings.Split(line, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, http.CanonicalHeaderKey(name))
+			}
+		}
+	}
+	return names
+}
+
+// varyHeaders picks out of the request headers those the response
+// "Vary" header lists.
+func varyHeaders(response http.Header, request http.Header) http.Header {
+	picked := http.Header{}
+	for _, name := range varyNames(response) {
+		if values := request.Values(name); len(values) > 0 {
+			picked[name] = values
+		}
+	}
+	return picked
+}
+
+// matchesVary tells if the given request headers select the entry.
+func (e Entry) matchesVary(request http.Header) bool {
+	for _, name := range varyNames(e.Headers) {
+		want := strings.Join(e.RequestHeaders.Values(name), ",")
+		got := strings.Join(request.Values(name), ",")
+		if want != got {
+			return false
+		}
+	}
+	return true
+}
+
+// fresh tells if the entry is still fresh at the given time, taking
+// into account any "max-age" directive in the request.
+func (e Entry) fresh(now time.Time, request cacheControl) bool {
+	age := now.Sub(e.Stored)
+	if maxAge, ok := request.seconds("max-age"); ok && age > maxAge {
+		return false
+	}
+	return age < freshness(e.Headers, e.Stored)
+}
@@ -0,0 +1,74 @@
+package cache
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestParseCacheControl(t *testing.T) {
+	headers := http.Header{
+		"Cache-Control": {`Max-Age=60, no-cache`, `private="x", max-age=10`},
+	}
+	cc := parseCacheControl(headers)
+	if !cc.has("no-cache") || cc["private"] != "x" {
+		t.Errorf("want: no-cache and private; got: %v", cc)
+	}
+	if d, ok := cc.seconds("max-age"); !ok || d != time.Minute {
+		t.Errorf("want: first max-age; got: %v", d)
+	}
+}
+
+func TestFreshness(t *testing.T) {
+	date := "Sun, 01 May 2022 10:00:00 GMT"
+	generated := time.Date(2022, 5, 1, 10, 1, 0, 0, time.UTC)
+	cases := []struct {
+		headers http.Header
+		want    time.Duration
+	}{
+		{http.Header{"Cache-Control": {"max-age=30"}}, 30 * time.Second},
+		{http.Header{"Cache-Control": {"max-age=x"}}, 0},
+		{http.Header{"Cache-Control": {"max-age=30, no-cache"}}, 0},
+		{http.Header{
+			"Date":    {date},
+			"Expires": {"Sun, 01 May 2022 10:05:00 GMT"},
+		}, 5 * time.Minute},
+		{http.Header{
+			"Cache-Control": {"max-age=1"},
+			"Date":          {date},
+			"Expires":       {"Sun, 01 May 2022 10:05:00 GMT"},
+		}, time.Second},
+		{http.Header{
+			"Expires": {"Sun, 01 May 2022 10:05:00 GMT"},
+		}, 4 * time.Minute},
+		{http.Header{"Expires": {"0"}}, 0},
+		{http.Header{}, 0},
+	}
+	for k, c := range cases {
+		if got := freshness(c.headers, generated); got != c.want {
+			t.Errorf("[%d] want: %v; got: %v", k, c.want, got)
+		}
+	}
+}
+
+func TestStorable(t *testing.T) {
+	cases := []struct {
+		code    int
+		headers http.Header
+		want    bool
+	}{
+		{200, http.Header{"Cache-Control": {"max-age=30"}}, true},
+		{200, http.Header{"Etag": {`"v1"`}}, true},
+		{200, http.Header{"Last-Modified": {"Sun, 01 May 2022 10:00:00 GMT"}}, true},
+		{200, http.Header{"Cache-Control": {"no-cache"}, "Etag": {`"v1"`}}, true},
+		{200, http.Header{}, false},
+		{200, http.Header{"Cache-Control": {"no-store"}, "Etag": {`"v1"`}}, false},
+		{200, http.Header{"Vary": {"*"}, "Etag": {`"v1"`}}, false},
+		{404, http.Header{"Cache-Control": {"max-age=30"}}, false},
+	}
+	for k, c := range cases {
+		if got := storable(c.code, c.headers, time.Now()); got != c.want {
+			t.Errorf("[%d] want: %v; got: %v", k, c.want, got)
+		}
+	}
+}
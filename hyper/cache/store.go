@@ -0,0 +1,105 @@
+package cache
+
+import (
+	"container/list"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/c0c0n3/resto/hyper/wire"
+)
+
+// Entry is a response in the cache.
+type Entry struct {
+	// The response status code.
+	StatusCode wire.StatusCode
+	// The response reason phrase.
+	Reason string
+	// The response headers.
+	Headers http.Header
+	// The response body.
+	Body []byte
+	// The values of the request headers the response "Vary" header
+	// lists, if any.
+	RequestHeaders http.Header
+	// When the server generated the response, going by our clock, i.e.
+	// the time we got the response minus the value of any "Age" header.
+	Stored time.Time
+}
+
+// Store keeps cache entries keyed by request URL. Implementations must
+// be safe for concurrent use.
+type Store interface {
+	// Get returns the entry with the given key, if there's one.
+	Get(key string) (Entry, bool)
+	// Put adds an entry with the given key, replacing any entry that
+	// was already there.
+	Put(key string, entry Entry) error
+	// Delete removes the entry with the given key, if there's one.
+	Delete(key string) error
+}
+
+type memoryItem struct {
+	key   string
+	entry Entry
+}
+
+type memoryStore struct {
+	mutex    sync.Mutex
+	capacity int
+	items    map[string]*list.Element
+	lru      *list.List // front = most recently used
+}
+
+// NewMemoryStore builds a Store that keeps up to capacity entries in
+// memory. When the Store is full, adding an entry evicts the least
+// recently used one. If capacity is less than 1, the Store never evicts
+// anything.
+func NewMemoryStore(capacity int) Store {
+	return &memoryStore{
+		capacity: capacity,
+		items:    make(map[string]*list.Element),
+		lru:      list.New(),
+	}
+}
+
+func (p *memoryStore) Get(key string) (Entry, bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	item, ok := p.items[key]
+	if !ok {
+		return Entry{}, false
+	}
+	p.lru.MoveToFront(item)
+	return item.Value.(*memoryItem).entry, true
+}
+
+func (p *memoryStore) Put(key string, entry Entry) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if item, ok := p.items[key]; ok {
+		item.Value.(*memoryItem).entry = entry
+		p.lru.MoveToFront(item)
+		return nil
+	}
+	p.items[key] = p.lru.PushFront(&memoryItem{key: key, entry: entry})
+	if p.capacity > 0 && p.lru.Len() > p.capacity {
+		oldest := p.lru.Back()
+		p.lru.Remove(oldest)
+		delete(p.items, oldest.Value.(*memoryItem).key)
+	}
+	return nil
+}
+
+func (p *memoryStore) Delete(key string) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if item, ok := p.items[key]; ok {
+		p.lru.Remove(item)
+		delete(p.items, key)
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package cache
+
+import (
+	"testing"
+)
+
+func entryWithBody(body string) Entry {
+	return Entry{StatusCode: 200, Body: []byte(body)}
+}
+
+func TestMemoryStoreGetPutDelete(t *testing.T) {
+	store := NewMemoryStore(2)
+	if _, ok := store.Get("a"); ok {
+		t.Errorf("want: no entry")
+	}
+
+	store.Put("a", entryWithBody("1"))
+	store.Put("a", entryWithBody("2"))
+	if got, ok := store.Get("a"); !ok || string(got.Body) != "2" {
+		t.Errorf("want: 2; got: %v", got)
+	}
+
+	store.Delete("a")
+	store.Delete("a")
+	if _, ok := store.Get("a"); ok {
+		t.Errorf("want: entry deleted")
+	}
+}
+
+func TestMemoryStoreEvictsLeastRecentlyUsed(t *testing.T) {
+	store := NewMemoryStore(2)
+	store.Put("a", entryWithBody("a"))
+	store.Put("b", entryWithBody("b"))
+	store.Get("a")
+	store.Put("c", entryWithBody("c"))
+
+	if _, ok := store.Get("b"); ok {
+		t.Errorf("want: b evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok := store.Get(key); !ok {
+			t.Errorf("want: %s kept", key)
+		}
+	}
+}
+
+func TestUnboundedMemoryStore(t *testing.T) {
+	store := NewMemoryStore(0)
+	for _, key := range []string{"a", "b", "c"} {
+		store.Put(key, entryWithBody(key))
+	}
+	for _, key := range []string{"a", "b", "c"} {
+		if _, ok := store.Get(key); !ok {
+			t.Errorf("want: %s kept", key)
+		}
+	}
+}
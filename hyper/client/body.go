@@ -5,40 +5,103 @@ import (
 
 	"github.com/c0c0n3/resto/hyper"
 	"github.com/c0c0n3/resto/hyper/wire"
+	"github.com/c0c0n3/resto/mime"
 )
 
-// RequestBody represents some data structure to be written to an
-// HTTP message body. The Body function takes care of converting
-// the data to a sequence of HTTP body octets.
-type RequestBody interface {
-	[]byte | string | *hyper.JsonBody | *hyper.StreamingBody
-}
-
-func bodyContentToSerializer[T RequestBody](data T) hyper.BodySerializer {
+func bodyContentToSerializer(data any) (hyper.BodySerializer, bool) {
 	var serializer hyper.BodySerializer
-	switch target := any(data).(type) {
+	switch target := data.(type) {
 	case []byte:
 		serializer = &hyper.ByteBody{Data: target}
 	case string:
 		serializer = &hyper.StringBody{Data: target}
-	case *hyper.JsonBody:
-		serializer = target
-	case *hyper.StreamingBody:
+	case hyper.BodySerializer:
 		serializer = target
+	default:
+		return nil, false
 	}
-	return serializer
+	return serializer, true
 }
 
 // Body turns the given content into HTTP octets which it writes to
 // the message body. Also it writes a "Content-Length" header with
 // the size of the resulting octet sequence.
-func Body[T RequestBody](content T) wire.RequestBuilder {
-	serializer := bodyContentToSerializer(content)
+//
+// If the content is a string, a byte slice or a hyper.BodySerializer,
+// e.g. Json(data), Body writes it as is. Otherwise Body looks up an
+// encoder in hyper.Codecs for the "Content-Type" the request declares,
+// so you've got to write that header before Body. Example.
+//
+//     order := &Order{Id: 1, Items: 3}
+//     err := Request(
+//         POST("https://my.api/orders"),
+//         ContentType(mime.XML),
+//         Body(order),
+//     ).Handle(
+//         ExpectStatusCodeOneOf(200, 201),
+//     )
+//
+func Body[T any](content T) wire.RequestBuilder {
 	return func(msg wire.RequestWriter) error {
+		serializer, err := serializerFor(msg, content)
+		if err != nil {
+			return err
+		}
 		return hyper.WriteBody(msg, serializer)
 	}
 }
 
+// GzipBody works just like Body but gzip-compresses the body and writes
+// a "Content-Encoding: gzip" header. Streaming content, e.g. Stream or
+// Multipart, gets compressed on the fly as it goes out. Any other content
+// gets compressed in memory so "Content-Length" has the compressed size.
+//
+// Example.
+//
+//     file, _ := os.Open("huge.log")
+//     defer file.Close()
+//     err := Request(
+//         POST("https://my.api/logs"),
+//         ContentType(mime.PLAIN_TEXT),
+//         GzipBody(Stream(file)),
+//     ).Handle(
+//         ExpectStatusCodeOneOf(200, 201),
+//     )
+//
+// Make sure the server can take gzip request bodies, many can't.
+func GzipBody[T any](content T) wire.RequestBuilder {
+	return func(msg wire.RequestWriter) error {
+		serializer, err := serializerFor(msg, content)
+		if err != nil {
+			return err
+		}
+		return hyper.WriteBody(msg, &hyper.GzipBody{Content: serializer})
+	}
+}
+
+func serializerFor(msg wire.RequestWriter, content any) (hyper.BodySerializer, error) {
+	if serializer, ok := bodyContentToSerializer(content); ok {
+		return serializer, nil
+	}
+	contentType, ok := declaredContentType(msg)
+	if !ok {
+		return nil, hyper.NoCodecErr(
+			"no Content-Type to pick an encoder for %T", content)
+	}
+	return hyper.Codecs.Encoder(contentType, content)
+}
+
+// declaredContentType gets hold of the "Content-Type" header written to
+// the request so far, if the RequestWriter keeps track of it.
+func declaredContentType(msg wire.RequestWriter) (mime.MediaType, bool) {
+	peeker, ok := msg.(wire.HeaderPeeker)
+	if !ok {
+		return "", false
+	}
+	value := peeker.PeekHeader("Content-Type")
+	return mime.MediaType(value), value != ""
+}
+
 // Json lets you serialise the given data structure to JSON and write
 // data to the message body. Notice you use Json with the Body function
 // which takes care of writing a "Content-Length" header with the size
@@ -60,6 +123,26 @@ func Json(data any) *hyper.JsonBody {
 	return &hyper.JsonBody{Data: data}
 }
 
+// Form lets you serialise the given form data to URL-encoded content
+// and write it to the message body. The data can be a url.Values, a
+// map[string]string or a struct with "form" tags---see hyper.FormBody
+// for the encoding rules. Body takes care of writing the "Content-Type"
+// and "Content-Length" headers.
+//
+// Example.
+//
+//     login := &Login{User: "admin", Password: "s3cr3t"}
+//     err := Request(
+//         POST("https://legacy.api/login"),
+//         Body(Form(login)),
+//     ).Handle(
+//         ExpectSuccess,
+//     )
+//
+func Form(data any) *hyper.FormBody {
+	return &hyper.FormBody{Data: data}
+}
+
 // Stream the content of a reader to the message body.
 //
 // Example.
@@ -78,3 +161,23 @@ func Json(data any) *hyper.JsonBody {
 func Stream(data io.ReadCloser) *hyper.StreamingBody {
 	return &hyper.StreamingBody{Data: data}
 }
+
+// Multipart streams the given parts to the message body as
+// "multipart/form-data" content. Body takes care of writing a
+// "Content-Type" header with the boundary between parts, so you don't
+// have to. Example.
+//
+//     err := Request(
+//         POST("https://my.api/reports"),
+//         Body(Multipart(
+//             hyper.FormField("title", "Q3 report"),
+//             hyper.FormFilePath("report", "/data/q3.pdf"),
+//         )),
+//     ).Handle(
+//         ExpectStatusCodeOneOf(200, 201),
+//     )
+//
+// Files go out in constant space, no matter how big.
+func Multipart(parts ...hyper.FormPart) *hyper.MultipartBody {
+	return &hyper.MultipartBody{Parts: parts}
+}
@@ -0,0 +1,281 @@
+package client
+
+import (
+	"compress/gzip"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/c0c0n3/resto/hyper"
+	"github.com/c0c0n3/resto/hyper/wire"
+	"github.com/c0c0n3/resto/mime"
+	"github.com/c0c0n3/resto/util/bytez"
+	e "github.com/c0c0n3/resto/util/err"
+)
+
+func TestMultipartUpload(t *testing.T) {
+	var title, file, fileName string
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if err := r.ParseMultipartForm(1024); err != nil {
+				http.Error(w, err.Error(), 400)
+				return
+			}
+			title = r.FormValue("title")
+			f, header, err := r.FormFile("report")
+			if err != nil {
+				http.Error(w, err.Error(), 400)
+				return
+			}
+			data, _ := io.ReadAll(f)
+			file, fileName = string(data), header.Filename
+		}))
+	defer server.Close()
+
+	err := Request(
+		POST(server.URL),
+		Body(Multipart(
+			hyper.FormField("title", "Q3"),
+			hyper.FormFile("report", "q3.txt", strings.NewReader("all good")),
+		)),
+	).Handle(ExpectSuccess)
+
+	if err != nil {
+		t.Fatalf("want: upload; got: %v", err)
+	}
+	if title != "Q3" || file != "all good" || fileName != "q3.txt" {
+		t.Errorf("want: Q3, q3.txt, all good; got: %s, %s, %s",
+			title, fileName, file)
+	}
+}
+
+func TestReadMultipartResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			writer := multipart.NewWriter(w)
+			w.Header().Set("Content-Type", writer.FormDataContentType())
+			writer.WriteField("a", "1")
+			writer.WriteField("b", "2")
+			writer.Close()
+		}))
+	defer server.Close()
+
+	got := []string{}
+	err := Request(GET(server.URL)).Handle(
+		ExpectSuccess,
+		ReadMultipart(func(part *multipart.Part) error {
+			data, err := io.ReadAll(part)
+			got = append(got, part.FormName()+"="+string(data))
+			return err
+		}),
+	)
+
+	if err != nil {
+		t.Fatalf("want: parts; got: %v", err)
+	}
+	if strings.Join(got, "&") != "a=1&b=2" {
+		t.Errorf("want: a=1&b=2; got: %v", got)
+	}
+}
+
+func TestBodyEncodesByContentType(t *testing.T) {
+	mock := &mockClient{
+		resToSend: &http.Response{
+			StatusCode: 201,
+			Body:       bytez.NewBuffer(),
+		},
+	}
+	err := New(mock.Sender()).Request(
+		POST("https://my.api/data"),
+		ContentType("application/atom+xml"),
+		Body(MyData{Greeting: "howzit!"}),
+	).Handle(ExpectSuccess)
+	if err != nil {
+		t.Fatalf("want: success; got: %v", err)
+	}
+
+	got, _ := io.ReadAll(mock.capturedReq.Body)
+	want := "<MyData><Greeting>howzit!</Greeting></MyData>"
+	if string(got) != want {
+		t.Errorf("want: %s; got: %s", want, got)
+	}
+	if mock.capturedReq.ContentLength != int64(len(want)) {
+		t.Errorf("want: %d; got: %d", len(want), mock.capturedReq.ContentLength)
+	}
+}
+
+func TestBodyEncodesByRecordedContentType(t *testing.T) {
+	request, err := wire.Record(makeRequestBuilder(
+		ContentType(mime.JSON),
+		Body(MyData{Greeting: "howzit!"}),
+	))
+	if err != nil {
+		t.Fatalf("want: recorded request; got: %v", err)
+	}
+	request.BufferBody()
+	if got := string(request.BodyBytes()); got != `{"Greeting":"howzit!"}` {
+		t.Errorf("want: json; got: %s", got)
+	}
+}
+
+func TestBodyWithNoCodec(t *testing.T) {
+	mock := &mockClient{}
+	for _, ct := range []mime.MediaType{"", "image/png"} {
+		builders := []wire.RequestBuilder{POST("https://my.api/data")}
+		if ct != "" {
+			builders = append(builders, ContentType(ct))
+		}
+		builders = append(builders, Body(MyData{}))
+
+		err := New(mock.Sender()).Request(builders...).Handle()
+		if _, ok := err.(e.Err[hyper.NoCodec]); !ok {
+			t.Errorf("[%s] want: no codec error; got: %v", ct, err)
+		}
+	}
+}
+
+func gzipEchoServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			body := io.Reader(r.Body)
+			if r.Header.Get("Content-Encoding") == "gzip" {
+				reader, err := gzip.NewReader(r.Body)
+				if err != nil {
+					http.Error(w, err.Error(), 400)
+					return
+				}
+				body = reader
+			}
+			w.Header().Set("Content-Encoding", "gzip")
+			w.Header().Set("X-Content-Length", r.Header.Get("Content-Length"))
+			zipper := gzip.NewWriter(w)
+			io.Copy(zipper, body)
+			zipper.Close()
+		}))
+}
+
+func TestGzipBody(t *testing.T) {
+	server := gzipEchoServer()
+	defer server.Close()
+
+	noAutoDecode := &http.Client{
+		Transport: &http.Transport{DisableCompression: true},
+	}
+	client := New(wire.Chain(wire.NewSender(noAutoDecode), wire.Decompress()))
+	data := strings.Repeat("howzit! ", 1000)
+	bodies := map[string]wire.RequestBuilder{
+		"buffered":  GzipBody(data),
+		"streaming": GzipBody(Stream(io.NopCloser(strings.NewReader(data)))),
+		"codec":     GzipBody(MyData{Greeting: data}),
+	}
+	for name, body := range bodies {
+		var got string
+		var length string
+		err := client.Request(
+			POST(server.URL),
+			ContentType(mime.JSON),
+			body,
+		).Handle(
+			ExpectSuccess,
+			func(response wire.ResponseReader) error {
+				length = response.Header("X-Content-Length")
+				return nil
+			},
+			ReadAny(&got),
+		)
+		if err != nil {
+			t.Fatalf("[%s] want: echo; got: %v", name, err)
+		}
+		if !strings.Contains(got, data) {
+			t.Errorf("[%s] want: data back; got: %d bytes", name, len(got))
+		}
+		if name != "streaming" && (length == "" || len(length) >= 4) {
+			t.Errorf("[%s] want: compressed length; got: %s", name, length)
+		}
+	}
+}
+
+func TestNdjsonRoundTrip(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", r.Header.Get("Content-Type"))
+			io.Copy(w, r.Body)
+		}))
+	defer server.Close()
+
+	sent := make(chan MyData)
+	go func() {
+		for _, g := range []string{"a", "b", "c"} {
+			sent <- MyData{Greeting: g}
+		}
+		close(sent)
+	}()
+	got := []string{}
+	err := Request(
+		POST(server.URL),
+		Body(hyper.NdjsonFromChan(sent)),
+	).Handle(
+		ExpectSuccess,
+		ExpectContentType(mime.NDJSON),
+		ReadNdjson(func(d MyData) error {
+			got = append(got, d.Greeting)
+			return nil
+		}),
+	)
+
+	if err != nil {
+		t.Fatalf("want: echo; got: %v", err)
+	}
+	if strings.Join(got, " ") != "a b c" {
+		t.Errorf("want: a b c; got: %v", got)
+	}
+}
+
+func TestBodyWritesAnySerializerAsIs(t *testing.T) {
+	problem := &hyper.ProblemBody{Data: &hyper.Problem{Status: 400, Title: "nope"}}
+	cases := []struct {
+		contentType mime.MediaType
+		body        hyper.BodySerializer
+		want        string
+	}{
+		{mime.PROBLEM_JSON, problem, `"title":"nope"`},
+		{mime.XML, &hyper.XmlBody{Data: MyData{Greeting: "hi"}}, "<Greeting>hi</Greeting>"},
+	}
+	for _, k := range cases {
+		request, err := wire.Record(makeRequestBuilder(
+			ContentType(k.contentType),
+			Body(k.body),
+		))
+		if err != nil {
+			t.Fatalf("[%s] want: recorded request; got: %v", k.contentType, err)
+		}
+		request.BufferBody()
+		if got := string(request.BodyBytes()); !strings.Contains(got, k.want) {
+			t.Errorf("[%s] want: %s; got: %s", k.contentType, k.want, got)
+		}
+	}
+}
+
+func TestBodyEncodesWithPlainSender(t *testing.T) {
+	var got string
+	send := wire.NewSender(func(req *http.Request) (*http.Response, error) {
+		data, _ := io.ReadAll(req.Body)
+		got = string(data)
+		return &http.Response{StatusCode: 200, Body: bytez.NewBuffer()}, nil
+	})
+	_, err := send(makeRequestBuilder(
+		POST("https://my.api/data"),
+		ContentType(mime.JSON),
+		Body(map[string]int{"a": 1}),
+	))
+
+	if err != nil {
+		t.Fatalf("want: sent; got: %v", err)
+	}
+	if got != `{"a":1}` {
+		t.Errorf("want: json; got: %s", got)
+	}
+}
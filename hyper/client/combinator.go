@@ -0,0 +1,156 @@
+package client
+
+import (
+	"io"
+
+	"github.com/c0c0n3/resto/hyper"
+	"github.com/c0c0n3/resto/hyper/wire"
+	e "github.com/c0c0n3/resto/util/err"
+)
+
+// branchScope keeps track of whether any OnStatus or OnStatusRange
+// branch in a handler pipeline matched the response, so Otherwise knows
+// if it should run. Each pipeline gets its own scope, i.e. Handle and
+// any combinator that runs a list of handlers.
+type branchScope struct {
+	wire.ResponseReader
+	taken bool
+}
+
+func (p *branchScope) Unwrap() wire.ResponseReader {
+	return p.ResponseReader
+}
+
+func takeBranch(response wire.ResponseReader) bool {
+	scope, ok := response.(*branchScope)
+	if !ok {
+		return true
+	}
+	if scope.taken {
+		return false
+	}
+	scope.taken = true
+	return true
+}
+
+func sequence(response wire.ResponseReader, handlers []wire.ResponseHandler) error {
+	scope := &branchScope{ResponseReader: response}
+	for _, handle := range handlers {
+		if handle == nil {
+			return hyper.NilResponseHandlerErr()
+		}
+		if err := handle(scope); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// OnStatus builds a wire.ResponseHandler that runs the given handlers
+// only if the response status code is the specified one. The handlers
+// run in turn, stopping at the first one that errors out. If the status
+// code is different, OnStatus does nothing. Use it with OnStatusRange
+// and Otherwise to handle different responses in different ways, e.g.
+// to read a success body into one type and an error body into another
+//
+//     data := &MyData{}
+//     apiErr := &MyApiError{}
+//     err := Request(
+//         GET("https://my.api/data"),
+//     ).Handle(
+//         OnStatus(200, ReadJsonResponse(data)),
+//         OnStatusRange(400, 499, ReadJsonResponse(apiErr)),
+//         Otherwise(ExpectSuccess),
+//     )
+//
+func OnStatus(code int, handlers ...wire.ResponseHandler) wire.ResponseHandler {
+	return OnStatusRange(code, code, handlers...)
+}
+
+// OnStatusRange builds a wire.ResponseHandler that runs the given
+// handlers only if the response status code is between first and last,
+// both inclusive. Otherwise it works just like OnStatus.
+func OnStatusRange(first int, last int, handlers ...wire.ResponseHandler) wire.ResponseHandler {
+	return func(response wire.ResponseReader) error {
+		code, _ := response.StatusLine()
+		if code.Value() < first || code.Value() > last {
+			return nil
+		}
+		takeBranch(response)
+		return sequence(response, handlers)
+	}
+}
+
+// Otherwise builds a wire.ResponseHandler that runs the given handlers
+// only if none of the OnStatus or OnStatusRange handlers that come
+// before it in the same pipeline matched the response. See OnStatus
+// for an example.
+func Otherwise(handlers ...wire.ResponseHandler) wire.ResponseHandler {
+	return func(response wire.ResponseReader) error {
+		if !takeBranch(response) {
+			return nil
+		}
+		return sequence(response, handlers)
+	}
+}
+
+// Optional builds a wire.ResponseHandler that runs the given handlers,
+// stopping at the first one that errors out, but then swallows the
+// error. So the pipeline goes on even if the handlers fail. Example.
+//
+//     var size int64
+//     err := Request(
+//         GET("https://my.api/data"),
+//     ).Handle(
+//         ExpectSuccess,
+//         Optional(ReadContentLength(&size)),
+//         ReadResponse(output),
+//     )
+//
+func Optional(handlers ...wire.ResponseHandler) wire.ResponseHandler {
+	return func(response wire.ResponseReader) error {
+		sequence(response, handlers)
+		return nil
+	}
+}
+
+// FirstOf builds a wire.ResponseHandler that tries each of the given
+// handlers in turn until one succeeds. If a handler fails, FirstOf
+// tries the next one, giving it the response body from the start. If
+// they all fail, FirstOf returns all their errors. Example.
+//
+//     data := &MyData{}
+//     text := &hyper.StringBody{}
+//     err := Request(
+//         GET("https://my.api/data"),
+//     ).Handle(
+//         ExpectSuccess,
+//         FirstOf(ReadJsonResponse(data), ReadResponse(text)),
+//     )
+//
+// Since each handler has to see the whole body, FirstOf reads the body
+// into memory, so don't use it with huge bodies.
+func FirstOf(handlers ...wire.ResponseHandler) wire.ResponseHandler {
+	return func(response wire.ResponseReader) error {
+		if len(handlers) == 0 {
+			return hyper.UnexpectedResponseErr("FirstOf: no handlers to try")
+		}
+		body, err := io.ReadAll(response.Body())
+		if err != nil {
+			return err
+		}
+		errs := e.Stack()
+		for _, handle := range handlers {
+			if handle == nil {
+				return hyper.NilResponseHandlerErr()
+			}
+			buffered := bufferedResponse{response, body}
+			err := sequence(buffered, []wire.ResponseHandler{handle})
+			if err == nil {
+				return nil
+			}
+			errs = errs.Push(err)
+		}
+		return errs
+	}
+}
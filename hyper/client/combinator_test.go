@@ -0,0 +1,189 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/c0c0n3/resto/hyper"
+	"github.com/c0c0n3/resto/hyper/wire"
+	"github.com/c0c0n3/resto/util/bytez"
+	e "github.com/c0c0n3/resto/util/err"
+)
+
+type MyApiError struct {
+	Message string
+}
+
+func respondWith(code int, body string) *Response {
+	mock := &mockClient{
+		resToSend: &http.Response{
+			StatusCode: code,
+			Body:       bytez.NewBufferFrom([]byte(body)),
+		},
+	}
+	return New(mock.Sender()).Request(GET("https://my.api/data"))
+}
+
+func branchPipeline(data *MyData, apiErr *MyApiError, trace *[]string) []wire.ResponseHandler {
+	mark := func(label string) wire.ResponseHandler {
+		return func(wire.ResponseReader) error {
+			*trace = append(*trace, label)
+			return nil
+		}
+	}
+	return []wire.ResponseHandler{
+		OnStatus(200, mark("200"), ReadJsonResponse(data)),
+		OnStatusRange(400, 499, mark("4xx"), ReadJsonResponse(apiErr)),
+		Otherwise(mark("otherwise"), ExpectSuccess),
+	}
+}
+
+func TestOnStatusSuccessBranch(t *testing.T) {
+	data, apiErr, trace := &MyData{}, &MyApiError{}, []string{}
+	err := respondWith(200, `{"Greeting": "howzit!"}`).
+		Handle(branchPipeline(data, apiErr, &trace)...)
+
+	if err != nil {
+		t.Errorf("want: success; got: %v", err)
+	}
+	if data.Greeting != "howzit!" {
+		t.Errorf("want: howzit!; got: %v", data)
+	}
+	if fmt.Sprint(trace) != "[200]" {
+		t.Errorf("want: [200]; got: %v", trace)
+	}
+}
+
+func TestOnStatusRangeErrorBranch(t *testing.T) {
+	data, apiErr, trace := &MyData{}, &MyApiError{}, []string{}
+	err := respondWith(404, `{"Message": "gone"}`).
+		Handle(branchPipeline(data, apiErr, &trace)...)
+
+	if err != nil {
+		t.Errorf("want: success; got: %v", err)
+	}
+	if apiErr.Message != "gone" {
+		t.Errorf("want: gone; got: %v", apiErr)
+	}
+	if fmt.Sprint(trace) != "[4xx]" {
+		t.Errorf("want: [4xx]; got: %v", trace)
+	}
+}
+
+func TestOtherwiseBranch(t *testing.T) {
+	data, apiErr, trace := &MyData{}, &MyApiError{}, []string{}
+	err := respondWith(503, "down").
+		Handle(branchPipeline(data, apiErr, &trace)...)
+
+	var resErr *hyper.ResponseError
+	if !errors.As(err, &resErr) || resErr.StatusCode != 503 {
+		t.Errorf("want: response err; got: %v", err)
+	}
+	if fmt.Sprint(trace) != "[otherwise]" {
+		t.Errorf("want: [otherwise]; got: %v", trace)
+	}
+}
+
+func TestOnStatusBranchErrorStopsPipeline(t *testing.T) {
+	data, apiErr, trace := &MyData{}, &MyApiError{}, []string{}
+	err := respondWith(200, "not json").
+		Handle(branchPipeline(data, apiErr, &trace)...)
+
+	if err == nil {
+		t.Errorf("want: decoding error; got: nil")
+	}
+	if fmt.Sprint(trace) != "[200]" {
+		t.Errorf("want: [200]; got: %v", trace)
+	}
+}
+
+func TestNestedOtherwiseHasOwnScope(t *testing.T) {
+	ran := false
+	err := respondWith(201, "").Handle(
+		OnStatusRange(200, 299,
+			OnStatus(200, ExpectStatusCodeOneOf()),
+			Otherwise(func(wire.ResponseReader) error {
+				ran = true
+				return nil
+			}),
+		),
+	)
+
+	if err != nil {
+		t.Errorf("want: success; got: %v", err)
+	}
+	if !ran {
+		t.Errorf("want: nested otherwise to run")
+	}
+}
+
+func TestOptionalSwallowsErrors(t *testing.T) {
+	output := &hyper.StringBody{}
+	err := respondWith(500, "*").Handle(
+		Optional(ExpectSuccess),
+		ReadResponse(output),
+	)
+
+	if err != nil {
+		t.Errorf("want: success; got: %v", err)
+	}
+}
+
+func TestFirstOfFallsBack(t *testing.T) {
+	data := &MyData{}
+	text := &hyper.StringBody{}
+	err := respondWith(200, "not json").Handle(
+		FirstOf(ReadJsonResponse(data), ReadResponse(text)),
+	)
+
+	if err != nil {
+		t.Errorf("want: success; got: %v", err)
+	}
+	if text.Data != "not json" {
+		t.Errorf("want: whole body; got: %s", text.Data)
+	}
+}
+
+func TestFirstOfStopsAtFirstSuccess(t *testing.T) {
+	first, second := &hyper.StringBody{}, &hyper.StringBody{}
+	err := respondWith(200, "*").Handle(
+		FirstOf(ReadResponse(first), ReadResponse(second)),
+	)
+
+	if err != nil {
+		t.Errorf("want: success; got: %v", err)
+	}
+	if first.Data != "*" || second.Data != "" {
+		t.Errorf("want: first only; got: %s, %s", first.Data, second.Data)
+	}
+}
+
+func TestFirstOfAllFail(t *testing.T) {
+	err := respondWith(500, "").Handle(
+		FirstOf(ExpectSuccess, ExpectStatusCodeOneOf(200)),
+	)
+
+	if _, ok := err.(e.ErrStack); !ok {
+		t.Errorf("want: error stack; got: %v", err)
+	}
+}
+
+func TestFirstOfNoHandlers(t *testing.T) {
+	err := respondWith(200, "").Handle(FirstOf())
+	if _, ok := err.(e.Err[hyper.UnexpectedResponse]); !ok {
+		t.Errorf("want: unexpected response err; got: %v", err)
+	}
+}
+
+func TestCombinatorsNilHandler(t *testing.T) {
+	for _, handle := range []wire.ResponseHandler{
+		OnStatus(200, nil), Otherwise(nil), FirstOf(nil),
+	} {
+		err := respondWith(200, "").Handle(handle)
+		if _, ok := err.(e.Err[hyper.NilPtr]); !ok {
+			t.Errorf("want: nil ptr err; got: %v", err)
+		}
+	}
+}
@@ -0,0 +1,21 @@
+package client
+
+import (
+	"context"
+
+	"github.com/c0c0n3/resto/hyper"
+	"github.com/c0c0n3/resto/hyper/wire"
+)
+
+// WithContext attaches the given context to the request. The context
+// carries deadlines, cancellation signals and request-scoped values
+// along with the request. If you cancel the context, the request gets
+// aborted---see Client.RequestCtx for the details.
+func WithContext(ctx context.Context) wire.RequestBuilder {
+	return func(req wire.RequestWriter) error {
+		if ctx == nil {
+			return hyper.NilContextErr()
+		}
+		return req.Context(ctx)
+	}
+}
@@ -0,0 +1,134 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/c0c0n3/resto/hyper"
+	"github.com/c0c0n3/resto/hyper/wire"
+	"github.com/c0c0n3/resto/util/bytez"
+	e "github.com/c0c0n3/resto/util/err"
+)
+
+type ctxKey string
+
+func TestRequestCtxPassesContextOn(t *testing.T) {
+	mock := &mockClient{
+		resToSend: &http.Response{StatusCode: 200},
+	}
+	ctx := context.WithValue(context.Background(), ctxKey("k"), "v")
+
+	err := New(mock.Sender()).RequestCtx(ctx,
+		GET("https://my.api/data"),
+	).Handle(
+		ExpectSuccess,
+	)
+
+	if err != nil {
+		t.Errorf("want: server reply; got: %v", err)
+	}
+	if got := mock.capturedReq.Context().Value(ctxKey("k")); got != "v" {
+		t.Errorf("want: v; got: %v", got)
+	}
+}
+
+func TestWithContextNilContext(t *testing.T) {
+	mock := &mockClient{
+		resToSend: &http.Response{StatusCode: 200},
+	}
+	//lint:ignore SA1012 testing nil context handling
+	err := New(mock.Sender()).Request(
+		GET("https://my.api/data"),
+		WithContext(nil),
+	).Handle()
+
+	if _, ok := err.(e.Err[hyper.NilPtr]); !ok {
+		t.Errorf("want: nil ptr err; got: %v", err)
+	}
+}
+
+func TestCancelStopsHandlers(t *testing.T) {
+	mock := &mockClient{
+		resToSend: &http.Response{
+			StatusCode: 200,
+			Body:       bytez.NewBufferFrom([]byte("howzit!")),
+		},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancelNow := func(wire.ResponseReader) error {
+		cancel()
+		return nil
+	}
+	output := &hyper.StringBody{}
+
+	err := New(mock.Sender()).RequestCtx(ctx,
+		GET("https://my.api/data"),
+	).Handle(
+		cancelNow,
+		ReadResponse(output),
+	)
+
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("want: canceled; got: %v", err)
+	}
+	if output.Data != "" {
+		t.Errorf("want: no body read; got: %s", output.Data)
+	}
+}
+
+func TestCancelAbortsBodyRead(t *testing.T) {
+	mock := &mockClient{
+		resToSend: &http.Response{
+			StatusCode: 200,
+			Body:       bytez.NewBufferFrom([]byte("howzit!")),
+		},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	readAfterCancel := func(res wire.ResponseReader) error {
+		cancel()
+		_, err := io.ReadAll(res.Body())
+		return err
+	}
+
+	err := New(mock.Sender()).Request(
+		WithContext(ctx),
+		GET("https://my.api/data"),
+	).Handle(
+		readAfterCancel,
+	)
+
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("want: canceled; got: %v", err)
+	}
+}
+
+func TestCancelAbortsInFlightRequest(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-release:
+			case <-r.Context().Done():
+			}
+		}))
+	defer server.Close()
+	defer close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err := RequestCtx(ctx,
+		GET(server.URL),
+	).Handle(
+		ExpectSuccess,
+	)
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("want: deadline exceeded; got: %v", err)
+	}
+}
@@ -0,0 +1,117 @@
+package client
+
+import (
+	"net/http"
+	"net/http/cookiejar"
+	"strings"
+
+	"github.com/c0c0n3/resto/hyper"
+	"github.com/c0c0n3/resto/hyper/wire"
+	e "github.com/c0c0n3/resto/util/err"
+)
+
+func nilCookieErr() e.Err[hyper.NilPtr] {
+	return e.Mk[hyper.NilPtr]("nil Cookie")
+}
+
+// Cookie writes a "Cookie" header with the name and value of each given
+// cookie. Cookie ignores any other cookie attribute, like the path or
+// expiry date, since those only make sense in a "Set-Cookie" header.
+// Since a request must have at most one "Cookie" header, Cookie appends
+// the cookies to any "Cookie" header written earlier, e.g. by another
+// Cookie call. (That only works if the request writer is a
+// wire.HeaderPeeker, like the ones in the wire package. Otherwise put
+// all the cookies you want to send in one Cookie call.) Example.
+//
+//     err := Request(
+//         GET("https://legacy.api/data"),
+//         Cookie(&http.Cookie{Name: "JSESSIONID", Value: sessionId}),
+//     ).Handle(
+//         ExpectSuccess,
+//     )
+//
+func Cookie(cookies ...*http.Cookie) wire.RequestBuilder {
+	return func(msg wire.RequestWriter) error {
+		pairs := make([]string, 0, len(cookies))
+		for _, c := range cookies {
+			if c == nil {
+				return nilCookieErr()
+			}
+			pair := (&http.Cookie{Name: c.Name, Value: c.Value}).String()
+			if pair == "" {
+				return hyper.InvalidHeaderErr("invalid cookie name: %q", c.Name)
+			}
+			pairs = append(pairs, pair)
+		}
+		if len(pairs) == 0 {
+			return nil
+		}
+		if peeker, ok := msg.(wire.HeaderPeeker); ok {
+			if existing := peeker.PeekHeader("Cookie"); existing != "" {
+				pairs = append([]string{existing}, pairs...)
+			}
+		}
+		return hyper.WriteHeader(msg, "Cookie", strings.Join(pairs, "; "))
+	}
+}
+
+// ReadCookies builds a wire.ResponseHandler to read the cookies the
+// response sets through "Set-Cookie" headers into the given output.
+// The handler skips any malformed cookie. Example.
+//
+//     var cookies []*http.Cookie
+//     err := Request(
+//         POST("https://legacy.api/login"),
+//         Body(credentials),
+//     ).Handle(
+//         ExpectSuccess,
+//         ReadCookies(&cookies),
+//     )
+//
+func ReadCookies(output *[]*http.Cookie) wire.ResponseHandler {
+	return func(response wire.ResponseReader) error {
+		*output = wire.ResponseCookies(response)
+		return nil
+	}
+}
+
+// Session is a Client that keeps cookies across requests, like a
+// browser would. Any cookie the server sets in a response goes into
+// the Session's cookie jar and then the Session sends it back in any
+// later request the cookie applies to. Example.
+//
+//     session := NewSession()
+//     err := session.Request(
+//         POST("https://legacy.api/login"),
+//         Body(credentials),
+//     ).Handle(ExpectSuccess)
+//     ...
+//     data := Fetch[Data](session.Client, GET("https://legacy.api/data"))
+//
+type Session struct {
+	*Client
+	jar http.CookieJar
+}
+
+// NewSession makes a new Session with an empty cookie jar.
+//
+// If you pass no arguments, the Session uses an http.Client with the
+// jar to exchange messages, so it also keeps the cookies set by any
+// redirect response along the way---e.g. a login that redirects to a
+// home page. If you pass in your own wire.Sender, the Session decorates
+// it with wire.Cookies instead, which only sees the final response to
+// each request.
+func NewSession(transport ...wire.Sender) *Session {
+	jar, _ := cookiejar.New(nil) // never fails
+	if len(transport) == 0 || transport[0] == nil {
+		send := wire.NewSender(&http.Client{Jar: jar})
+		return &Session{Client: New(send), jar: jar}
+	}
+	send := wire.Chain(transport[0], wire.Cookies(jar))
+	return &Session{Client: New(send), jar: jar}
+}
+
+// Jar returns the Session's cookie jar.
+func (p *Session) Jar() http.CookieJar {
+	return p.jar
+}
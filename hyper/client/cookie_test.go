@@ -0,0 +1,129 @@
+package client
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/c0c0n3/resto/hyper"
+	e "github.com/c0c0n3/resto/util/err"
+)
+
+func TestCookieBuilder(t *testing.T) {
+	mock := &mockClient{resToSend: &http.Response{StatusCode: 200}}
+	New(mock.Sender()).Request(
+		GET("http://legacy.api"),
+		Cookie(
+			&http.Cookie{Name: "a", Value: "1", Path: "/ignored"},
+			&http.Cookie{Name: "b", Value: "two words"},
+		),
+	)
+
+	want := `a=1; b="two words"`
+	if got := mock.capturedReq.Header.Values("Cookie"); len(got) != 1 || got[0] != want {
+		t.Errorf("want: %s; got: %v", want, got)
+	}
+}
+
+func TestCookieBuilderAppends(t *testing.T) {
+	mock := &mockClient{resToSend: &http.Response{StatusCode: 200}}
+	New(mock.Sender()).Request(
+		GET("http://legacy.api"),
+		Cookie(&http.Cookie{Name: "a", Value: "1"}),
+		Cookie(&http.Cookie{Name: "b", Value: "2"}),
+	)
+
+	want := "a=1; b=2"
+	if got := mock.capturedReq.Header.Values("Cookie"); len(got) != 1 || got[0] != want {
+		t.Errorf("want: %s; got: %v", want, got)
+	}
+}
+
+func TestCookieBuilderErrors(t *testing.T) {
+	err := Request(GET("http://legacy.api"), Cookie(nil)).Handle()
+	if _, ok := err.(e.Err[hyper.NilPtr]); !ok {
+		t.Errorf("want: nil ptr err; got: %v", err)
+	}
+
+	err = Request(
+		GET("http://legacy.api"),
+		Cookie(&http.Cookie{Name: "bad name", Value: "1"}),
+	).Handle()
+	if _, ok := err.(e.Err[hyper.InvalidHeader]); !ok {
+		t.Errorf("want: invalid header err; got: %v", err)
+	}
+}
+
+func TestReadCookies(t *testing.T) {
+	header := http.Header{}
+	header.Add("Set-Cookie", "session=s3cr3t; Path=/; HttpOnly")
+	header.Add("Set-Cookie", "theme=dark")
+	mock := &mockClient{resToSend: &http.Response{
+		StatusCode: 200, Header: header,
+	}}
+
+	var cookies []*http.Cookie
+	err := New(mock.Sender()).Request(GET("http://legacy.api")).Handle(
+		ReadCookies(&cookies),
+	)
+
+	if err != nil {
+		t.Fatalf("want: cookies; got: %v", err)
+	}
+	if len(cookies) != 2 || cookies[0].Name != "session" ||
+		!cookies[0].HttpOnly || cookies[1].Value != "dark" {
+		t.Errorf("want: session and theme cookies; got: %v", cookies)
+	}
+}
+
+func loginServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/login":
+				http.SetCookie(w, &http.Cookie{Name: "session", Value: "s3cr3t"})
+				http.Redirect(w, r, "/home", http.StatusFound)
+			default:
+				if c, err := r.Cookie("session"); err != nil || c.Value != "s3cr3t" {
+					w.WriteHeader(http.StatusUnauthorized)
+				}
+			}
+		}))
+}
+
+func TestSessionKeepsCookiesAcrossRedirects(t *testing.T) {
+	server := loginServer()
+	defer server.Close()
+
+	session := NewSession()
+	err := session.Request(POST(server.URL + "/login")).Handle(ExpectSuccess)
+	if err != nil {
+		t.Fatalf("want: logged in; got: %v", err)
+	}
+	err = session.Request(GET(server.URL + "/data")).Handle(ExpectSuccess)
+	if err != nil {
+		t.Errorf("want: session cookie sent; got: %v", err)
+	}
+
+	target, _ := url.Parse(server.URL)
+	if cookies := session.Jar().Cookies(target); len(cookies) != 1 {
+		t.Errorf("want: session cookie in jar; got: %v", cookies)
+	}
+}
+
+func TestSessionWithCustomSender(t *testing.T) {
+	header := http.Header{}
+	header.Add("Set-Cookie", "session=s3cr3t")
+	mock := &mockClient{resToSend: &http.Response{
+		StatusCode: 200, Header: header,
+	}}
+	session := NewSession(mock.Sender())
+
+	session.Request(GET("http://legacy.api/login")).Handle()
+	session.Request(GET("http://legacy.api/data")).Handle()
+
+	if got := mock.capturedReq.Header.Get("Cookie"); got != "session=s3cr3t" {
+		t.Errorf("want: session cookie; got: %s", got)
+	}
+}
@@ -61,6 +61,16 @@ it
         Body("howdy, stranger"),
     )
 
+Mind you, for one-off headers there's no need to write your own builder,
+Header does the job. And you add query parameters to the request URL
+with QueryParam, no need to rebuild the URL
+
+    response := Request(
+        GET("http://you.api/greeting"),
+        Header("Content-Language", "en-ZA"),
+        QueryParam("lang", "af"),
+    )
+
 
 Response handling
 
@@ -86,6 +96,62 @@ returning, so handlers don't have to do that.
 So you can chain handlers to do more than one thing with the response
 so the code stays modular---single responsibility principle, anyone?
 
+When the server replies with a status code you didn't expect, handlers
+like ExpectSuccess return a *hyper.ResponseError holding the request
+method and URL, the status code, a few headers and the first bytes of
+the response body. (The URL comes without password and query string,
+see hyper.RedactUrl.) Use errors.As to branch on the status code
+
+    var resErr *hyper.ResponseError
+    if errors.As(err, &resErr) && resErr.StatusCode == 404 {
+        fmt.Printf("not found: %s\n", resErr.Url)
+    }
+
+Heads up, this is a breaking change. ExpectSuccess and
+ExpectStatusCodeOneOf used to return an err.Err[hyper.UnexpectedResponse]
+value, so code that checks the error with a type assertion like
+
+    if _, ok := failure.(err.Err[hyper.UnexpectedResponse]); ok { ... }
+
+won't match any more. The ResponseError wraps that error though, so
+use errors.As instead
+
+    var unexpected err.Err[hyper.UnexpectedResponse]
+    if errors.As(failure, &unexpected) { ... }
+
+You don't have to stick to a linear pipeline though. Combinators like
+OnStatus, OnStatusRange, Otherwise, FirstOf and Optional let you branch
+on the response, e.g. to read a success body into one type and an error
+body into another
+
+    err := response.Handle(
+        OnStatus(200, ReadJsonResponse(data)),
+        OnStatusRange(400, 499, ReadJsonResponse(apiErr)),
+        Otherwise(ExpectSuccess),
+    )
+
+If the API splits a collection into pages, Paginate gets them for you
+one at a time. You tell it how to get from one page to the next with
+a PageStrategy, e.g. following the "next" link in the response "Link"
+header, and then go through the pages or straight through the items
+
+    pages := Paginate(client, FollowNextLink[[]Repo](), GET(reposUrl))
+    repos := Items(pages, func(page []Repo) []Repo { return page })
+    for repos.Next() {
+        fmt.Println(repos.Item().Name)
+    }
+    if err := repos.Err(); err != nil { ... }
+
+Streams come in pieces too. ReadNdjson reads newline-delimited JSON
+one value at a time, whereas ReadEvents reads Server-Sent Events. To
+keep listening to an event stream across dropped connections, use
+Subscribe, which reconnects with the ID of the last event it got
+
+    err := Subscribe(ctx, client, func(event hyper.Event) error {
+        fmt.Println(event.Data)
+        return nil
+    }, GET(statusUrl))
+
 There's a fair bit of built-in handlers. If you need to write your
 own, keep in mind, like for request builders, a handler is just a
 function
@@ -215,6 +281,29 @@ code, you should see an output similar to the one below.
     error: <nil>
 
 
+Codecs
+
+Body and ReadAny don't have to be told how to (de-)serialize your data.
+If you give Body a value it doesn't know, it picks an encoder from the
+request "Content-Type". Likewise, ReadAny picks a decoder from the
+response "Content-Type". Out of the box, you get JSON, XML, URL-encoded
+forms, text and raw bytes, including suffixed types like
+"application/problem+json" or "application/atom+xml"
+
+    order := &Order{}
+    err := Request(
+        PUT("https://my.api/orders/1"),
+        ContentType(mime.XML),
+        Body(Order{Id: 1, Items: 3}),
+    ).Handle(
+        ExpectSuccess,
+        ReadAny(order),
+    )
+
+Register a hyper.Codec with hyper.Codecs to handle other media types,
+e.g. YAML or your in-house format.
+
+
 HTTP client
 
 The Request function we've been using so far is just a convenience
@@ -248,6 +337,60 @@ how you could do it
 
     fmt.Printf("error: %v", err)
 
+Use wire.NewRedirectSender instead if you want to control redirects,
+e.g. cap them or turn them off to get 3xx responses in your handlers.
+Either way, Response.FinalUrl tells you where the request ended up
+
+    sender := wire.NewRedirectSender(wire.RedirectPolicy{MaxRedirects: 3})
+    response := New(sender).Request(GET("http://some/stuff"))
+    err := response.Handle(ExpectSuccess)
+    fmt.Printf("final URL: %s", response.FinalUrl())
+
+Another option is to decorate a Sender with cross-cutting concerns like
+logging or retries. The wire package lets you stack as many decorators
+as you need through wire.Chain
+
+    sender := wire.Chain(
+        wire.NewSender[wire.DefaultClient](),
+        wire.Retry(wire.DefaultRetryPolicy()),
+    )
+    hyperc := New(sender)
+
+Add wire.Decompress to the chain to get gzip and deflate response
+bodies decoded, even with an http.Client that has compression turned
+off. Going the other way, GzipBody works just like Body but compresses
+the request body, streaming or not.
+
+If the server keeps track of you through cookies, use a Session.
+It's a Client with a cookie jar, so it sends back any cookie the
+server set in an earlier response
+
+    session := NewSession()
+    err := session.Request(POST("http://some/login"), ...).Handle()
+    err = session.Request(GET("http://some/stuff")).Handle()
+
+
+Deadlines and cancellation
+
+If you need to set a deadline for the message exchange or be able to
+cancel it, use RequestCtx instead of Request. RequestCtx attaches the
+given context to the request, so cancelling the context aborts the
+request as well as any response handler still reading the body. E.g.
+
+    ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+    defer cancel()
+
+    output := &hyper.StringBody{}
+    err := RequestCtx(ctx,
+        GET("http://some/stuff"),
+    ).Handle(
+        ExpectSuccess,
+        ReadResponse(output),
+    )
+
+Alternatively, slot the WithContext builder into the request assembly
+line as you'd do with any other builder.
+
 
 Unit testing
 
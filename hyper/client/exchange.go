@@ -1,8 +1,16 @@
 package client
 
 import (
+	"context"
+	"errors"
+	"io"
+	"net/url"
+	"strings"
+
 	"github.com/c0c0n3/resto/hyper"
 	"github.com/c0c0n3/resto/hyper/wire"
+	"github.com/c0c0n3/resto/util/bytez"
+	"github.com/c0c0n3/resto/yoorel"
 )
 
 // Client represents an HTTP client.
@@ -29,6 +37,9 @@ func New(transport ...wire.Sender) *Client {
 type Response struct {
 	requestError error
 	reader       wire.ResponseReader
+	ctx          context.Context
+	method       wire.Method
+	url          string
 }
 
 // Request makes an HTTP request using the given builders and returns
@@ -48,10 +59,129 @@ func (p *Client) Request(fields ...wire.RequestBuilder) *Response {
 			return &Response{requestError: hyper.NilRequestBuilderErr()}
 		}
 	}
-	request := makeRequestBuilder(fields...)
+	line := &requestLineSniffer{}
+	request := line.wrap(makeRequestBuilder(fields...))
 	reader, err := p.send(request)
 
-	return &Response{err, reader}
+	return &Response{err, reader, context.Background(), line.verb, line.url()}
+}
+
+// RequestCtx makes an HTTP request using the given builders and context,
+// then returns the server's response.
+//
+// RequestCtx works just like Request but attaches the given context to
+// the request---see WithContext. If you cancel the context, the request
+// gets aborted. If the server's response is already in, cancelling the
+// context stops Response.Handle from running any more handlers and makes
+// any handler still reading the response body get an error.
+func (p *Client) RequestCtx(ctx context.Context, fields ...wire.RequestBuilder) *Response {
+	builders := append([]wire.RequestBuilder{WithContext(ctx)}, fields...)
+	response := p.Request(builders...)
+	if ctx != nil {
+		response.ctx = ctx
+	}
+	return response
+}
+
+// requestLineSniffer remembers the method and URL of the request it
+// writes so Response knows how to handle the reply, e.g. a HEAD response
+// has no body even if it comes with a "Content-Length" header. It also
+// remembers the "Content-Type" so Body can pick an encoder.
+type requestLineSniffer struct {
+	wire.RequestWriter
+	verb        wire.Method
+	resource    yoorel.HttpUrl
+	query       url.Values
+	contentType string
+}
+
+func (p *requestLineSniffer) Header(name string, content string) error {
+	if strings.EqualFold(name, "Content-Type") {
+		p.contentType = content
+	}
+	return p.RequestWriter.Header(name, content)
+}
+
+// PeekHeader reads back a header from the RequestWriter it wraps, if
+// that's a wire.HeaderPeeker, or otherwise from what it sniffed, which
+// is only "Content-Type".
+func (p *requestLineSniffer) PeekHeader(name string) string {
+	if peeker, ok := p.RequestWriter.(wire.HeaderPeeker); ok {
+		return peeker.PeekHeader(name)
+	}
+	if strings.EqualFold(name, "Content-Type") {
+		return p.contentType
+	}
+	return ""
+}
+
+func (p *requestLineSniffer) BodyTransfer(streaming bool) error {
+	if transfer, ok := p.RequestWriter.(wire.BodyTransferWriter); ok {
+		return transfer.BodyTransfer(streaming)
+	}
+	return nil
+}
+
+func (p *requestLineSniffer) AddHeader(name string, content string) error {
+	if strings.EqualFold(name, "Content-Type") && p.contentType == "" {
+		p.contentType = content
+	}
+	return p.RequestWriter.AddHeader(name, content)
+}
+
+func (p *requestLineSniffer) RequestLine(verb wire.Method, resource yoorel.HttpUrl) error {
+	p.verb = verb
+	p.resource = resource
+	return p.RequestWriter.RequestLine(verb, resource)
+}
+
+func (p *requestLineSniffer) QueryParam(key string, value string) error {
+	if p.query == nil {
+		p.query = make(url.Values)
+	}
+	p.query.Add(key, value)
+	return p.RequestWriter.QueryParam(key, value)
+}
+
+func (p *requestLineSniffer) url() string {
+	if p.resource == nil {
+		return ""
+	}
+	target := yoorel.ToURL(p.resource)
+	if len(p.query) > 0 {
+		query := target.Query()
+		for key, values := range p.query {
+			for _, v := range values {
+				query.Add(key, v)
+			}
+		}
+		target.RawQuery = query.Encode()
+	}
+	return target.String()
+}
+
+func (p *requestLineSniffer) wrap(build wire.RequestBuilder) wire.RequestBuilder {
+	return func(request wire.RequestWriter) error {
+		p.RequestWriter = request
+		return build(p)
+	}
+}
+
+// headResponse is the response to a HEAD request. The server isn't
+// supposed to send a body, but it may still send a "Content-Length"
+// header with the size of the body a GET would get. So headResponse
+// hides whatever body the wire.ResponseReader may have and gives back
+// an empty one instead.
+type headResponse struct {
+	wire.ResponseReader
+}
+
+func (p headResponse) Body() io.ReadCloser {
+	return bytez.NewBuffer()
+}
+
+func (p headResponse) Unwrap() wire.ResponseReader {
+	return p.ResponseReader
 }
 
 func makeRequestBuilder(builders ...wire.RequestBuilder) wire.RequestBuilder {
@@ -79,26 +209,83 @@ func makeRequestBuilder(builders ...wire.RequestBuilder) wire.RequestBuilder {
 // so the code stays modular---single responsibility principle, anyone?
 // Since the response processing chain stops at the first error, basically
 // we've got a poor man's monomorphic either+IO monad stack---ask Google.
+//
+// The response to a HEAD request has no body, so handlers always get
+// an empty one in that case, even if the server sent a "Content-Length"
+// header. Use ReadContentLength to get hold of the header value.
+//
+// If a handler returns a *hyper.ResponseError, Handle fills in the
+// method and URL of the request.
 func (p Response) Handle(handlers ...wire.ResponseHandler) error {
 	if p.requestError != nil {
 		return p.requestError
 	}
 
 	defer p.reader.Body().Close()
+	reader := p.reader
+	if p.method == wire.HEAD {
+		reader = headResponse{reader}
+	}
+	scope := &branchScope{ResponseReader: reader}
 	for _, handle := range handlers {
 		if handle == nil {
 			return hyper.NilResponseHandlerErr()
 		}
-		if err := handle(p.reader); err != nil {
+		if err := p.ctx.Err(); err != nil {
 			return err
 		}
+		if err := handle(scope); err != nil {
+			return p.addRequestLine(err)
+		}
 	}
 	return nil
 }
 
+func (p Response) addRequestLine(err error) error {
+	var resErr *hyper.ResponseError
+	if errors.As(err, &resErr) && resErr.Url == "" {
+		resErr.Method = p.method
+		resErr.Url = hyper.RedactUrl(p.url)
+	}
+	return err
+}
+
+// RedirectHistory returns the URL of each request sent to get the
+// response, starting with the original one and followed by the target
+// of each redirect, in order. You only get the URLs if the Sender knows
+// them---see wire.RedirectHistory. Otherwise RedirectHistory returns
+// the URL of the original request, if there was one.
+func (p Response) RedirectHistory() []string {
+	history := []string{}
+	for _, u := range wire.RedirectHistory(p.reader) {
+		history = append(history, u.String())
+	}
+	if len(history) == 0 && p.url != "" {
+		history = append(history, p.url)
+	}
+	return history
+}
+
+// FinalUrl returns the URL of the response, i.e. the URL of the last
+// request sent after following any redirects. See RedirectHistory.
+func (p Response) FinalUrl() string {
+	history := p.RedirectHistory()
+	if len(history) == 0 {
+		return ""
+	}
+	return history[len(history)-1]
+}
+
 // Request is a convenience function to send an HTTP request using
 // http.DefaultClient. The given builders write the request as explained
 // in Client.Request.
 func Request(fields ...wire.RequestBuilder) *Response {
 	return New().Request(fields...)
 }
+
+// RequestCtx is a convenience function to send an HTTP request using
+// http.DefaultClient and the given context. The given builders write
+// the request as explained in Client.Request.
+func RequestCtx(ctx context.Context, fields ...wire.RequestBuilder) *Response {
+	return New().RequestCtx(ctx, fields...)
+}
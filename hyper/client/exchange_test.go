@@ -3,10 +3,13 @@ package client
 import (
 	"bytes"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
+	"strings"
 	"testing"
 
 	"github.com/c0c0n3/resto/hyper"
+	"github.com/c0c0n3/resto/hyper/wire"
 	"github.com/c0c0n3/resto/mime"
 	"github.com/c0c0n3/resto/util/bytez"
 	e "github.com/c0c0n3/resto/util/err"
@@ -285,3 +288,179 @@ func TestDnsLookupError(t *testing.T) {
 		t.Errorf("want: url error; got: %v", err)
 	}
 }
+
+func TestHeadResponseHasNoBody(t *testing.T) {
+	responseBody := &EmptyBody{}
+	mock := &mockClient{
+		resToSend: &http.Response{
+			StatusCode: 200,
+			Status:     "OK",
+			Header:     http.Header{"Content-Length": []string{"42"}},
+			Body:       responseBody,
+		},
+	}
+	client := New(mock.Sender())
+
+	var size int64
+	output := &hyper.ByteBody{}
+	err := client.Request(
+		HEAD("https://my.api/data"),
+	).Handle(
+		ExpectSuccess,
+		ReadContentLength(&size),
+		ReadResponse(output),
+	)
+
+	if err != nil {
+		t.Errorf("want: server reply; got: %v", err)
+	}
+	if size != 42 {
+		t.Errorf("want: 42; got: %d", size)
+	}
+	if len(output.Data) > 0 {
+		t.Errorf("want: empty body; got: %v", output.Data)
+	}
+	if !responseBody.closed {
+		t.Errorf("didn't close body stream on exit")
+	}
+}
+
+func TestGetResponseKeepsBody(t *testing.T) {
+	mock := &mockClient{
+		resToSend: &http.Response{
+			StatusCode: 200,
+			Status:     "OK",
+			Body:       bytez.NewBufferFrom([]byte("*")),
+		},
+	}
+	client := New(mock.Sender())
+
+	output := &hyper.StringBody{}
+	err := client.Request(
+		Method("GET", "https://my.api/data"),
+	).Handle(
+		ReadResponse(output),
+	)
+
+	if err != nil {
+		t.Errorf("want: server reply; got: %v", err)
+	}
+	if output.Data != "*" {
+		t.Errorf("want: *; got: %s", output.Data)
+	}
+}
+
+func TestHeadRequestToServer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Length", "1234")
+			w.WriteHeader(200)
+		}))
+	defer server.Close()
+
+	var size int64
+	err := Request(
+		HEAD(server.URL),
+	).Handle(
+		ExpectSuccess,
+		ReadContentLength(&size),
+	)
+
+	if err != nil {
+		t.Errorf("want: server reply; got: %v", err)
+	}
+	if size != 1234 {
+		t.Errorf("want: 1234; got: %d", size)
+	}
+}
+
+func TestReadContentLengthMissing(t *testing.T) {
+	mock := &mockClient{
+		resToSend: &http.Response{StatusCode: 200},
+	}
+	client := New(mock.Sender())
+
+	var size int64
+	err := client.Request(
+		HEAD("https://my.api/data"),
+	).Handle(
+		ReadContentLength(&size),
+	)
+
+	if _, ok := err.(e.Err[hyper.UnexpectedResponse]); !ok {
+		t.Errorf("want: unexpected response error; got: %v", err)
+	}
+}
+
+func TestRedirectHistory(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/old" {
+				http.Redirect(w, r, "/new", http.StatusMovedPermanently)
+			}
+		}))
+	defer server.Close()
+
+	response := Request(GET(server.URL + "/old"))
+	seen := []*url.URL{}
+	err := response.Handle(
+		ExpectSuccess,
+		OnStatus(200, func(r wire.ResponseReader) error {
+			seen = wire.RedirectHistory(r)
+			return nil
+		}),
+	)
+
+	if err != nil {
+		t.Fatalf("want: success; got: %v", err)
+	}
+	history := response.RedirectHistory()
+	if len(history) != 2 || !strings.HasSuffix(history[0], "/old") {
+		t.Errorf("want: old and new URLs; got: %v", history)
+	}
+	if final := response.FinalUrl(); final != server.URL+"/new" {
+		t.Errorf("want: %s/new; got: %s", server.URL, final)
+	}
+	if len(seen) != 2 || seen[1].Path != "/new" {
+		t.Errorf("want: handler to see history; got: %v", seen)
+	}
+}
+
+func TestFinalUrlWithoutHistory(t *testing.T) {
+	mock := &mockClient{resToSend: &http.Response{StatusCode: 200}}
+	response := New(mock.Sender()).Request(GET("http://my.api/data"))
+	if got := response.FinalUrl(); got != "http://my.api:80/data" {
+		t.Errorf("want: request url; got: %s", got)
+	}
+
+	response = Request(GET("http://my.api/data"), nil)
+	if got := response.FinalUrl(); got != "" {
+		t.Errorf("want: no url; got: %s", got)
+	}
+}
+
+type Login struct {
+	User     string   `form:"user"`
+	Password string   `form:"password"`
+	Scopes   []string `form:"scope"`
+}
+
+func TestPostFormBody(t *testing.T) {
+	mock := &mockClient{resToSend: &http.Response{StatusCode: 200}}
+	err := New(mock.Sender()).Request(
+		POST("https://legacy.api/login"),
+		Body(Form(&Login{"admin", "s3cr3t", []string{"r", "w"}})),
+	).Handle(ExpectSuccess)
+
+	if err != nil {
+		t.Fatalf("want: server reply; got: %v", err)
+	}
+	req := mock.capturedReq
+	if got := req.Header.Get("Content-Type"); got != "application/x-www-form-urlencoded" {
+		t.Errorf("want: url-encoded content type; got: %s", got)
+	}
+	req.ParseForm()
+	if req.PostForm.Get("user") != "admin" || len(req.PostForm["scope"]) != 2 {
+		t.Errorf("want: login form; got: %v", req.PostForm)
+	}
+}
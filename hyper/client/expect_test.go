@@ -1,10 +1,14 @@
 package client
 
 import (
+	"errors"
 	"net/http"
 	"testing"
 
 	"github.com/c0c0n3/resto/hyper"
+	"github.com/c0c0n3/resto/hyper/wire"
+	"github.com/c0c0n3/resto/mime"
+	"github.com/c0c0n3/resto/util/bytez"
 	e "github.com/c0c0n3/resto/util/err"
 )
 
@@ -36,7 +40,12 @@ func TestExpectSuccess(t *testing.T) {
 			ExpectSuccess,
 		)
 
-		if _, ok := err.(e.Err[hyper.UnexpectedResponse]); !ok {
+		var resErr *hyper.ResponseError
+		if !errors.As(err, &resErr) || resErr.StatusCode.Value() != code {
+			t.Errorf("want: response err (%d); got: %v", code, err)
+		}
+		var unexpected e.Err[hyper.UnexpectedResponse]
+		if !errors.As(err, &unexpected) {
 			t.Errorf("want: unexpected response err (%d); got: %v", code, err)
 		}
 	}
@@ -71,7 +80,12 @@ func TestExpectStatusCodeOneOf(t *testing.T) {
 			ExpectStatusCodeOneOf(want...),
 		)
 
-		if _, ok := err.(e.Err[hyper.UnexpectedResponse]); !ok {
+		var resErr *hyper.ResponseError
+		if !errors.As(err, &resErr) || resErr.StatusCode.Value() != code {
+			t.Errorf("want: response err (%d); got: %v", code, err)
+		}
+		var unexpected e.Err[hyper.UnexpectedResponse]
+		if !errors.As(err, &unexpected) {
 			t.Errorf("want: unexpected response err (%d); got: %v", code, err)
 		}
 	}
@@ -89,7 +103,180 @@ func TestExpectStatusCodeNone(t *testing.T) {
 		ExpectStatusCodeOneOf(),
 	)
 
-	if _, ok := err.(e.Err[hyper.UnexpectedResponse]); !ok {
+	var unexpected e.Err[hyper.UnexpectedResponse]
+	if !errors.As(err, &unexpected) {
 		t.Errorf("want: unexpected response err; got: %v", err)
 	}
 }
+
+var expectContentTypeFixtures = []struct {
+	header string
+	match  bool
+}{
+	{"application/json", true},
+	{"application/json; charset=utf-8", true},
+	{"application/problem+json", true},
+	{"application/yaml", false},
+	{"bogus", false},
+	{"", false},
+}
+
+func TestExpectContentType(t *testing.T) {
+	for k, f := range expectContentTypeFixtures {
+		mock := &mockClient{
+			resToSend: &http.Response{
+				StatusCode: 200,
+				Header:     http.Header{"Content-Type": []string{f.header}},
+			},
+		}
+		err := New(mock.Sender()).Request(
+			GET("https://my.api/data"),
+		).Handle(
+			ExpectContentType(mime.JSON, "application/*+json"),
+		)
+
+		if f.match && err != nil {
+			t.Errorf("[%d] want: match; got: %v", k, err)
+		}
+		if _, ok := err.(e.Err[hyper.UnexpectedResponse]); !f.match && !ok {
+			t.Errorf("[%d] want: unexpected response; got: %v", k, err)
+		}
+	}
+}
+
+func TestExpectSuccessErrorDetails(t *testing.T) {
+	mock := &mockClient{
+		resToSend: &http.Response{
+			StatusCode: 503,
+			Status:     "503 Service Unavailable",
+			Header: http.Header{
+				"Retry-After": []string{"120"},
+				"Set-Cookie":  []string{"secret=1"},
+			},
+			Body: bytez.NewBufferFrom([]byte(`{"error": "down"}`)),
+		},
+	}
+	client := New(mock.Sender())
+
+	err := client.Request(
+		GET("https://my.api/data"),
+		QueryParam("page", "2"),
+	).Handle(
+		ExpectSuccess,
+	)
+
+	var resErr *hyper.ResponseError
+	if !errors.As(err, &resErr) {
+		t.Fatalf("want: response err; got: %v", err)
+	}
+	if resErr.Method != wire.GET {
+		t.Errorf("want: GET; got: %s", resErr.Method)
+	}
+	if resErr.Url != "https://my.api:443/data" {
+		t.Errorf("want: request url; got: %s", resErr.Url)
+	}
+	if resErr.Reason != "Service Unavailable" {
+		t.Errorf("want: Service Unavailable; got: %s", resErr.Reason)
+	}
+	if got := resErr.Headers.Get("Retry-After"); got != "120" {
+		t.Errorf("want: 120; got: %s", got)
+	}
+	if got := resErr.Headers.Get("Set-Cookie"); got != "" {
+		t.Errorf("want: no cookie; got: %s", got)
+	}
+	if string(resErr.Body) != `{"error": "down"}` {
+		t.Errorf("want: body excerpt; got: %s", resErr.Body)
+	}
+	want := `hyper.UnexpectedResponse: expected successful response, got: 503; ` +
+		`request: GET https://my.api:443/data; ` +
+		`response: 503 Service Unavailable; body: {"error": "down"}`
+	if err.Error() != want {
+		t.Errorf("want: %s; got: %s", want, err)
+	}
+}
+
+func TestExpectSuccessOrProblem(t *testing.T) {
+	mock := &mockClient{
+		resToSend: &http.Response{
+			StatusCode: 403,
+			Header: http.Header{
+				"Content-Type": []string{"application/problem+json; charset=utf-8"},
+			},
+			Body: bytez.NewBufferFrom([]byte(
+				`{"type": "/probs/out-of-credit", "title": "No credit", "balance": 30}`)),
+		},
+	}
+	client := New(mock.Sender())
+
+	err := client.Request(
+		POST("https://my.api/buy"),
+	).Handle(
+		ExpectSuccessOrProblem,
+	)
+
+	var problem *hyper.ProblemError
+	if !errors.As(err, &problem) {
+		t.Fatalf("want: problem err; got: %v", err)
+	}
+	if problem.Problem.Type != "/probs/out-of-credit" {
+		t.Errorf("want: problem type; got: %v", problem.Problem)
+	}
+	if problem.Problem.Extensions["balance"] != float64(30) {
+		t.Errorf("want: balance; got: %v", problem.Problem.Extensions)
+	}
+	var resErr *hyper.ResponseError
+	if !errors.As(err, &resErr) || resErr.StatusCode != 403 {
+		t.Errorf("want: response err; got: %v", err)
+	}
+	if resErr.Method != wire.POST {
+		t.Errorf("want: POST; got: %s", resErr.Method)
+	}
+}
+
+func TestExpectSuccessOrProblemWithNoProblem(t *testing.T) {
+	mock := &mockClient{
+		resToSend: &http.Response{
+			StatusCode: 500,
+			Header:     http.Header{"Content-Type": []string{"text/plain"}},
+			Body:       bytez.NewBufferFrom([]byte("boom")),
+		},
+	}
+	client := New(mock.Sender())
+
+	err := client.Request(
+		GET("https://my.api/data"),
+	).Handle(
+		ExpectSuccessOrProblem,
+	)
+
+	var problem *hyper.ProblemError
+	if errors.As(err, &problem) {
+		t.Errorf("want: no problem err; got: %v", err)
+	}
+	var resErr *hyper.ResponseError
+	if !errors.As(err, &resErr) || string(resErr.Body) != "boom" {
+		t.Errorf("want: response err; got: %v", err)
+	}
+}
+
+func TestExpectSuccessOrProblemWithSuccess(t *testing.T) {
+	mock := &mockClient{
+		resToSend: &http.Response{
+			StatusCode: 200,
+			Body:       bytez.NewBufferFrom([]byte("*")),
+		},
+	}
+	client := New(mock.Sender())
+
+	output := &hyper.StringBody{}
+	err := client.Request(
+		GET("https://my.api/data"),
+	).Handle(
+		ExpectSuccessOrProblem,
+		ReadResponse(output),
+	)
+
+	if err != nil || output.Data != "*" {
+		t.Errorf("want: *; got: %s, %v", output.Data, err)
+	}
+}
@@ -0,0 +1,67 @@
+package client
+
+import (
+	"github.com/c0c0n3/resto/hyper"
+	"github.com/c0c0n3/resto/hyper/wire"
+	"github.com/c0c0n3/resto/mime"
+	e "github.com/c0c0n3/resto/util/err"
+)
+
+func deserializerFor[T any](response wire.ResponseReader, output *T) (hyper.BodyDeserializer, error) {
+	var contentType mime.MediaType
+	switch any(output).(type) {
+	case *string, *[]byte:
+		// raw body, content type doesn't matter
+	default:
+		parsed, err := hyper.ReadContentType(response)
+		if err != nil {
+			return nil, hyper.UnexpectedResponseErr("%v", err)
+		}
+		contentType = parsed.Essence()
+	}
+	deserializer, err := hyper.Codecs.Decoder(contentType, output)
+	if err != nil {
+		return nil, hyper.UnexpectedResponseErr("%v", err)
+	}
+	return deserializer, nil
+}
+
+// Fetch sends the request the given builders write, checks the response
+// is successful and reads the response body into a value of type T.
+//
+// Fetch picks a decoder going by the type of T and the response
+// "Content-Type" header. If T is a string or a byte slice, Fetch reads
+// in the raw body whatever the content type. Otherwise Fetch decodes
+// the body into T with the hyper.Codecs decoder for the content type,
+// e.g. JSON for "application/json" or any "application/*+json" type.
+// A content type with no decoder is an error. If the response isn't
+// successful, Fetch returns the same error ExpectSuccess does.
+//
+// You get back the decoded value or the error wrapped in an ErrOr, so
+// you can chain calls with err.Bind. Example.
+//
+//     fetchOrders := func(u User) ([]Order, error) {
+//         orders := Fetch[[]Order](client, GET(u.OrdersUrl))
+//         return orders.Right(), orders.Left()
+//     }
+//     user := Fetch[User](client, GET("https://my.api/users/1"))
+//     orders := err.Bind(fetchOrders, user)
+//
+// If client is nil, Fetch uses a Client backed by http.DefaultClient.
+func Fetch[T any](client *Client, builders ...wire.RequestBuilder) e.ErrOr[T] {
+	if client == nil {
+		client = New()
+	}
+	var output T
+	err := client.Request(builders...).Handle(
+		ExpectSuccess,
+		func(response wire.ResponseReader) error {
+			deserializer, err := deserializerFor(response, &output)
+			if err != nil {
+				return err
+			}
+			return hyper.ReadBody(response, deserializer)
+		},
+	)
+	return e.FromResult(output, err)
+}
@@ -0,0 +1,130 @@
+package client
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/c0c0n3/resto/hyper"
+	"github.com/c0c0n3/resto/util/bytez"
+	e "github.com/c0c0n3/resto/util/err"
+)
+
+func fetchMock(code int, contentType string, body string) *Client {
+	mock := &mockClient{
+		resToSend: &http.Response{
+			StatusCode: code,
+			Header:     http.Header{"Content-Type": []string{contentType}},
+			Body:       bytez.NewBufferFrom([]byte(body)),
+		},
+	}
+	return New(mock.Sender())
+}
+
+func TestFetchJson(t *testing.T) {
+	for _, ct := range []string{"application/json", "application/vnd.api+json; charset=utf-8"} {
+		client := fetchMock(200, ct, `{"Greeting": "howzit!"}`)
+		got := Fetch[MyData](client, GET("https://my.api/data"))
+
+		if !got.IsRight() {
+			t.Fatalf("[%s] want: data; got: %v", ct, got.Left())
+		}
+		if got.Right().Greeting != "howzit!" {
+			t.Errorf("[%s] want: howzit!; got: %v", ct, got.Right())
+		}
+	}
+}
+
+func TestFetchRawBody(t *testing.T) {
+	client := fetchMock(200, "text/plain", "howzit!")
+	if got := Fetch[string](client, GET("https://my.api/data")); got.Right() != "howzit!" {
+		t.Errorf("want: howzit!; got: %v", got)
+	}
+	client = fetchMock(200, "application/octet-stream", "*")
+	if got := Fetch[[]byte](client, GET("https://my.api/data")); string(got.Right()) != "*" {
+		t.Errorf("want: *; got: %v", got)
+	}
+}
+
+func TestFetchXml(t *testing.T) {
+	client := fetchMock(200, "text/xml", "<d><Greeting>howzit!</Greeting></d>")
+	got := Fetch[MyData](client, GET("https://my.api/data"))
+
+	if got.Right().Greeting != "howzit!" {
+		t.Errorf("want: howzit!; got: %v", got)
+	}
+}
+
+func TestReadAny(t *testing.T) {
+	cases := []struct {
+		contentType string
+		body        string
+	}{
+		{"application/json", `{"Greeting": "howzit!"}`},
+		{"application/xml", "<d><Greeting>howzit!</Greeting></d>"},
+	}
+	for _, k := range cases {
+		data := &MyData{}
+		err := fetchMock(200, k.contentType, k.body).Request(
+			GET("https://my.api/data"),
+		).Handle(ReadAny(data))
+
+		if err != nil || data.Greeting != "howzit!" {
+			t.Errorf("[%s] want: howzit!; got: %v, %v", k.contentType, data, err)
+		}
+	}
+}
+
+func TestReadAnyRawBody(t *testing.T) {
+	var text string
+	err := fetchMock(200, "image/png", "howzit!").Request(
+		GET("https://my.api/data"),
+	).Handle(ReadAny(&text))
+
+	if err != nil || text != "howzit!" {
+		t.Errorf("want: howzit!; got: %s, %v", text, err)
+	}
+}
+
+func TestReadAnyUnsupportedContentType(t *testing.T) {
+	err := fetchMock(200, "image/png", "howzit!").Request(
+		GET("https://my.api/data"),
+	).Handle(ReadAny(&MyData{}))
+
+	if _, ok := err.(e.Err[hyper.UnexpectedResponse]); !ok {
+		t.Errorf("want: unexpected response err; got: %v", err)
+	}
+}
+
+func TestFetchUnsupportedContentType(t *testing.T) {
+	for _, ct := range []string{"text/plain", ""} {
+		client := fetchMock(200, ct, "howzit!")
+		got := Fetch[MyData](client, GET("https://my.api/data"))
+
+		if _, ok := got.Left().(e.Err[hyper.UnexpectedResponse]); !ok {
+			t.Errorf("[%s] want: unexpected response err; got: %v", ct, got.Left())
+		}
+	}
+}
+
+func TestFetchUnsuccessfulResponse(t *testing.T) {
+	client := fetchMock(404, "application/json", `{"Greeting": "nope"}`)
+	got := Fetch[MyData](client, GET("https://my.api/data"))
+
+	var resErr *hyper.ResponseError
+	if !errors.As(got.Left(), &resErr) || resErr.StatusCode != 404 {
+		t.Errorf("want: response err; got: %v", got.Left())
+	}
+}
+
+func TestFetchComposesWithBind(t *testing.T) {
+	client := fetchMock(200, "application/json", `{"Greeting": "howzit!"}`)
+	greet := func(d MyData) (string, error) {
+		return d.Greeting + " stranger", nil
+	}
+	got := e.Bind(greet, Fetch[MyData](client, GET("https://my.api/data")))
+
+	if got.Right() != "howzit! stranger" {
+		t.Errorf("want: howzit! stranger; got: %v", got)
+	}
+}
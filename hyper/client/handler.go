@@ -1,15 +1,23 @@
 package client
 
 import (
+	"io"
+	"mime/multipart"
+	"strconv"
+
 	"github.com/c0c0n3/resto/hyper"
 	"github.com/c0c0n3/resto/hyper/wire"
+	"github.com/c0c0n3/resto/mime"
+	"github.com/c0c0n3/resto/util/bytez"
 	"github.com/c0c0n3/resto/util/set"
 )
 
 // ExpectSuccess is a wire.ResponseHandler that checks for successful
 // responses. If the response code is in the range 200-299 (both inclusive),
-// ExpectSuccess does nothing, otherwise it returns an error---that
-// stops any following wire.ResponseHandler from running.
+// ExpectSuccess does nothing, otherwise it returns a *hyper.ResponseError
+// ---that stops any following wire.ResponseHandler from running. The
+// error holds the status code, a few headers and the first bytes of the
+// response body so you can see what the server said.
 //
 // Example.
 //
@@ -22,13 +30,80 @@ import (
 //
 func ExpectSuccess(response wire.ResponseReader) error {
 	code, _ := response.StatusLine()
-	if code < 200 || code > 299 {
-		return hyper.UnexpectedResponseErr(
+	if !isSuccess(code) {
+		return hyper.UnexpectedResponseError(response,
 			"expected successful response, got: %v", code)
 	}
 	return nil
 }
 
+func isSuccess(code wire.StatusCode) bool {
+	return 200 <= code && code <= 299
+}
+
+// The maximum number of bytes ExpectSuccessOrProblem reads from the body
+// of a problem details response.
+const maxProblemSize = 64 * 1024
+
+// bufferedResponse replays a response body that was read into memory.
+type bufferedResponse struct {
+	wire.ResponseReader
+	body []byte
+}
+
+func (p bufferedResponse) Body() io.ReadCloser {
+	return bytez.Reader(p.body)
+}
+
+func (p bufferedResponse) Unwrap() wire.ResponseReader {
+	return p.ResponseReader
+}
+
+// ExpectSuccessOrProblem is a wire.ResponseHandler that works like
+// ExpectSuccess, except it also reads RFC 9457 problem details. If the
+// response code isn't in the range 200-299 and the response body is a
+// problem details document, i.e. "application/problem+json" content,
+// ExpectSuccessOrProblem returns a *hyper.ProblemError with the problem
+// the server sent. Otherwise it returns a *hyper.ResponseError just like
+// ExpectSuccess does.
+//
+// Example.
+//
+//     err := Request(
+//         DELETE("https://my.api/data/1"),
+//     ).Handle(
+//         ExpectSuccessOrProblem,
+//     )
+//     var problem *hyper.ProblemError
+//     if errors.As(err, &problem) {
+//         fmt.Printf("problem type: %s\n", problem.Problem.Type)
+//     }
+//
+func ExpectSuccessOrProblem(response wire.ResponseReader) error {
+	code, _ := response.StatusLine()
+	if isSuccess(code) {
+		return nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(response.Body(), maxProblemSize))
+	if err != nil {
+		return err
+	}
+	buffered := bufferedResponse{response, body}
+	resErr := hyper.UnexpectedResponseError(buffered,
+		"expected successful response, got: %v", code)
+
+	contentType, err := hyper.ReadContentType(response)
+	if err != nil || !mime.PROBLEM_JSON.Matches(contentType.Essence()) {
+		return resErr
+	}
+	problem := &hyper.ProblemBody{}
+	if err := hyper.ReadBody(buffered, problem); err != nil {
+		return resErr
+	}
+	return &hyper.ProblemError{Problem: problem.Data, Response: resErr}
+}
+
 // TODO. Implement expect for other status code ranges too?
 // Informational responses (100–199)
 // Successful responses (200–299)     --> DONE
@@ -39,8 +114,8 @@ func ExpectSuccess(response wire.ResponseReader) error {
 // ExpectStatusCodeOneOf builds a wire.ResponseHandler to check the
 // response status code is among the given ones.
 // If the response code is in the given list, the returned handler
-// does nothing. Otherwise it returns an error---that stops any following
-// wire.ResponseHandler from running.
+// does nothing. Otherwise it returns a *hyper.ResponseError---that stops
+// any following wire.ResponseHandler from running.
 //
 // Example.
 //
@@ -60,12 +135,45 @@ func ExpectStatusCodeOneOf(expectedStatusCode ...int) wire.ResponseHandler {
 		code, _ := response.StatusLine()
 
 		if !allowed.Member(code.Value()) {
-			return hyper.UnexpectedResponseErr("%v", code)
+			return hyper.UnexpectedResponseError(response, "%v", code)
 		}
 		return nil
 	}
 }
 
+// ExpectContentType builds a wire.ResponseHandler to check the response
+// "Content-Type" header matches one of the given MIME types, which can
+// have wildcards---see mime.ParsedType.Matches. Any parameters in the
+// header, e.g. a charset, are ignored unless the given MIME type has
+// them too. If there's no match, the handler returns an error.
+//
+// Example.
+//
+//     err := Request(
+//         GET("https://my.api/data"),
+//         Accept(mime.JSON),
+//     ).Handle(
+//         ExpectSuccess,
+//         ExpectContentType(mime.JSON, "application/*+json"),
+//         ReadJsonResponse(data),
+//     )
+//
+func ExpectContentType(mediaType ...mime.MediaType) wire.ResponseHandler {
+	return func(response wire.ResponseReader) error {
+		got, err := hyper.ReadContentType(response)
+		if err != nil {
+			return hyper.UnexpectedResponseErr("%v", err)
+		}
+		for _, mt := range mediaType {
+			if mt.Matches(got.MediaType()) {
+				return nil
+			}
+		}
+		return hyper.UnexpectedResponseErr(
+			"unexpected content type: %s", got)
+	}
+}
+
 // ReadJsonResponse builds a wire.ResponseHandler to deserialise a
 // JSON response body, returning any error that stopped it from
 // deserializing the body.
@@ -89,6 +197,34 @@ func ReadJsonResponse[T any](output *T) wire.ResponseHandler {
 	}
 }
 
+// ReadAny builds a wire.ResponseHandler to read the response body into
+// the given output with the hyper.Codecs decoder for the response
+// "Content-Type". If the output is a string or byte slice though,
+// ReadAny reads in the raw body whatever the content type. A content
+// type with no decoder is an error.
+//
+// Example.
+//
+//     data := &MyData{}
+//     err := Request(
+//         GET("https://my.api/data"),
+//         Accept(mime.JSON, mime.XML),
+//     ).Handle(
+//         ExpectSuccess,
+//         ReadAny(data),
+//     )
+//     fmt.Printf("data: %v\nerror: %v\n", data, err)
+//
+func ReadAny[T any](output *T) wire.ResponseHandler {
+	return func(response wire.ResponseReader) error {
+		deserializer, err := deserializerFor(response, output)
+		if err != nil {
+			return err
+		}
+		return hyper.ReadBody(response, deserializer)
+	}
+}
+
 // ReadResponse builds a wire.ResponseHandler to read in a response body,
 // returning any error that stopped it from reading the body. You use an
 // hyper.BodyDeserializer to have ReadResponse convert the HTTP body octets
@@ -112,3 +248,78 @@ func ReadResponse(output hyper.BodyDeserializer) wire.ResponseHandler {
 		return hyper.ReadBody(response, output)
 	}
 }
+
+// ReadMultipart builds a wire.ResponseHandler to read a multipart
+// response body, calling the given function with each part in turn.
+// The handler stops at the first error the function returns. Example.
+//
+//     err := Request(
+//         GET("https://my.api/reports/q3"),
+//     ).Handle(
+//         ExpectSuccess,
+//         ReadMultipart(func(part *multipart.Part) error {
+//             fmt.Printf("got part: %s\n", part.FileName())
+//             return nil
+//         }),
+//     )
+//
+func ReadMultipart(each func(part *multipart.Part) error) wire.ResponseHandler {
+	return func(response wire.ResponseReader) error {
+		return hyper.ReadParts(response, each)
+	}
+}
+
+// ReadNdjson builds a wire.ResponseHandler to read a newline-delimited
+// JSON response body one value at a time, in constant space, calling the
+// given function with each value in turn. The handler stops at the first
+// error the function returns. Example.
+//
+//     count := 0
+//     err := Request(
+//         GET("https://my.api/export"),
+//         Accept(mime.NDJSON),
+//     ).Handle(
+//         ExpectSuccess,
+//         ReadNdjson(func(r Record) error {
+//             count++
+//             return store(r)
+//         }),
+//     )
+//
+// Use hyper.NdjsonToChan with ReadResponse to get the values through a
+// channel instead.
+func ReadNdjson[T any](each func(item T) error) wire.ResponseHandler {
+	return func(response wire.ResponseReader) error {
+		return hyper.ReadNdjson(response, each)
+	}
+}
+
+// ReadContentLength builds a wire.ResponseHandler to read the value of
+// the response's "Content-Length" header into the given output. The
+// handler returns an error if the header is missing or isn't a valid
+// length. This comes in handy with HEAD requests to find out the size
+// of a resource without downloading it.
+//
+// Example.
+//
+//     var size int64
+//     err := Request(
+//         HEAD("https://my.api/big/file"),
+//     ).Handle(
+//         ExpectSuccess,
+//         ReadContentLength(&size),
+//     )
+//     fmt.Printf("size: %d\nerror: %v\n", size, err)
+//
+func ReadContentLength(output *int64) wire.ResponseHandler {
+	return func(response wire.ResponseReader) error {
+		value := response.Header("Content-Length")
+		size, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || size < 0 {
+			return hyper.UnexpectedResponseErr(
+				"invalid content length: '%s'", value)
+		}
+		*output = size
+		return nil
+	}
+}
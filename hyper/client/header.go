@@ -1,11 +1,105 @@
 package client
 
 import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
 	"github.com/c0c0n3/resto/hyper"
 	"github.com/c0c0n3/resto/hyper/wire"
 	"github.com/c0c0n3/resto/mime"
 )
 
+// Header writes a header with the specified values, replacing any values
+// written earlier for the same header. Each value becomes a separate
+// header line. Use it to write any header there's no built-in builder
+// for, including repeated headers like "Via" or "Link".
+//
+// Example.
+//
+//     err := Request(
+//         GET("https://my.api/data"),
+//         Header("Via", "1.1 alpha", "1.1 beta"),
+//     ).Handle(
+//         ExpectSuccess,
+//     )
+//
+func Header(name string, values ...string) wire.RequestBuilder {
+	return func(msg wire.RequestWriter) error {
+		return hyper.WriteHeader(msg, name, values...)
+	}
+}
+
+// AddHeader adds the specified values to a header, keeping any values
+// written earlier for the same header. Each value becomes a separate
+// header line.
+func AddHeader(name string, values ...string) wire.RequestBuilder {
+	return func(msg wire.RequestWriter) error {
+		return hyper.AddHeader(msg, name, values...)
+	}
+}
+
+// Headers writes each header in the given map, replacing any value
+// written earlier for the same header.
+func Headers(headers map[string]string) wire.RequestBuilder {
+	return func(msg wire.RequestWriter) error {
+		for name, value := range headers {
+			if err := hyper.WriteHeader(msg, name, value); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// UserAgent writes a "User-Agent" header with the specified value.
+func UserAgent(value string) wire.RequestBuilder {
+	return Header("User-Agent", value)
+}
+
+// IfMatch writes an "If-Match" header with the specified entity tags.
+// Each tag must be a quoted string, optionally prefixed by "W/" for weak
+// tags, e.g. `"xyzzy"` or `W/"xyzzy"`. Use "*" to match any tag.
+func IfMatch(etag ...string) wire.RequestBuilder {
+	return Header("If-Match", strings.Join(etag, ", "))
+}
+
+// IfNoneMatch writes an "If-None-Match" header with the specified entity
+// tags. See IfMatch about the tag format.
+func IfNoneMatch(etag ...string) wire.RequestBuilder {
+	return Header("If-None-Match", strings.Join(etag, ", "))
+}
+
+// IfModifiedSince writes an "If-Modified-Since" header with the specified
+// time, converted to an HTTP date.
+func IfModifiedSince(t time.Time) wire.RequestBuilder {
+	return Header("If-Modified-Since", t.UTC().Format(http.TimeFormat))
+}
+
+// Range writes a "Range" header to request the bytes from first to
+// last, both inclusive. If last is negative, the range extends to the
+// end of the content.
+//
+// Examples.
+//
+//     Range(0, 499)   ==> Range: bytes=0-499
+//     Range(500, -1)  ==> Range: bytes=500-
+//
+func Range(first int64, last int64) wire.RequestBuilder {
+	spec := fmt.Sprintf("bytes=%d-", first)
+	if last >= 0 {
+		spec = fmt.Sprintf("%s%d", spec, last)
+	}
+	return Header("Range", spec)
+}
+
+// CacheControl writes a "Cache-Control" header with the specified
+// directives, e.g. CacheControl("no-cache", "max-age=0").
+func CacheControl(directive ...string) wire.RequestBuilder {
+	return Header("Cache-Control", strings.Join(directive, ", "))
+}
+
 // ContentType writes a "Content-Type" header with the specified MIME
 // type.
 func ContentType(mediaType mime.MediaType) wire.RequestBuilder {
@@ -29,6 +123,21 @@ func Accept(mediaType ...mime.MediaType) wire.RequestBuilder {
 	}
 }
 
+// AcceptWeighted writes an "Accept" header with the specified media
+// ranges and their weights, e.g.
+//
+//     AcceptWeighted(
+//         hyper.Weighted(mime.JSON, 1),
+//         hyper.Weighted(mime.YAML, 0.5),
+//     )
+//     // Accept: application/json, application/yaml;q=0.5
+//
+func AcceptWeighted(ranges ...hyper.MediaRange) wire.RequestBuilder {
+	return func(msg wire.RequestWriter) error {
+		return hyper.WriteWeightedAccept(msg, ranges...)
+	}
+}
+
 // Authorization writes an "Authorization" header with the specified
 // value.
 func Authorization(value string) wire.RequestBuilder {
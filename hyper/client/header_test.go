@@ -0,0 +1,116 @@
+package client
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/c0c0n3/resto/hyper"
+	"github.com/c0c0n3/resto/mime"
+)
+
+func TestRepeatedHeaders(t *testing.T) {
+	mock := &mockClient{
+		resToSend: &http.Response{StatusCode: 200},
+	}
+	client := New(mock.Sender())
+
+	err := client.Request(
+		GET("https://my.api/data"),
+		Header("Via", "old"),
+		Header("Via", "1.1 alpha", "1.1 beta"),
+		AddHeader("Cookie", "a=1"),
+		AddHeader("Cookie", "b=2", "c=3"),
+	).Handle(
+		ExpectSuccess,
+	)
+
+	if err != nil {
+		t.Errorf("want: server reply; got: %v", err)
+	}
+	wantVia := []string{"1.1 alpha", "1.1 beta"}
+	if got := mock.capturedReq.Header["Via"]; !reflect.DeepEqual(wantVia, got) {
+		t.Errorf("want: %v; got: %v", wantVia, got)
+	}
+	wantCookie := []string{"a=1", "b=2", "c=3"}
+	if got := mock.capturedReq.Header["Cookie"]; !reflect.DeepEqual(wantCookie, got) {
+		t.Errorf("want: %v; got: %v", wantCookie, got)
+	}
+}
+
+func TestConditionalAndCacheHeaders(t *testing.T) {
+	mock := &mockClient{
+		resToSend: &http.Response{StatusCode: 200},
+	}
+	client := New(mock.Sender())
+	since := time.Date(2015, 10, 21, 9, 28, 0, 0, time.FixedZone("X", 3600))
+
+	err := client.Request(
+		GET("https://my.api/data"),
+		Headers(map[string]string{"X-A": "a", "X-B": "b"}),
+		UserAgent("resto/1.0"),
+		IfMatch(`"x"`, `W/"y"`),
+		IfNoneMatch("*"),
+		IfModifiedSince(since),
+		Range(500, -1),
+		CacheControl("no-cache", "max-age=0"),
+	).Handle(
+		ExpectSuccess,
+	)
+
+	if err != nil {
+		t.Errorf("want: server reply; got: %v", err)
+	}
+	want := map[string]string{
+		"X-A":               "a",
+		"X-B":               "b",
+		"User-Agent":        "resto/1.0",
+		"If-Match":          `"x", W/"y"`,
+		"If-None-Match":     "*",
+		"If-Modified-Since": "Wed, 21 Oct 2015 08:28:00 GMT",
+		"Range":             "bytes=500-",
+		"Cache-Control":     "no-cache, max-age=0",
+	}
+	for name, value := range want {
+		if got := mock.capturedReq.Header.Get(name); got != value {
+			t.Errorf("[%s] want: %s; got: %s", name, value, got)
+		}
+	}
+}
+
+func TestClosedRange(t *testing.T) {
+	mock := &mockClient{
+		resToSend: &http.Response{StatusCode: 206},
+	}
+	client := New(mock.Sender())
+
+	client.Request(GET("https://my.api/data"), Range(0, 499)).
+		Handle(ExpectSuccess)
+
+	if got := mock.capturedReq.Header.Get("Range"); got != "bytes=0-499" {
+		t.Errorf("want: bytes=0-499; got: %s", got)
+	}
+}
+
+func TestAcceptWeighted(t *testing.T) {
+	mock := &mockClient{
+		resToSend: &http.Response{StatusCode: 200},
+	}
+	client := New(mock.Sender())
+
+	client.Request(
+		GET("https://my.api/data"),
+		AcceptWeighted(
+			hyper.Weighted(mime.JSON, 1),
+			hyper.Weighted(mime.YAML, 0.5),
+		),
+	).Handle(
+		ExpectSuccess,
+	)
+
+	want := "application/json, application/yaml;q=0.5"
+	if got := mock.capturedReq.Header.Get("Accept"); got != want {
+		t.Errorf("want: %s; got: %s", want, got)
+	}
+}
@@ -85,3 +85,60 @@ func PATCH[U TargetUrl](resource U) wire.RequestBuilder {
 func DELETE[U TargetUrl](resource U) wire.RequestBuilder {
 	return makeRequestLineBuilder(wire.DELETE, resource)
 }
+
+// HEAD writes the request line of a HEAD HTTP request to the specified
+// resource.
+func HEAD[U TargetUrl](resource U) wire.RequestBuilder {
+	return makeRequestLineBuilder(wire.HEAD, resource)
+}
+
+// OPTIONS writes the request line of an OPTIONS HTTP request to the
+// specified resource.
+func OPTIONS[U TargetUrl](resource U) wire.RequestBuilder {
+	return makeRequestLineBuilder(wire.OPTIONS, resource)
+}
+
+// TRACE writes the request line of a TRACE HTTP request to the specified
+// resource.
+func TRACE[U TargetUrl](resource U) wire.RequestBuilder {
+	return makeRequestLineBuilder(wire.TRACE, resource)
+}
+
+// CONNECT writes the request line of a CONNECT HTTP request to the
+// specified resource.
+func CONNECT[U TargetUrl](resource U) wire.RequestBuilder {
+	return makeRequestLineBuilder(wire.CONNECT, resource)
+}
+
+// Method writes the request line of an HTTP request with the given
+// method to the specified resource. Use it for extension methods
+// there's no dedicated builder for, e.g.
+//
+//     Method("PROPFIND", "https://my.dav/docs/")
+//
+func Method[U TargetUrl](verb wire.Method, resource U) wire.RequestBuilder {
+	return makeRequestLineBuilder(verb, resource)
+}
+
+// QueryParam adds the given key-value pair to the query part of the
+// request URL. It doesn't matter whether QueryParam comes before or
+// after the builder that writes the request line, so you don't have
+// to rebuild the URL with yoorel just to add a query parameter.
+//
+// Example.
+//
+//     err := Request(
+//         GET("https://my.api/data?sort=asc"),
+//         QueryParam("page", "2"),
+//         QueryParam("tag", "a"),
+//         QueryParam("tag", "b"),
+//     ).Handle(
+//         ExpectSuccess,
+//     )
+//     // GET https://my.api/data?page=2&sort=asc&tag=a&tag=b
+//
+func QueryParam(key string, value string) wire.RequestBuilder {
+	return func(req wire.RequestWriter) error {
+		return req.QueryParam(key, value)
+	}
+}
@@ -1,9 +1,11 @@
 package client
 
 import (
+	"net/http"
 	"net/url"
 	"testing"
 
+	"github.com/c0c0n3/resto/hyper/wire"
 	e "github.com/c0c0n3/resto/util/err"
 	"github.com/c0c0n3/resto/yoorel"
 )
@@ -55,3 +57,50 @@ func TestToHttpUrlFromNil(t *testing.T) {
 		t.Errorf("want: error; got: %v", got)
 	}
 }
+
+func TestQueryParam(t *testing.T) {
+	mock := &mockClient{
+		resToSend: &http.Response{StatusCode: 200},
+	}
+	client := New(mock.Sender())
+
+	err := client.Request(
+		QueryParam("page", "2"),
+		GET("https://my.api/data?sort=asc"),
+		QueryParam("tag", "a"),
+		QueryParam("tag", "b"),
+	).Handle(
+		ExpectSuccess,
+	)
+
+	if err != nil {
+		t.Errorf("want: server reply; got: %v", err)
+	}
+	want := "page=2&sort=asc&tag=a&tag=b"
+	if got := mock.capturedReq.URL.RawQuery; got != want {
+		t.Errorf("want: %s; got: %s", want, got)
+	}
+}
+
+func TestRequestLineBuilders(t *testing.T) {
+	builders := map[wire.Method]wire.RequestBuilder{
+		wire.HEAD:    HEAD("https://my.api/data"),
+		wire.OPTIONS: OPTIONS("https://my.api/data"),
+		wire.TRACE:   TRACE("https://my.api/data"),
+		wire.CONNECT: CONNECT("https://my.api/data"),
+		"PROPFIND":   Method("PROPFIND", "https://my.api/data"),
+	}
+	for verb, build := range builders {
+		mock := &mockClient{
+			resToSend: &http.Response{StatusCode: 200},
+		}
+		err := New(mock.Sender()).Request(build).Handle(ExpectSuccess)
+
+		if err != nil {
+			t.Errorf("[%s] want: server reply; got: %v", verb, err)
+		}
+		if got := mock.capturedReq.Method; got != verb.String() {
+			t.Errorf("want: %s; got: %s", verb, got)
+		}
+	}
+}
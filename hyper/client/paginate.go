@@ -0,0 +1,301 @@
+package client
+
+import (
+	"net/url"
+	"strconv"
+
+	"github.com/c0c0n3/resto/hyper"
+	"github.com/c0c0n3/resto/hyper/wire"
+	e "github.com/c0c0n3/resto/util/err"
+	"github.com/c0c0n3/resto/yoorel"
+)
+
+func nilPageStrategyErr() e.Err[hyper.NilPtr] {
+	return e.Mk[hyper.NilPtr]("nil PageStrategy")
+}
+
+// PageStrategy tells a Pager how to get from one page to the next.
+// The Pager records the request for the first page in a
+// wire.RequestRecorder, then it lets the strategy tweak it, e.g. to add
+// query parameters, sends it and gives the strategy the response and
+// the page read from it. The strategy modifies the request recorder to
+// ask for the next page, e.g. by changing the URL, and says if there's
+// a next page at all.
+type PageStrategy[T any] interface {
+	// First gets the request for the first page ready.
+	First(request *wire.RequestRecorder) error
+	// Next changes the request to ask for the page that comes after the
+	// given one. Next returns false if the given page is the last one.
+	Next(request *wire.RequestRecorder, response wire.ResponseReader, page T) (bool, error)
+}
+
+func setUrl(request *wire.RequestRecorder, target *url.URL) error {
+	parsed := yoorel.BuilderFrom(target).Build()
+	if !parsed.IsRight() {
+		return parsed.Left()
+	}
+	request.Url = parsed.Right()
+	return nil
+}
+
+func setQueryParam(request *wire.RequestRecorder, key string, value string) error {
+	target := yoorel.ToURL(request.Url)
+	query := target.Query()
+	query.Set(key, value)
+	target.RawQuery = query.Encode()
+	return setUrl(request, target)
+}
+
+type nextLink[T any] struct{}
+
+// FollowNextLink is a PageStrategy to get the next page from the URL
+// in the response "Link" header with a relation type of "next", as in
+// GitHub-style APIs. Relative URLs get resolved against the URL of the
+// current page. If there's no next link, that's the last page.
+func FollowNextLink[T any]() PageStrategy[T] {
+	return nextLink[T]{}
+}
+
+func (s nextLink[T]) First(request *wire.RequestRecorder) error {
+	return nil
+}
+
+func (s nextLink[T]) Next(request *wire.RequestRecorder, response wire.ResponseReader, page T) (bool, error) {
+	links, err := hyper.ReadLinks(response)
+	if err != nil {
+		return false, err
+	}
+	next, ok := hyper.FindLink(links, "next")
+	if !ok {
+		return false, nil
+	}
+	ref, err := url.Parse(next.Target)
+	if err != nil {
+		return false, hyper.InvalidHeaderErr("Link: %v", err)
+	}
+	current := yoorel.ToURL(request.Url)
+	target := current.ResolveReference(ref)
+	if target.String() == current.String() {
+		return false, nil
+	}
+	return true, setUrl(request, target)
+}
+
+type cursorInBody[T any] struct {
+	param  string
+	cursor func(page T) string
+}
+
+// CursorInBody is a PageStrategy for APIs that put a cursor to the next
+// page in the response body. The cursor function extracts the cursor
+// from the page, returning an empty string if there's no next page.
+// The strategy then sends the cursor back in a query parameter with the
+// given name to get the next page.
+func CursorInBody[T any](param string, cursor func(page T) string) PageStrategy[T] {
+	return cursorInBody[T]{param: param, cursor: cursor}
+}
+
+func (s cursorInBody[T]) First(request *wire.RequestRecorder) error {
+	return nil
+}
+
+func (s cursorInBody[T]) Next(request *wire.RequestRecorder, response wire.ResponseReader, page T) (bool, error) {
+	next := s.cursor(page)
+	if next == "" {
+		return false, nil
+	}
+	return true, setQueryParam(request, s.param, next)
+}
+
+type offsetLimit[T any] struct {
+	offsetParam string
+	limitParam  string
+	limit       int
+	count       func(page T) int
+	offset      int
+}
+
+// OffsetLimit is a PageStrategy for APIs that take the position of the
+// first item and the number of items in a page as query parameters. The
+// strategy asks for limit items at a time, starting at offset zero, and
+// uses the count function to tell how many items are in a page. A page
+// with fewer than limit items is the last one.
+func OffsetLimit[T any](offsetParam string, limitParam string, limit int, count func(page T) int) PageStrategy[T] {
+	return &offsetLimit[T]{
+		offsetParam: offsetParam,
+		limitParam:  limitParam,
+		limit:       limit,
+		count:       count,
+	}
+}
+
+func (s *offsetLimit[T]) First(request *wire.RequestRecorder) error {
+	s.offset = 0
+	if err := setQueryParam(request, s.limitParam, strconv.Itoa(s.limit)); err != nil {
+		return err
+	}
+	return setQueryParam(request, s.offsetParam, "0")
+}
+
+func (s *offsetLimit[T]) Next(request *wire.RequestRecorder, response wire.ResponseReader, page T) (bool, error) {
+	n := s.count(page)
+	if n <= 0 || n < s.limit {
+		return false, nil
+	}
+	s.offset += n
+	return true, setQueryParam(request, s.offsetParam, strconv.Itoa(s.offset))
+}
+
+// Pager fetches the pages of a paginated resource, one at a time. You
+// use it like a bufio.Scanner. Example.
+//
+//     pages := Paginate(client, FollowNextLink[[]Repo](),
+//         GET("https://api.github.com/orgs/golang/repos"),
+//     )
+//     for pages.Next() {
+//         for _, repo := range pages.Page() {
+//             fmt.Println(repo.Name)
+//         }
+//     }
+//     if err := pages.Err(); err != nil {
+//         log.Fatal(err)
+//     }
+//
+// Pager decodes each page into a T the same way Fetch does and stops
+// after the last page or at the first error.
+type Pager[T any] struct {
+	client   *Client
+	strategy PageStrategy[T]
+	builders []wire.RequestBuilder
+	request  *wire.RequestRecorder
+	page     T
+	err      error
+	done     bool
+}
+
+// Paginate builds a Pager to fetch the pages of the resource the given
+// builders request, going from one page to the next with the given
+// strategy. If client is nil, the Pager uses a Client backed by
+// http.DefaultClient.
+func Paginate[T any](client *Client, strategy PageStrategy[T], builders ...wire.RequestBuilder) *Pager[T] {
+	if client == nil {
+		client = New()
+	}
+	return &Pager[T]{client: client, strategy: strategy, builders: builders}
+}
+
+func (p *Pager[T]) start() error {
+	if p.strategy == nil {
+		return nilPageStrategyErr()
+	}
+	for _, build := range p.builders {
+		if build == nil {
+			return hyper.NilRequestBuilderErr()
+		}
+	}
+	request, err := wire.Record(makeRequestBuilder(p.builders...))
+	if err != nil {
+		return err
+	}
+	if _, err := request.BufferBody(); err != nil {
+		return err
+	}
+	p.request = request
+	return p.strategy.First(request)
+}
+
+func (p *Pager[T]) fail(err error) bool {
+	p.err = err
+	p.done = true
+	return false
+}
+
+// Next fetches the next page, returning false if there are no more
+// pages or there was an error. Call Err to tell the two apart.
+func (p *Pager[T]) Next() bool {
+	if p.done {
+		return false
+	}
+	if p.request == nil {
+		if err := p.start(); err != nil {
+			return p.fail(err)
+		}
+	}
+
+	var page T
+	more := false
+	err := p.client.Request(p.request.Replay).Handle(
+		ExpectSuccess,
+		func(response wire.ResponseReader) error {
+			deserializer, err := deserializerFor(response, &page)
+			if err != nil {
+				return err
+			}
+			if err := hyper.ReadBody(response, deserializer); err != nil {
+				return err
+			}
+			more, err = p.strategy.Next(p.request, response, page)
+			return err
+		},
+	)
+	if err != nil {
+		return p.fail(err)
+	}
+	p.page = page
+	p.done = !more
+	return true
+}
+
+// Page returns the page Next fetched.
+func (p *Pager[T]) Page() T {
+	return p.page
+}
+
+// Err returns the error that stopped the Pager, if any.
+func (p *Pager[T]) Err() error {
+	return p.err
+}
+
+// ItemIterator goes through the items in the pages a Pager fetches,
+// one item at a time, fetching pages as needed. You use it like a Pager.
+type ItemIterator[T any, E any] struct {
+	pages *Pager[T]
+	items func(page T) []E
+	buf   []E
+	item  E
+}
+
+// Items builds an ItemIterator to go through the items in the pages the
+// given Pager fetches. The items function extracts the items from a page.
+//
+//     repos := Items(pages, func(page []Repo) []Repo { return page })
+//     for repos.Next() {
+//         fmt.Println(repos.Item().Name)
+//     }
+//
+func Items[T any, E any](pages *Pager[T], items func(page T) []E) *ItemIterator[T, E] {
+	return &ItemIterator[T, E]{pages: pages, items: items}
+}
+
+// Next moves on to the next item, returning false if there are no more
+// items or there was an error. Call Err to tell the two apart.
+func (p *ItemIterator[T, E]) Next() bool {
+	for len(p.buf) == 0 {
+		if !p.pages.Next() {
+			return false
+		}
+		p.buf = p.items(p.pages.Page())
+	}
+	p.item, p.buf = p.buf[0], p.buf[1:]
+	return true
+}
+
+// Item returns the item Next moved on to.
+func (p *ItemIterator[T, E]) Item() E {
+	return p.item
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (p *ItemIterator[T, E]) Err() error {
+	return p.pages.Err()
+}
@@ -0,0 +1,197 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/c0c0n3/resto/hyper"
+)
+
+func linkedPagesServer(pages [][]int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			k, _ := strconv.Atoi(r.URL.Query().Get("page"))
+			if k >= len(pages) {
+				http.Error(w, "no such page", 404)
+				return
+			}
+			if k+1 < len(pages) {
+				next := fmt.Sprintf(`</items?page=%d&size=%s>; rel="next"`,
+					k+1, r.URL.Query().Get("size"))
+				w.Header().Add("Link", next)
+			}
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, toJson(pages[k]))
+		}))
+}
+
+func toJson(xs []int) string {
+	data, _ := json.Marshal(xs)
+	return string(data)
+}
+
+func TestFollowNextLink(t *testing.T) {
+	server := linkedPagesServer([][]int{{1, 2}, {3, 4}, {5}})
+	defer server.Close()
+
+	pages := Paginate(nil, FollowNextLink[[]int](),
+		GET(server.URL+"/items"),
+		QueryParam("size", "2"),
+	)
+	got := [][]int{}
+	for pages.Next() {
+		got = append(got, pages.Page())
+	}
+
+	if pages.Err() != nil {
+		t.Errorf("want: no error; got: %v", pages.Err())
+	}
+	want := [][]int{{1, 2}, {3, 4}, {5}}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("want: %v; got: %v", want, got)
+	}
+	if pages.Next() {
+		t.Errorf("want: no more pages")
+	}
+}
+
+func TestItems(t *testing.T) {
+	server := linkedPagesServer([][]int{{1, 2}, {}, {3}})
+	defer server.Close()
+
+	pages := Paginate(nil, FollowNextLink[[]int](), GET(server.URL+"/items"))
+	items := Items(pages, func(page []int) []int { return page })
+	got := []int{}
+	for items.Next() {
+		got = append(got, items.Item())
+	}
+
+	if items.Err() != nil {
+		t.Errorf("want: no error; got: %v", items.Err())
+	}
+	if !reflect.DeepEqual([]int{1, 2, 3}, got) {
+		t.Errorf("want: [1 2 3]; got: %v", got)
+	}
+}
+
+func TestPagerStopsOnError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Query().Get("page") == "" {
+				w.Header().Set("Link", `</items?page=1>; rel="next"`)
+				w.Header().Set("Content-Type", "application/json")
+				fmt.Fprint(w, "[1]")
+				return
+			}
+			http.Error(w, "boom", 500)
+		}))
+	defer server.Close()
+
+	pages := Paginate(nil, FollowNextLink[[]int](), GET(server.URL+"/items"))
+	count := 0
+	for pages.Next() {
+		count++
+	}
+
+	if count != 1 {
+		t.Errorf("want: 1 page; got: %d", count)
+	}
+	var resErr *hyper.ResponseError
+	if !errors.As(pages.Err(), &resErr) || resErr.StatusCode != 500 {
+		t.Errorf("want: response err; got: %v", pages.Err())
+	}
+}
+
+type cursorPage struct {
+	Items      []int
+	NextCursor string
+}
+
+func TestCursorInBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			switch r.URL.Query().Get("cursor") {
+			case "":
+				fmt.Fprint(w, `{"Items": [1, 2], "NextCursor": "abc"}`)
+			case "abc":
+				fmt.Fprint(w, `{"Items": [3], "NextCursor": ""}`)
+			default:
+				http.Error(w, "bad cursor", 400)
+			}
+		}))
+	defer server.Close()
+
+	strategy := CursorInBody("cursor", func(p cursorPage) string {
+		return p.NextCursor
+	})
+	pages := Paginate(nil, strategy, GET(server.URL))
+	items := Items(pages, func(p cursorPage) []int { return p.Items })
+	got := []int{}
+	for items.Next() {
+		got = append(got, items.Item())
+	}
+
+	if items.Err() != nil {
+		t.Errorf("want: no error; got: %v", items.Err())
+	}
+	if !reflect.DeepEqual([]int{1, 2, 3}, got) {
+		t.Errorf("want: [1 2 3]; got: %v", got)
+	}
+}
+
+func TestOffsetLimit(t *testing.T) {
+	data := []int{1, 2, 3, 4, 5}
+	requests := []string{}
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			requests = append(requests, r.URL.RawQuery)
+			offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+			limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+			end := offset + limit
+			if end > len(data) {
+				end = len(data)
+			}
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, toJson(data[offset:end]))
+		}))
+	defer server.Close()
+
+	strategy := OffsetLimit("offset", "limit", 2, func(page []int) int {
+		return len(page)
+	})
+	pages := Paginate(nil, strategy, GET(server.URL+"?sort=asc"))
+	items := Items(pages, func(page []int) []int { return page })
+	got := []int{}
+	for items.Next() {
+		got = append(got, items.Item())
+	}
+
+	if !reflect.DeepEqual(data, got) {
+		t.Errorf("want: %v; got: %v", data, got)
+	}
+	want := []string{
+		"limit=2&offset=0&sort=asc",
+		"limit=2&offset=2&sort=asc",
+		"limit=2&offset=4&sort=asc",
+	}
+	if !reflect.DeepEqual(want, requests) {
+		t.Errorf("want: %v; got: %v", want, requests)
+	}
+}
+
+func TestPaginateNilStrategy(t *testing.T) {
+	pages := Paginate[[]int](nil, nil, GET("http://nowhere"))
+	if pages.Next() {
+		t.Errorf("want: no pages")
+	}
+	if pages.Err() == nil {
+		t.Errorf("want: nil ptr err; got: nil")
+	}
+}
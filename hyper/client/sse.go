@@ -0,0 +1,151 @@
+package client
+
+import (
+	"context"
+	"time"
+
+	"github.com/c0c0n3/resto/hyper"
+	"github.com/c0c0n3/resto/hyper/wire"
+	"github.com/c0c0n3/resto/mime"
+)
+
+// DefaultEventRetry is how long Subscribe waits before reconnecting if
+// the server didn't say.
+const DefaultEventRetry = 3 * time.Second
+
+// ReadEvents builds a wire.ResponseHandler to read a "text/event-stream"
+// response body, calling the given function with each Server-Sent Event
+// as soon as it comes in. The handler returns when the stream ends or at
+// the first error the function returns. It doesn't reconnect, use
+// Subscribe for that.
+func ReadEvents(each func(event hyper.Event) error) wire.ResponseHandler {
+	return func(response wire.ResponseReader) error {
+		return hyper.ReadEvents(response, each)
+	}
+}
+
+// Subscribe connects to the Server-Sent Events stream the given builders
+// request and calls each with each event as soon as it comes in.
+//
+// If the connection drops or can't be made in the first place, Subscribe
+// waits for the reconnection delay the server set through the "retry"
+// field, or DefaultEventRetry if it set none, and then reconnects with a
+// "Last-Event-ID" header so the server can pick up from where it left
+// off. Subscribe stops
+//
+//   - when you cancel the context, returning the context error;
+//   - at the first error each returns, returning that error;
+//   - if the request can't be built or sent, returning that error,
+//     unless it's a connection error---see wire.IsConnectionError;
+//   - if the server replies with a 204, returning nil;
+//   - if the server replies with any other status outside of the 2xx
+//     range or with a content type other than "text/event-stream",
+//     returning the same error ExpectSuccess or ExpectContentType do.
+//
+// Example.
+//
+//     ctx, stop := context.WithCancel(context.Background())
+//     defer stop()
+//     err := Subscribe(ctx, nil,
+//         func(event hyper.Event) error {
+//             fmt.Printf("%s: %s\n", event.Type, event.Data)
+//             return nil
+//         },
+//         GET("https://my.api/status/stream"),
+//     )
+//
+// Since Subscribe reconnects through the client's Sender, any Middleware
+// in there, e.g. authentication, runs on each connection. If client is
+// nil, Subscribe uses a Client backed by http.DefaultClient.
+func Subscribe(ctx context.Context, client *Client, each func(event hyper.Event) error,
+	builders ...wire.RequestBuilder) error {
+	if client == nil {
+		client = New()
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if each == nil {
+		return hyper.NilEventFuncErr()
+	}
+	for _, build := range builders {
+		if build == nil {
+			return hyper.NilRequestBuilderErr()
+		}
+	}
+
+	decoder := &hyper.EventDecoder{}
+	retry := DefaultEventRetry
+	for {
+		stop, err := subscribeOnce(ctx, client, each, decoder, builders)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if stop {
+			return err
+		}
+		if decoder.Retry > 0 {
+			retry = decoder.Retry
+		}
+
+		timer := time.NewTimer(retry)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// subscribeOnce reads the event stream over a single connection. It
+// returns true if Subscribe shouldn't reconnect.
+func subscribeOnce(ctx context.Context, client *Client, each func(event hyper.Event) error,
+	decoder *hyper.EventDecoder, builders []wire.RequestBuilder) (bool, error) {
+	request := append([]wire.RequestBuilder{}, builders...)
+	request = append(request,
+		Accept(mime.EVENT_STREAM),
+		Header("Cache-Control", "no-cache"),
+	)
+	if decoder.LastEventId != "" {
+		request = append(request, Header("Last-Event-ID", decoder.LastEventId))
+	}
+
+	replied, stop := false, false
+	var eachErr error
+	decoder.Each = func(event hyper.Event) error {
+		if err := each(event); err != nil {
+			eachErr = err
+			return err
+		}
+		return nil
+	}
+	err := client.RequestCtx(ctx, request...).Handle(
+		func(response wire.ResponseReader) error {
+			replied, stop = true, true
+			if code, _ := response.StatusLine(); code.Value() == 204 {
+				return nil
+			}
+			if err := ExpectSuccess(response); err != nil {
+				return err
+			}
+			if err := ExpectContentType(mime.EVENT_STREAM)(response); err != nil {
+				return err
+			}
+			stop = false
+			return hyper.ReadBody(response, decoder)
+		},
+	)
+	if eachErr != nil {
+		return true, eachErr
+	}
+	if !replied {
+		return !wire.IsConnectionError(err), err
+	}
+	return stop, err
+	// NOTE. If the handler didn't run, err is a build or transport error.
+	// Reconnecting won't fix a bad URL or an invalid certificate, so we
+	// only go again if the connection couldn't be made. Once the handler
+	// runs, any error other than a bad status or content type comes from
+	// reading the stream, i.e. the connection dropped.
+}
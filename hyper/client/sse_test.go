@@ -0,0 +1,244 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/c0c0n3/resto/hyper"
+	"github.com/c0c0n3/resto/hyper/wire"
+	e "github.com/c0c0n3/resto/util/err"
+)
+
+// eventServer sends one event per connection, then hangs up, so the
+// client has to reconnect to get the next one.
+type eventServer struct {
+	mutex        sync.Mutex
+	lastEventIds []string
+	headers      http.Header
+}
+
+func (p *eventServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	p.mutex.Lock()
+	last := r.Header.Get("Last-Event-ID")
+	p.lastEventIds = append(p.lastEventIds, last)
+	p.headers = r.Header.Clone()
+	p.mutex.Unlock()
+
+	next := 1
+	fmt.Sscanf(last, "%d", &next)
+	if last != "" {
+		next++
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	fmt.Fprintf(w, "retry: 5\n\nid: %d\nevent: tick\ndata: %d\n\n", next, next)
+}
+
+func TestSubscribeReconnectsWithLastEventId(t *testing.T) {
+	handler := &eventServer{}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	ctx, stop := context.WithCancel(context.Background())
+	defer stop()
+	got := []string{}
+	err := Subscribe(ctx, nil,
+		func(event hyper.Event) error {
+			got = append(got, event.Type+":"+event.Data)
+			if len(got) == 3 {
+				stop()
+			}
+			return nil
+		},
+		GET(server.URL),
+	)
+
+	if err != context.Canceled {
+		t.Errorf("want: cancelled; got: %v", err)
+	}
+	if strings.Join(got, " ") != "tick:1 tick:2 tick:3" {
+		t.Errorf("want: 3 ticks; got: %v", got)
+	}
+	if strings.Join(handler.lastEventIds, ",") != ",1,2" {
+		t.Errorf("want: ,1,2; got: %v", handler.lastEventIds)
+	}
+	if handler.headers.Get("Accept") != "text/event-stream" ||
+		handler.headers.Get("Cache-Control") != "no-cache" {
+		t.Errorf("want: event stream headers; got: %v", handler.headers)
+	}
+}
+
+func TestSubscribeStopsOnCancelWhileStreaming(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/event-stream")
+			io.WriteString(w, "data: hello\n\n")
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+		}))
+	defer server.Close()
+
+	ctx, stop := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- Subscribe(ctx, nil,
+			func(event hyper.Event) error {
+				stop()
+				return nil
+			},
+			GET(server.URL),
+		)
+	}()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("want: cancelled; got: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("want: stop on cancel; got: still running")
+	}
+}
+
+func TestSubscribeStopsOnEachErr(t *testing.T) {
+	server := httptest.NewServer(&eventServer{})
+	defer server.Close()
+
+	boom := errors.New("boom")
+	err := Subscribe(context.Background(), nil,
+		func(event hyper.Event) error { return boom },
+		GET(server.URL),
+	)
+	if err != boom {
+		t.Errorf("want: boom; got: %v", err)
+	}
+}
+
+func TestSubscribeStopsOnNoContent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(204)
+		}))
+	defer server.Close()
+
+	err := Subscribe(context.Background(), nil,
+		func(event hyper.Event) error { return nil },
+		GET(server.URL),
+	)
+	if err != nil {
+		t.Errorf("want: nil; got: %v", err)
+	}
+}
+
+func TestSubscribeFailsOnBadResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/json" {
+				w.Header().Set("Content-Type", "application/json")
+				io.WriteString(w, "{}")
+				return
+			}
+			w.WriteHeader(503)
+		}))
+	defer server.Close()
+
+	each := func(event hyper.Event) error { return nil }
+	err := Subscribe(context.Background(), nil, each, GET(server.URL+"/down"))
+	var resErr *hyper.ResponseError
+	if !errors.As(err, &resErr) || resErr.StatusCode != 503 {
+		t.Errorf("want: 503 response error; got: %v", err)
+	}
+
+	err = Subscribe(context.Background(), nil, each, GET(server.URL+"/json"))
+	if _, ok := err.(e.Err[hyper.UnexpectedResponse]); !ok {
+		t.Errorf("want: unexpected response error; got: %v", err)
+	}
+}
+
+func TestSubscribeStopsOnCancelWhileWaitingToReconnect(t *testing.T) {
+	refused := &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}
+	mock := &mockClient{errToSend: &url.Error{
+		Op: "Get", URL: "http://my.api/events", Err: refused,
+	}}
+
+	ctx, stop := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer stop()
+	err := Subscribe(ctx, New(mock.Sender()),
+		func(event hyper.Event) error { return nil },
+		GET("http://my.api/events"),
+	)
+	if err != context.DeadlineExceeded {
+		t.Errorf("want: deadline exceeded; got: %v", err)
+	}
+	if mock.capturedReq == nil {
+		t.Errorf("want: connection attempt; got: none")
+	}
+}
+
+func TestSubscribeFailsOnBadRequest(t *testing.T) {
+	each := func(event hyper.Event) error { return nil }
+	requests := [][]wire.RequestBuilder{
+		{GET("ftp://x/%zz")},
+		{GET("http://my.api/events"), func(wire.RequestWriter) error {
+			return errors.New("can't build")
+		}},
+	}
+	for k, request := range requests {
+		ctx, stop := context.WithTimeout(context.Background(), time.Second)
+		err := Subscribe(ctx, nil, each, request...)
+		stop()
+
+		if err == nil || err == context.DeadlineExceeded {
+			t.Errorf("[%d] want: request error; got: %v", k, err)
+		}
+	}
+
+	mock := &mockClient{errToSend: &url.Error{
+		Op: "Get", URL: "ftp://my.api/events",
+		Err: errors.New(`unsupported protocol scheme "ftp"`),
+	}}
+	ctx, stop := context.WithTimeout(context.Background(), time.Second)
+	defer stop()
+	err := Subscribe(ctx, New(mock.Sender()), each, GET("http://my.api/events"))
+	if _, ok := err.(*url.Error); !ok {
+		t.Errorf("want: url error; got: %v", err)
+	}
+}
+
+func TestSubscribeNilArgs(t *testing.T) {
+	if err := Subscribe(context.Background(), nil, nil, GET("http://my.api")); err == nil {
+		t.Errorf("want: nil ptr error; got: nil")
+	}
+	each := func(event hyper.Event) error { return nil }
+	if err := Subscribe(context.Background(), nil, each, nil); err == nil {
+		t.Errorf("want: nil ptr error; got: nil")
+	}
+}
+
+func TestReadEvents(t *testing.T) {
+	client := fetchMock(200, "text/event-stream", "id: 1\ndata: a\n\ndata: b\n\n")
+	got := []hyper.Event{}
+	err := client.Request(GET("https://my.api/events")).Handle(
+		ReadEvents(func(event hyper.Event) error {
+			got = append(got, event)
+			return nil
+		}),
+	)
+
+	if err != nil || len(got) != 2 {
+		t.Fatalf("want: 2 events; got: %v, %v", got, err)
+	}
+	if got[1].Id != "1" || got[1].Data != "b" {
+		t.Errorf("want: 1, b; got: %+v", got[1])
+	}
+}
@@ -0,0 +1,241 @@
+package hyper
+
+import (
+	"io"
+	"sync"
+
+	"github.com/c0c0n3/resto/mime"
+)
+
+// Codec builds serializers and deserializers for the bodies of some
+// media type, e.g. JSON.
+type Codec struct {
+	// Encode builds a BodySerializer to turn the given data into a body.
+	// It returns an error if the codec can't encode that kind of data.
+	// Nil if the codec can only decode.
+	Encode func(data any) (BodySerializer, error)
+	// Decode builds a BodyDeserializer to read a body into the given
+	// output, which is typically a pointer. It returns an error if the
+	// codec can't decode into that kind of output. Nil if the codec can
+	// only encode.
+	Decode func(output any) (BodyDeserializer, error)
+}
+
+type codecPattern struct {
+	pattern mime.ParsedType
+	codec   Codec
+}
+
+// CodecRegistry maps media types to the Codecs that handle them. You
+// can register a Codec for a media type like "application/yaml" or for
+// a media range like "application/*+json", in which case the Codec
+// handles any media type in that range that doesn't have a Codec of its
+// own. CodecRegistry is safe for concurrent use.
+type CodecRegistry struct {
+	mutex    sync.RWMutex
+	exact    map[mime.MediaType]Codec
+	patterns []codecPattern // most recently registered first
+}
+
+// NewCodecRegistry builds an empty CodecRegistry.
+func NewCodecRegistry() *CodecRegistry {
+	return &CodecRegistry{exact: make(map[mime.MediaType]Codec)}
+}
+
+// Register adds a Codec for the given media type or range, replacing
+// any Codec registered earlier for the same type or range. Parameters
+// in a media type get ignored, so "text/plain; charset=utf-8" is the
+// same as "text/plain".
+func (r *CodecRegistry) Register(mediaType mime.MediaType, codec Codec) error {
+	parsed, err := mediaType.Parse()
+	if err != nil {
+		return err
+	}
+	parsed.Params = nil
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if !parsed.IsWildcard() {
+		r.exact[parsed.Essence()] = codec
+		return nil
+	}
+	patterns := []codecPattern{{parsed, codec}}
+	for _, p := range r.patterns {
+		if p.pattern.Essence() != parsed.Essence() {
+			patterns = append(patterns, p)
+		}
+	}
+	r.patterns = patterns
+	return nil
+}
+
+// Lookup finds the Codec for the given media type. A Codec registered
+// for the media type itself wins over any registered for a range the
+// media type is in. If there's more than one such range, the one
+// registered last wins.
+func (r *CodecRegistry) Lookup(mediaType mime.MediaType) (Codec, bool) {
+	parsed, err := mediaType.Parse()
+	if err != nil {
+		return Codec{}, false
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	if codec, ok := r.exact[parsed.Essence()]; ok {
+		return codec, true
+	}
+	for _, p := range r.patterns {
+		if p.pattern.Matches(parsed) {
+			return p.codec, true
+		}
+	}
+	return Codec{}, false
+}
+
+// Encoder builds a BodySerializer to turn the given data into a body of
+// the given media type using the registered Codec.
+func (r *CodecRegistry) Encoder(mediaType mime.MediaType, data any) (BodySerializer, error) {
+	codec, ok := r.Lookup(mediaType)
+	if !ok || codec.Encode == nil {
+		return nil, NoCodecErr("no encoder for media type: %s", mediaType)
+	}
+	return codec.Encode(data)
+}
+
+// Decoder builds a BodyDeserializer to read a body of the given media
+// type into the given output using the registered Codec. If the output
+// is a *string or *[]byte though, the BodyDeserializer always reads in
+// the raw body whatever the media type.
+func (r *CodecRegistry) Decoder(mediaType mime.MediaType, output any) (BodyDeserializer, error) {
+	if raw, ok := rawDecoder(output); ok {
+		return raw, nil
+	}
+	codec, ok := r.Lookup(mediaType)
+	if !ok || codec.Decode == nil {
+		return nil, NoCodecErr("no decoder for media type: %s", mediaType)
+	}
+	return codec.Decode(output)
+}
+
+// Codecs is the CodecRegistry the client and server packages use to
+// pick a Codec by media type. It comes with Codecs for
+//
+//   - JSON: "application/json" and "application/*+json";
+//   - XML: "application/xml", "text/xml" and "application/*+xml";
+//   - URL-encoded forms: "application/x-www-form-urlencoded";
+//   - text: "text/plain";
+//   - raw bytes: "application/octet-stream".
+//
+// Register your own Codecs to handle other media types, e.g.
+//
+//     hyper.Codecs.Register(mime.YAML, hyper.Codec{
+//         Encode: func(data any) (hyper.BodySerializer, error) {
+//             return &YamlBody{Data: data}, nil
+//         },
+//         Decode: func(output any) (hyper.BodyDeserializer, error) {
+//             return &YamlBody{Data: output}, nil
+//         },
+//     })
+//
+var Codecs = defaultCodecs()
+
+func defaultCodecs() *CodecRegistry {
+	json := Codec{
+		Encode: func(data any) (BodySerializer, error) {
+			return &JsonBody{Data: data}, nil
+		},
+		Decode: func(output any) (BodyDeserializer, error) {
+			return &JsonBody{Data: output}, nil
+		},
+	}
+	xml := Codec{
+		Encode: func(data any) (BodySerializer, error) {
+			return &XmlBody{Data: data}, nil
+		},
+		Decode: func(output any) (BodyDeserializer, error) {
+			return &XmlBody{Data: output}, nil
+		},
+	}
+	form := Codec{
+		Encode: func(data any) (BodySerializer, error) {
+			return &FormBody{Data: data}, nil
+		},
+		Decode: func(output any) (BodyDeserializer, error) {
+			return &FormBody{Data: output}, nil
+		},
+	}
+	raw := Codec{Encode: rawEncoder, Decode: rawOnly}
+
+	r := NewCodecRegistry()
+	r.Register(mime.JSON, json)
+	r.Register("application/*+json", json)
+	r.Register(mime.XML, xml)
+	r.Register("text/xml", xml)
+	r.Register("application/*+xml", xml)
+	r.Register(mime.URL_ENCODED, form)
+	r.Register(mime.PLAIN_TEXT, raw)
+	r.Register(mime.OCTET_STREAM, raw)
+	return r
+}
+
+func rawEncoder(data any) (BodySerializer, error) {
+	switch d := data.(type) {
+	case string:
+		return &StringBody{Data: d}, nil
+	case []byte:
+		return &ByteBody{Data: d}, nil
+	case io.ReadCloser:
+		return &StreamingBody{Data: d}, nil
+	case io.Reader:
+		return &StreamingBody{Data: io.NopCloser(d)}, nil
+	}
+	return nil, NoCodecErr("can't encode a %T as raw content", data)
+}
+
+func rawOnly(output any) (BodyDeserializer, error) {
+	return nil, NoCodecErr("can't decode raw content into a %T", output)
+	// NOTE. Decoder takes care of *string and *[]byte before it gets
+	// here, see rawDecoder.
+}
+
+func rawDecoder(output any) (BodyDeserializer, bool) {
+	switch target := output.(type) {
+	case *string:
+		return &stringTarget{target}, true
+	case *[]byte:
+		return &bytesTarget{target}, true
+	}
+	return nil, false
+}
+
+type stringTarget struct {
+	output *string
+}
+
+func (p *stringTarget) Streaming() bool {
+	return false
+}
+
+func (p *stringTarget) Deserialize(body io.ReadCloser) error {
+	content := &StringBody{}
+	err := content.Deserialize(body)
+	*p.output = content.Data
+	return err
+}
+
+type bytesTarget struct {
+	output *[]byte
+}
+
+func (p *bytesTarget) Streaming() bool {
+	return false
+}
+
+func (p *bytesTarget) Deserialize(body io.ReadCloser) error {
+	content := &ByteBody{}
+	err := content.Deserialize(body)
+	*p.output = content.Data
+	return err
+}
@@ -0,0 +1,174 @@
+package hyper
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/c0c0n3/resto/mime"
+	"github.com/c0c0n3/resto/util/bytez"
+	e "github.com/c0c0n3/resto/util/err"
+)
+
+type codecData struct {
+	Greeting string `json:"greeting" xml:"greeting"`
+}
+
+func serializeToString(t *testing.T, serializer BodySerializer) string {
+	reader, _, err := serializer.Serialize()
+	if err != nil {
+		t.Fatalf("want: serialized body; got: %v", err)
+	}
+	defer reader.Close()
+	data, _ := io.ReadAll(reader)
+	return string(data)
+}
+
+func TestDefaultCodecsEncode(t *testing.T) {
+	data := &codecData{Greeting: "howzit!"}
+	cases := []struct {
+		mediaType mime.MediaType
+		want      string
+	}{
+		{mime.JSON, `{"greeting":"howzit!"}`},
+		{"application/vnd.api+json; charset=utf-8", `{"greeting":"howzit!"}`},
+		{mime.XML, `<codecData><greeting>howzit!</greeting></codecData>`},
+		{"text/xml", `<codecData><greeting>howzit!</greeting></codecData>`},
+		{"application/atom+xml", `<codecData><greeting>howzit!</greeting></codecData>`},
+	}
+	for _, k := range cases {
+		serializer, err := Codecs.Encoder(k.mediaType, data)
+		if err != nil {
+			t.Fatalf("[%s] want: encoder; got: %v", k.mediaType, err)
+		}
+		if got := serializeToString(t, serializer); got != k.want {
+			t.Errorf("[%s] want: %s; got: %s", k.mediaType, k.want, got)
+		}
+	}
+}
+
+func TestDefaultCodecsDecode(t *testing.T) {
+	cases := []struct {
+		mediaType mime.MediaType
+		body      string
+	}{
+		{"application/problem+json", `{"greeting":"howzit!"}`},
+		{"text/xml; charset=utf-8", `<x><greeting>howzit!</greeting></x>`},
+	}
+	for _, k := range cases {
+		got := &codecData{}
+		deserializer, err := Codecs.Decoder(k.mediaType, got)
+		if err != nil {
+			t.Fatalf("[%s] want: decoder; got: %v", k.mediaType, err)
+		}
+		err = deserializer.Deserialize(bytez.NewBufferFrom([]byte(k.body)))
+		if err != nil || got.Greeting != "howzit!" {
+			t.Errorf("[%s] want: howzit!; got: %v, %v", k.mediaType, got, err)
+		}
+	}
+}
+
+func TestDecodeRawWhateverMediaType(t *testing.T) {
+	var text string
+	deserializer, err := Codecs.Decoder("image/png", &text)
+	if err != nil {
+		t.Fatalf("want: raw decoder; got: %v", err)
+	}
+	deserializer.Deserialize(bytez.NewBufferFrom([]byte("howzit!")))
+	if text != "howzit!" {
+		t.Errorf("want: howzit!; got: %s", text)
+	}
+}
+
+func TestRawCodecEncode(t *testing.T) {
+	for _, data := range []any{"howzit!", []byte("howzit!"), strings.NewReader("howzit!")} {
+		serializer, err := Codecs.Encoder(mime.PLAIN_TEXT, data)
+		if err != nil {
+			t.Fatalf("[%T] want: encoder; got: %v", data, err)
+		}
+		if got := serializeToString(t, serializer); got != "howzit!" {
+			t.Errorf("[%T] want: howzit!; got: %s", data, got)
+		}
+	}
+	if _, err := Codecs.Encoder(mime.OCTET_STREAM, 1); err == nil {
+		t.Errorf("want: error; got: nil")
+	}
+}
+
+func TestNoCodec(t *testing.T) {
+	r := NewCodecRegistry()
+	if _, err := r.Encoder(mime.YAML, 1); err == nil {
+		t.Errorf("want: no encoder error; got: nil")
+	} else if _, ok := err.(e.Err[NoCodec]); !ok {
+		t.Errorf("want: no codec error; got: %v", err)
+	}
+	if _, err := r.Decoder(mime.YAML, &codecData{}); err == nil {
+		t.Errorf("want: no decoder error; got: nil")
+	}
+	if _, ok := r.Lookup("not a media type"); ok {
+		t.Errorf("want: no codec; got: codec")
+	}
+}
+
+func TestRegisterCodec(t *testing.T) {
+	yaml := Codec{
+		Encode: func(data any) (BodySerializer, error) {
+			return &StringBody{Data: "yaml"}, nil
+		},
+	}
+	r := NewCodecRegistry()
+	if err := r.Register(mime.YAML+"; charset=utf-8", yaml); err != nil {
+		t.Fatalf("want: registered; got: %v", err)
+	}
+	serializer, err := r.Encoder("application/YAML", 1)
+	if err != nil {
+		t.Fatalf("want: encoder; got: %v", err)
+	}
+	if got := serializeToString(t, serializer); got != "yaml" {
+		t.Errorf("want: yaml; got: %s", got)
+	}
+	if _, err := r.Decoder(mime.YAML, &codecData{}); err == nil {
+		t.Errorf("want: no decoder error; got: nil")
+	}
+	if err := r.Register("not a media type", yaml); err == nil {
+		t.Errorf("want: error; got: nil")
+	}
+}
+
+func TestExactCodecBeatsPattern(t *testing.T) {
+	codec := func(name string) Codec {
+		return Codec{
+			Encode: func(data any) (BodySerializer, error) {
+				return &StringBody{Data: name}, nil
+			},
+		}
+	}
+	r := NewCodecRegistry()
+	r.Register("application/vnd.my+json", codec("exact"))
+	r.Register("application/*+json", codec("suffix"))
+	r.Register("application/*", codec("subtype"))
+
+	cases := []struct {
+		mediaType mime.MediaType
+		want      string
+	}{
+		{"application/vnd.my+json", "exact"},
+		{"application/vnd.other+json", "subtype"},
+		{"application/pdf", "subtype"},
+	}
+	for _, k := range cases {
+		serializer, err := r.Encoder(k.mediaType, 1)
+		if err != nil {
+			t.Fatalf("[%s] want: encoder; got: %v", k.mediaType, err)
+		}
+		if got := serializeToString(t, serializer); got != k.want {
+			t.Errorf("[%s] want: %s; got: %s", k.mediaType, k.want, got)
+		}
+	}
+
+	r.Register("application/*+json", codec("suffix again"))
+	serializer, _ := r.Encoder("application/vnd.other+json", 1)
+	if got := serializeToString(t, serializer); got != "suffix again" {
+		t.Errorf("want: suffix again; got: %s", got)
+	}
+}
@@ -14,5 +14,9 @@ requests which is independent of the underlying client lib you can
 use. It works with "net/http" but can also happily work with any
 other library that has common, run-of-the-mill HTTP functionality.
 
+The hyper.server package does the same on the server side. It uses
+the very same prim ops to implement an EDSL to read the client's
+request and build the response to send back.
+
 */
 package hyper
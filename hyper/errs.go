@@ -11,6 +11,10 @@ func NilMessageWriterErr() err.Err[NilPtr] {
 	return err.Mk[NilPtr]("nil MessageWriter")
 }
 
+func NilMessageReaderErr() err.Err[NilPtr] {
+	return err.Mk[NilPtr]("nil MessageReader")
+}
+
 func NilBodySerializerErr() err.Err[NilPtr] {
 	return err.Mk[NilPtr]("nil BodySerializer")
 }
@@ -27,13 +31,72 @@ func NilResponseHandlerErr() err.Err[NilPtr] {
 	return err.Mk[NilPtr]("nil ResponseHandler")
 }
 
+func NilResponseBuilderErr() err.Err[NilPtr] {
+	return err.Mk[NilPtr]("nil ResponseBuilder")
+}
+
+func NilRequestMatcherErr() err.Err[NilPtr] {
+	return err.Mk[NilPtr]("nil RequestMatcher")
+}
+
+func NilContextErr() err.Err[NilPtr] {
+	return err.Mk[NilPtr]("nil Context")
+}
+
 func NilBearerTokenProviderErr() err.Err[NilPtr] {
 	return err.Mk[NilPtr]("nil BearerTokenProvider")
 }
 
+func NilPartContentErr(name string) err.Err[NilPtr] {
+	return err.Mk[NilPtr]("nil content for form part %s", name)
+}
+
+func NilNdjsonFuncErr(name string) err.Err[NilPtr] {
+	return err.Mk[NilPtr]("nil NDJSON %s function", name)
+}
+
+func NilEventFuncErr() err.Err[NilPtr] {
+	return err.Mk[NilPtr]("nil Server-Sent Events function")
+}
+
 // An unexpected server response.
 type UnexpectedResponse string
 
 func UnexpectedResponseErr(format string, args ...any) err.Err[UnexpectedResponse] {
 	return err.Mk[UnexpectedResponse](format, args...)
 }
+
+// A header value that doesn't conform to the HTTP spec.
+type InvalidHeader string
+
+func InvalidHeaderErr(format string, args ...any) err.Err[InvalidHeader] {
+	return err.Mk[InvalidHeader](format, args...)
+}
+
+// Form data that can't be encoded or decoded.
+type InvalidForm string
+
+func InvalidFormErr(format string, args ...any) err.Err[InvalidForm] {
+	return err.Mk[InvalidForm](format, args...)
+}
+
+// Malformed newline-delimited JSON content.
+type InvalidNdjson string
+
+func InvalidNdjsonErr(format string, args ...any) err.Err[InvalidNdjson] {
+	return err.Mk[InvalidNdjson](format, args...)
+}
+
+// A Server-Sent Event that can't be encoded.
+type InvalidEvent string
+
+func InvalidEventErr(format string, args ...any) err.Err[InvalidEvent] {
+	return err.Mk[InvalidEvent](format, args...)
+}
+
+// No codec to encode or decode a body of some media type.
+type NoCodec string
+
+func NoCodecErr(format string, args ...any) err.Err[NoCodec] {
+	return err.Mk[NoCodec](format, args...)
+}
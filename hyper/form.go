@@ -0,0 +1,491 @@
+package hyper
+
+import (
+	"bytes"
+	"encoding"
+	"fmt"
+	"io"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/c0c0n3/resto/hyper/wire"
+	"github.com/c0c0n3/resto/mime"
+	"github.com/c0c0n3/resto/util/bytez"
+)
+
+// FormBody holds form data that needs to be (de-)serialized (from) to
+// an HTTP body octet stream of "application/x-www-form-urlencoded"
+// content.
+//
+// Data can be a url.Values, a map[string]string or a struct---or a
+// pointer to any of those. To deserialize, Data must be a pointer.
+// Struct fields map to form fields as follows.
+//
+//   - The form field name is the one in the field's "form" tag or the
+//     struct field name if there's no tag. A tag of "-" skips the field,
+//     whereas a tag option of "omitempty" skips it if it has a zero value,
+//     e.g. `form:"page,omitempty"`.
+//   - Strings, booleans, numbers and any type implementing
+//     encoding.TextMarshaler, e.g. time.Time, become a single form field.
+//   - Slices and arrays of those become a repeated form field, e.g.
+//     tags=a&tags=b.
+//   - Nested structs add their fields under the parent field name with
+//     a dot, e.g. address.city=Cape+Town.
+//   - Slices and arrays of structs index each struct with brackets, e.g.
+//     items[0].sku=x&items[1].sku=y. When decoding, an index can't go
+//     above 9999 or the number of form keys.
+//   - Nil pointers get skipped, embedded structs get flattened and
+//     unexported fields get ignored.
+//
+// Example.
+//
+//     type Signup struct {
+//         Email   string   `form:"email"`
+//         Topics  []string `form:"topic"`
+//         Address struct {
+//             City string `form:"city"`
+//         } `form:"address"`
+//     }
+//     form := &FormBody{Data: signup}
+//     // email=x%40y.z&topic=go&topic=http&address.city=Cape+Town
+//
+type FormBody struct {
+	Data any
+}
+
+func (p *FormBody) Streaming() bool {
+	return false
+}
+
+func (p *FormBody) Serialize() (io.ReadCloser, int, error) {
+	values, err := encodeForm(p.Data)
+	if err != nil {
+		return nil, 0, err
+	}
+	buf := []byte(values.Encode())
+	return bytez.Reader(buf), len(buf), nil
+}
+
+// WriteHeaders writes a "Content-Type" header of
+// "application/x-www-form-urlencoded".
+func (p *FormBody) WriteHeaders(msg wire.MessageWriter) error {
+	return WriteContentType(msg, mime.URL_ENCODED)
+}
+
+// Deserialize reads the form into Data. The body can't be bigger than
+// 10MB, the same limit the http package puts on form bodies, nor have
+// more than 10000 fields.
+func (p *FormBody) Deserialize(reader io.ReadCloser) error {
+	body := io.LimitReader(ensureReader(reader), maxFormSize+1)
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	if len(data) > maxFormSize {
+		return InvalidFormErr("form body bigger than %d bytes", maxFormSize)
+	}
+	if bytes.Count(data, []byte("&"))+1 > maxFormFields {
+		return InvalidFormErr("more than %d form fields", maxFormFields)
+	}
+	values, err := url.ParseQuery(string(data))
+	if err != nil {
+		return InvalidFormErr("%v", err)
+	}
+	return decodeForm(values, p.Data)
+}
+
+const (
+	// maxFormSize is the most form data Deserialize reads in.
+	maxFormSize = 10 << 20
+	// maxFormFields is the most key-value pairs Deserialize accepts.
+	maxFormFields = 10000
+	// maxFormIndex is the highest list index a form key can have, e.g.
+	// items[9999].sku. Since the client picks the indices, we can't let
+	// them size the slices we decode into.
+	maxFormIndex = 9999
+)
+
+var (
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+type formField struct {
+	name      string
+	omitEmpty bool
+	index     []int
+}
+
+func formFields(t reflect.Type) []formField {
+	fields := []formField{}
+	for k := 0; k < t.NumField(); k++ {
+		f := t.Field(k)
+		tag, hasTag := f.Tag.Lookup("form")
+		if tag == "-" {
+			continue
+		}
+		if f.Anonymous && !hasTag && f.Type.Kind() == reflect.Struct {
+			for _, embedded := range formFields(f.Type) {
+				embedded.index = append([]int{k}, embedded.index...)
+				fields = append(fields, embedded)
+			}
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = f.Name
+		}
+		fields = append(fields, formField{
+			name:      name,
+			omitEmpty: options == "omitempty",
+			index:     []int{k},
+		})
+	}
+	return fields
+}
+
+func joinKey(prefix string, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
+
+func indexKey(prefix string, k int) string {
+	return fmt.Sprintf("%s[%d]", prefix, k)
+}
+
+func derefType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
+
+func isFormScalar(t reflect.Type) bool {
+	if t.Implements(textMarshalerType) ||
+		reflect.PointerTo(t).Implements(textMarshalerType) {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	case reflect.Slice:
+		return t.Elem().Kind() == reflect.Uint8 // []byte
+	}
+	return false
+}
+
+func encodeForm(data any) (url.Values, error) {
+	switch d := data.(type) {
+	case url.Values:
+		return d, nil
+	case *url.Values:
+		return *d, nil
+	case map[string]string:
+		values := url.Values{}
+		for key, value := range d {
+			values.Set(key, value)
+		}
+		return values, nil
+	}
+
+	values := url.Values{}
+	v := reflect.ValueOf(data)
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return values, nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil, InvalidFormErr("can't encode a %T as a form", data)
+	}
+	return values, encodeStruct(values, "", v)
+}
+
+func encodeStruct(values url.Values, prefix string, v reflect.Value) error {
+	for _, f := range formFields(v.Type()) {
+		field := v.FieldByIndex(f.index)
+		if f.omitEmpty && field.IsZero() {
+			continue
+		}
+		if err := encodeValue(values, joinKey(prefix, f.name), field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func encodeValue(values url.Values, key string, v reflect.Value) error {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if isFormScalar(v.Type()) {
+		text, err := scalarToText(v)
+		if err != nil {
+			return InvalidFormErr("%s: %v", key, err)
+		}
+		values.Add(key, text)
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		return encodeStruct(values, key, v)
+	case reflect.Slice, reflect.Array:
+		repeat := isFormScalar(derefType(v.Type().Elem()))
+		for k := 0; k < v.Len(); k++ {
+			elemKey := key
+			if !repeat {
+				elemKey = indexKey(key, k)
+			}
+			if err := encodeValue(values, elemKey, v.Index(k)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return InvalidFormErr("%s: can't encode a %s", key, v.Type())
+}
+
+func scalarToText(v reflect.Value) (string, error) {
+	if v.Type().Implements(textMarshalerType) {
+		text, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+		return string(text), err
+	}
+	if reflect.PointerTo(v.Type()).Implements(textMarshalerType) {
+		ptr := reflect.New(v.Type())
+		ptr.Elem().Set(v)
+		text, err := ptr.Interface().(encoding.TextMarshaler).MarshalText()
+		return string(text), err
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits()), nil
+	}
+	return string(v.Bytes()), nil // []byte, see isFormScalar
+}
+
+func decodeForm(values url.Values, target any) error {
+	switch t := target.(type) {
+	case *url.Values:
+		*t = values
+		return nil
+	case url.Values:
+		for key, vs := range values {
+			t[key] = vs
+		}
+		return nil
+	case map[string]string:
+		for key := range values {
+			t[key] = values.Get(key)
+		}
+		return nil
+	case *map[string]string:
+		*t = map[string]string{}
+		return decodeForm(values, *t)
+	}
+
+	v := reflect.ValueOf(target)
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		return InvalidFormErr("can't decode a form into a %T", target)
+	}
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return InvalidFormErr("can't decode a form into a %T", target)
+	}
+	return decodeStruct(indexFormKeys(values), "", v)
+}
+
+// formKeys indexes the keys of a form so we can tell which fields are
+// there without going through all the keys each time.
+type formKeys struct {
+	values url.Values
+	// Each key up to, but not including, any "." or "[" in it, e.g.
+	// "items" and "items[0]" for "items[0].sku".
+	prefixes map[string]bool
+	// One more than the highest index under each list key, e.g. 2 for
+	// "items" if there's "items[1].sku". Indices too big for an int
+	// are left out.
+	counts map[string]int
+}
+
+func indexFormKeys(values url.Values) *formKeys {
+	keys := &formKeys{
+		values:   values,
+		prefixes: map[string]bool{},
+		counts:   map[string]int{},
+	}
+	for k := range values {
+		for i := 0; i < len(k); i++ {
+			if k[i] != '.' && k[i] != '[' {
+				continue
+			}
+			keys.prefixes[k[:i]] = true
+			if k[i] != '[' {
+				continue
+			}
+			end := strings.IndexByte(k[i+1:], ']')
+			if end < 0 {
+				continue
+			}
+			n, err := strconv.Atoi(k[i+1 : i+1+end])
+			if err == nil && n >= keys.counts[k[:i]] {
+				keys.counts[k[:i]] = n + 1
+			}
+		}
+	}
+	return keys
+}
+
+// has tells if there's any form field for the given key, either the
+// key itself or any nested or indexed field under it.
+func (p *formKeys) has(key string) bool {
+	if _, ok := p.values[key]; ok {
+		return true
+	}
+	return p.prefixes[key]
+}
+
+// indexCount returns one more than the highest index under the given
+// key, i.e. the length of the slice to decode. An index can't be higher
+// than maxFormIndex or the number of form keys, since each list element
+// needs a key of its own.
+func (p *formKeys) indexCount(key string) (int, error) {
+	count := p.counts[key]
+	if n := count - 1; n > maxFormIndex || n >= len(p.values) {
+		return 0, InvalidFormErr("%s: index out of range: %d", key, n)
+	}
+	return count, nil
+}
+
+func decodeStruct(keys *formKeys, prefix string, v reflect.Value) error {
+	for _, f := range formFields(v.Type()) {
+		key := joinKey(prefix, f.name)
+		if err := decodeValue(keys, key, v.FieldByIndex(f.index)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func decodeValue(keys *formKeys, key string, v reflect.Value) error {
+	if !keys.has(key) {
+		return nil
+	}
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return decodeValue(keys, key, v.Elem())
+	}
+	if isFormScalar(v.Type()) {
+		return textToScalar(key, keys.values.Get(key), v)
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		return decodeStruct(keys, key, v)
+	case reflect.Slice, reflect.Array:
+		return decodeList(keys, key, v)
+	}
+	return InvalidFormErr("%s: can't decode a %s", key, v.Type())
+}
+
+func decodeList(keys *formKeys, key string, v reflect.Value) error {
+	repeat := isFormScalar(derefType(v.Type().Elem()))
+	n := len(keys.values[key])
+	if !repeat {
+		count, err := keys.indexCount(key)
+		if err != nil {
+			return err
+		}
+		n = count
+	}
+	if v.Kind() == reflect.Slice {
+		v.Set(reflect.MakeSlice(v.Type(), n, n))
+	} else if n > v.Len() {
+		n = v.Len()
+	}
+
+	for k := 0; k < n; k++ {
+		elem := v.Index(k)
+		if !repeat {
+			if err := decodeValue(keys, indexKey(key, k), elem); err != nil {
+				return err
+			}
+			continue
+		}
+		if elem.Kind() == reflect.Pointer {
+			elem.Set(reflect.New(elem.Type().Elem()))
+			elem = elem.Elem()
+		}
+		if err := textToScalar(key, keys.values[key][k], elem); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func textToScalar(key string, text string, v reflect.Value) error {
+	if v.CanAddr() && v.Addr().Type().Implements(textUnmarshalerType) {
+		err := v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(text))
+		if err != nil {
+			return InvalidFormErr("%s: %v", key, err)
+		}
+		return nil
+	}
+
+	var err error
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(text)
+	case reflect.Bool:
+		var b bool
+		b, err = strconv.ParseBool(text)
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var n int64
+		n, err = strconv.ParseInt(text, 10, v.Type().Bits())
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var n uint64
+		n, err = strconv.ParseUint(text, 10, v.Type().Bits())
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		var x float64
+		x, err = strconv.ParseFloat(text, v.Type().Bits())
+		v.SetFloat(x)
+	case reflect.Slice:
+		v.SetBytes([]byte(text))
+	default:
+		err = fmt.Errorf("can't decode a %s", v.Type())
+	}
+	if err != nil {
+		return InvalidFormErr("%s: %v", key, err)
+	}
+	return nil
+}
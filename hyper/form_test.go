@@ -0,0 +1,230 @@
+package hyper
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/c0c0n3/resto/util/bytez"
+	e "github.com/c0c0n3/resto/util/err"
+)
+
+type formAddress struct {
+	City string `form:"city"`
+	Zip  *int   `form:"zip"`
+}
+
+type formItem struct {
+	Sku string `form:"sku"`
+	Qty uint8  `form:"qty"`
+}
+
+type formAudit struct {
+	Created time.Time `form:"created"`
+}
+
+type formOrder struct {
+	formAudit
+	Email    string       `form:"email"`
+	Topics   []string     `form:"topic"`
+	Scores   [2]float64   `form:"score"`
+	Address  formAddress  `form:"address"`
+	Billing  *formAddress `form:"billing"`
+	Items    []formItem   `form:"items"`
+	Page     int          `form:"page,omitempty"`
+	Gift     bool
+	Secret   string `form:"-"`
+	internal string
+}
+
+func sampleOrder() formOrder {
+	zip := 8001
+	return formOrder{
+		formAudit: formAudit{time.Date(2022, 5, 1, 10, 0, 0, 0, time.UTC)},
+		Email:     "x@y.z",
+		Topics:    []string{"go", "http"},
+		Scores:    [2]float64{1.5, 2},
+		Address:   formAddress{City: "Cape Town", Zip: &zip},
+		Items:     []formItem{{"a", 1}, {"b", 2}},
+		Gift:      true,
+		Secret:    "shh",
+		internal:  "x",
+	}
+}
+
+func TestEncodeFormStruct(t *testing.T) {
+	order := sampleOrder()
+	got, err := encodeForm(&order)
+	if err != nil {
+		t.Fatalf("want: values; got: %v", err)
+	}
+	want := url.Values{
+		"created":      {"2022-05-01T10:00:00Z"},
+		"email":        {"x@y.z"},
+		"topic":        {"go", "http"},
+		"score":        {"1.5", "2"},
+		"address.city": {"Cape Town"},
+		"address.zip":  {"8001"},
+		"items[0].sku": {"a"},
+		"items[0].qty": {"1"},
+		"items[1].sku": {"b"},
+		"items[1].qty": {"2"},
+		"Gift":         {"true"},
+	}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("want: %v; got: %v", want, got)
+	}
+}
+
+func TestFormRoundTrip(t *testing.T) {
+	order := sampleOrder()
+	body, _, err := (&FormBody{Data: order}).Serialize()
+	if err != nil {
+		t.Fatalf("want: body; got: %v", err)
+	}
+
+	decoded := formOrder{}
+	if err := (&FormBody{Data: &decoded}).Deserialize(body); err != nil {
+		t.Fatalf("want: decoded order; got: %v", err)
+	}
+	order.Secret, order.internal = "", ""
+	if !reflect.DeepEqual(order, decoded) {
+		t.Errorf("want: %+v; got: %+v", order, decoded)
+	}
+}
+
+func TestFormMaps(t *testing.T) {
+	writer := newMsgWriter(t)
+	err := WriteBody(writer, &FormBody{Data: map[string]string{"a": "1 2"}})
+	if err != nil {
+		t.Fatalf("want: body; got: %v", err)
+	}
+	writer.assertHeader("Content-Type", "application/x-www-form-urlencoded")
+	writer.assertHeader("Content-Length", "5")
+	if got := writer.stringBody(); got != "a=1+2" {
+		t.Errorf("want: a=1+2; got: %s", got)
+	}
+
+	values := url.Values{}
+	(&FormBody{Data: &values}).Deserialize(readerOf("a=1&a=2"))
+	if got := values["a"]; len(got) != 2 {
+		t.Errorf("want: a=1&a=2; got: %v", values)
+	}
+
+	fields := map[string]string{}
+	(&FormBody{Data: &fields}).Deserialize(readerOf("a=1&b=2"))
+	if fields["a"] != "1" || fields["b"] != "2" {
+		t.Errorf("want: a=1, b=2; got: %v", fields)
+	}
+}
+
+func readerOf(s string) io.ReadCloser {
+	return bytez.Reader([]byte(s))
+}
+
+func TestFormErrors(t *testing.T) {
+	isInvalidForm := func(err error) bool {
+		_, ok := err.(e.Err[InvalidForm])
+		return ok
+	}
+
+	if _, _, err := (&FormBody{Data: 42}).Serialize(); !isInvalidForm(err) {
+		t.Errorf("want: can't encode int; got: %v", err)
+	}
+	type withMap struct{ M map[string]int }
+	if _, _, err := (&FormBody{Data: withMap{M: map[string]int{}}}).Serialize(); !isInvalidForm(err) {
+		t.Errorf("want: can't encode map; got: %v", err)
+	}
+
+	order := formOrder{}
+	for _, body := range []string{"page=x", "items[0].qty=300", "created=yesterday", "%zz"} {
+		err := (&FormBody{Data: &order}).Deserialize(readerOf(body))
+		if !isInvalidForm(err) {
+			t.Errorf("[%s] want: invalid form; got: %v", body, err)
+		}
+	}
+	if err := (&FormBody{Data: order}).Deserialize(readerOf("a=1")); !isInvalidForm(err) {
+		t.Errorf("want: can't decode into non-pointer; got: %v", err)
+	}
+}
+
+func TestDecodeFormIndexOutOfRange(t *testing.T) {
+	bodies := []string{
+		"items[9223372036854775806].sku=x",
+		"items[100000000].sku=x",
+		"items[0].sku=x&items[5].sku=y",
+	}
+	for _, body := range bodies {
+		order := formOrder{}
+		err := (&FormBody{Data: &order}).Deserialize(readerOf(body))
+		if _, ok := err.(e.Err[InvalidForm]); !ok {
+			t.Errorf("[%s] want: invalid form; got: %v", body, err)
+		}
+	}
+
+	order := formOrder{}
+	body := "items[1].sku=y&items[0].sku=x"
+	if err := (&FormBody{Data: &order}).Deserialize(readerOf(body)); err != nil {
+		t.Fatalf("want: items; got: %v", err)
+	}
+	if len(order.Items) != 2 || order.Items[1].Sku != "y" {
+		t.Errorf("want: x, y; got: %v", order.Items)
+	}
+}
+
+func TestDecodeFormTooBig(t *testing.T) {
+	body := "q=" + strings.Repeat("x", maxFormSize)
+	values := url.Values{}
+	err := (&FormBody{Data: &values}).Deserialize(readerOf(body))
+	if _, ok := err.(e.Err[InvalidForm]); !ok {
+		t.Errorf("want: invalid form; got: %v", err)
+	}
+}
+
+func formWithDummyKeys(n int, tail string) string {
+	var body strings.Builder
+	for k := 0; k < n; k++ {
+		fmt.Fprintf(&body, "k%d=1&", k)
+	}
+	body.WriteString(tail)
+	return body.String()
+}
+
+func TestDecodeFormManyKeys(t *testing.T) {
+	var target struct {
+		Items []struct{ A, B, C string }
+	}
+	body := formWithDummyKeys(maxFormFields-1, "Items[9998].A=1")
+	start := time.Now()
+	err := (&FormBody{Data: &target}).Deserialize(readerOf(body))
+	elapsed := time.Since(start)
+
+	if err != nil {
+		t.Fatalf("want: items; got: %v", err)
+	}
+	if len(target.Items) != 9999 || target.Items[9998].A != "1" {
+		t.Errorf("want: 9999 items; got: %d", len(target.Items))
+	}
+	if elapsed > 2*time.Second {
+		t.Errorf("want: decoded in under 2s; took: %v", elapsed)
+	}
+
+	body = formWithDummyKeys(200000, "Items[9999].A=1")
+	err = (&FormBody{Data: &target}).Deserialize(readerOf(body))
+	if _, ok := err.(e.Err[InvalidForm]); !ok {
+		t.Errorf("want: too many fields; got: %v", err)
+	}
+}
+
+func TestEncodeNilForm(t *testing.T) {
+	var order *formOrder
+	body, size, err := (&FormBody{Data: order}).Serialize()
+	if err != nil || size != 0 {
+		t.Errorf("want: empty form; got: %v, %d", err, size)
+	}
+	body.Close()
+}
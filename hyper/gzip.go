@@ -0,0 +1,76 @@
+package hyper
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+
+	"github.com/c0c0n3/resto/hyper/wire"
+	"github.com/c0c0n3/resto/util/bytez"
+)
+
+// GzipBody compresses the body another BodySerializer produces and
+// writes a "Content-Encoding: gzip" header along with it.
+//
+// If the Content serializer streams its body, so does GzipBody: the
+// body gets compressed on the fly as it goes out, in constant space,
+// and there's no "Content-Length" header. Otherwise GzipBody compresses
+// the whole body in memory, so the "Content-Length" header WriteBody
+// writes has the size of the compressed body.
+type GzipBody struct {
+	Content BodySerializer
+}
+
+func (p *GzipBody) Streaming() bool {
+	return p.Content != nil && p.Content.Streaming()
+}
+
+func (p *GzipBody) Serialize() (io.ReadCloser, int, error) {
+	if p.Content == nil {
+		return nil, 0, NilBodySerializerErr()
+	}
+	content, _, err := p.Content.Serialize()
+	if err != nil {
+		return nil, 0, err
+	}
+	content = ensureReader(content)
+
+	if p.Content.Streaming() {
+		pr, pw := io.Pipe()
+		write := func() {
+			defer content.Close()
+			zipper := gzip.NewWriter(pw)
+			if _, err := io.Copy(zipper, content); err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+			pw.CloseWithError(zipper.Close())
+		}
+		unstarted := func() { content.Close() }
+		return &lazyPipe{
+			reader: pr, start: write, onUnstartedClose: unstarted,
+		}, 0, nil
+	}
+
+	defer content.Close()
+	buf := &bytes.Buffer{}
+	zipper := gzip.NewWriter(buf)
+	if _, err := io.Copy(zipper, content); err != nil {
+		return nil, 0, err
+	}
+	if err := zipper.Close(); err != nil {
+		return nil, 0, err
+	}
+	return bytez.NewBufferFrom(buf.Bytes()), buf.Len(), nil
+}
+
+// WriteHeaders writes a "Content-Encoding: gzip" header as well as any
+// headers the Content serializer writes, e.g. "Content-Type".
+func (p *GzipBody) WriteHeaders(msg wire.MessageWriter) error {
+	if headers, ok := p.Content.(BodyHeaders); ok {
+		if err := headers.WriteHeaders(msg); err != nil {
+			return err
+		}
+	}
+	return msg.Header("Content-Encoding", "gzip")
+}
@@ -0,0 +1,117 @@
+package hyper
+
+import (
+	"compress/gzip"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/c0c0n3/resto/mime"
+)
+
+func gunzip(t *testing.T, body io.Reader) string {
+	reader, err := gzip.NewReader(body)
+	if err != nil {
+		t.Fatalf("want: gzip body; got: %v", err)
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("want: gzip body; got: %v", err)
+	}
+	return string(data)
+}
+
+func TestWriteBufferedGzipBody(t *testing.T) {
+	msg := newMsgWriter(t)
+	content := &GzipBody{Content: &StringBody{Data: "howzit!"}}
+	if err := WriteBody(msg, content); err != nil {
+		t.Fatalf("want: write; got: %v", err)
+	}
+
+	msg.assertHeader("Content-Encoding", "gzip")
+	compressed := msg.stringBody()
+	msg.assertHeader("Content-Length", strconv.Itoa(len(compressed)))
+	if got := gunzip(t, strings.NewReader(compressed)); got != "howzit!" {
+		t.Errorf("want: howzit!; got: %s", got)
+	}
+}
+
+func TestWriteStreamingGzipBody(t *testing.T) {
+	msg := newMsgWriter(t)
+	data := strings.Repeat("howzit! ", 10000)
+	content := &GzipBody{
+		Content: &StreamingBody{Data: io.NopCloser(strings.NewReader(data))},
+	}
+	if !content.Streaming() {
+		t.Errorf("want: streaming; got: buffered")
+	}
+	if err := WriteBody(msg, content); err != nil {
+		t.Fatalf("want: write; got: %v", err)
+	}
+
+	msg.assertHeader("Content-Encoding", "gzip")
+	msg.assertNoHeader("Content-Length")
+	if got := gunzip(t, strings.NewReader(msg.stringBody())); got != data {
+		t.Errorf("want: %d bytes; got: %d", len(data), len(got))
+	}
+}
+
+type closeTracker struct {
+	io.Reader
+	closed bool
+}
+
+func (p *closeTracker) Close() error {
+	p.closed = true
+	return nil
+}
+
+func TestStreamingGzipBodyClosesUnreadContent(t *testing.T) {
+	source := &closeTracker{Reader: strings.NewReader("howzit!")}
+	content := &GzipBody{Content: &StreamingBody{Data: source}}
+	body, _, err := content.Serialize()
+	if err != nil {
+		t.Fatalf("want: body; got: %v", err)
+	}
+	body.Close()
+
+	if !source.closed {
+		t.Errorf("want: content closed; got: still open")
+	}
+	if _, err := body.Read(make([]byte, 1)); err == nil {
+		t.Errorf("want: read error after close; got: nil")
+	}
+}
+
+func TestGzipBodyKeepsContentHeaders(t *testing.T) {
+	msg := newMsgWriter(t)
+	content := &GzipBody{Content: &FormBody{Data: map[string]string{"a": "1"}}}
+	if err := WriteBody(msg, content); err != nil {
+		t.Fatalf("want: write; got: %v", err)
+	}
+
+	msg.assertHeader("Content-Type", mime.URL_ENCODED.String())
+	msg.assertHeader("Content-Encoding", "gzip")
+	if got := gunzip(t, strings.NewReader(msg.stringBody())); got != "a=1" {
+		t.Errorf("want: a=1; got: %s", got)
+	}
+}
+
+func TestNilGzipBodyContent(t *testing.T) {
+	content := &GzipBody{}
+	if _, _, err := content.Serialize(); err == nil {
+		t.Errorf("want: nil serializer error; got: nil")
+	}
+}
+
+func TestGzipBodyEmptyContent(t *testing.T) {
+	content := &GzipBody{Content: &ByteBody{}}
+	reader, size, err := content.Serialize()
+	if err != nil || size == 0 {
+		t.Fatalf("want: gzip stream; got: %d, %v", size, err)
+	}
+	if got := gunzip(t, reader); got != "" {
+		t.Errorf("want: empty; got: %s", got)
+	}
+}
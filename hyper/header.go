@@ -2,19 +2,76 @@ package hyper
 
 import (
 	"fmt"
-	"strings"
 
 	"github.com/c0c0n3/resto/hyper/wire"
 	"github.com/c0c0n3/resto/mime"
 )
 
-// Write a "Content-Type" header with the specified MIME type.
+// Write a header with the specified values, replacing any values the
+// header might already have. Each value gets written as a separate
+// header line. If there are no values, nothing gets written.
+func WriteHeader(msg wire.MessageWriter, name string, values ...string) error {
+	if msg == nil {
+		return NilMessageWriterErr()
+	}
+	for k, v := range values {
+		write := msg.AddHeader
+		if k == 0 {
+			write = msg.Header
+		}
+		if err := write(name, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Add the specified values to a header, keeping any values the header
+// might already have. Each value gets written as a separate header line.
+func AddHeader(msg wire.MessageWriter, name string, values ...string) error {
+	if msg == nil {
+		return NilMessageWriterErr()
+	}
+	for _, v := range values {
+		if err := msg.AddHeader(name, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Write a "Content-Type" header with the specified MIME type. The MIME
+// type can have parameters, e.g.
+//
+//     WriteContentType(msg, mime.JSON.WithParam("charset", "utf-8"))
+//
+// WriteContentType returns an error if the MIME type isn't valid or has
+// wildcards.
 func WriteContentType(msg wire.MessageWriter, mediaType mime.MediaType) error {
 	if msg == nil {
 		return NilMessageWriterErr()
 	}
-	return msg.Header("Content-Type", mediaType.String())
-	// TODO implement charset & friends?
+	parsed, err := mediaType.Parse()
+	if err != nil {
+		return err
+	}
+	if parsed.IsWildcard() {
+		return InvalidHeaderErr("wildcard content type: %s", mediaType)
+	}
+	return msg.Header("Content-Type", parsed.String())
+}
+
+// Read the "Content-Type" header of the given message. ReadContentType
+// returns an error if the header is missing or isn't a valid MIME type.
+func ReadContentType(msg wire.MessageReader) (mime.ParsedType, error) {
+	if msg == nil {
+		return mime.ParsedType{}, NilMessageReaderErr()
+	}
+	header := msg.Header("Content-Type")
+	if header == "" {
+		return mime.ParsedType{}, InvalidHeaderErr("no content type")
+	}
+	return mime.Parse(header)
 }
 
 // Write a "Content-Length" header with the specified body size.
@@ -26,20 +83,14 @@ func WriteContentLength(msg wire.MessageWriter, bodySize uint64) error {
 	return msg.Header("Content-Length", sz)
 }
 
-// Write an "Accept" header with the specified MIME types.
+// Write an "Accept" header with the specified MIME types, all with the
+// same weight. Use WriteWeightedAccept to give each type its own weight.
 func WriteAccept(msg wire.MessageWriter, mediaType ...mime.MediaType) error {
-	if msg == nil {
-		return NilMessageWriterErr()
-	}
-	ts := []string{}
+	ranges := []MediaRange{}
 	for _, mt := range mediaType {
-		ts = append(ts, mt.String())
-	}
-	if len(ts) > 0 {
-		return msg.Header("Accept", strings.Join(ts, ", "))
+		ranges = append(ranges, Weighted(mt, 1))
 	}
-	return nil
-	// TODO implement weights too?
+	return WriteWeightedAccept(msg, ranges...)
 }
 
 // Write an "Authorization" header with the specified value.
@@ -20,6 +20,48 @@ func TestWriteContentTypeNilWriter(t *testing.T) {
 	}
 }
 
+func TestWriteContentTypeWithParams(t *testing.T) {
+	msg := newMsgWriter(t)
+	WriteContentType(msg, "Text/Plain;Charset=UTF-8")
+	msg.assertHeader("Content-Type", "text/plain; charset=UTF-8")
+}
+
+func TestWriteContentTypeInvalid(t *testing.T) {
+	msg := newMsgWriter(t)
+	err := WriteContentType(msg, "json")
+	if _, ok := err.(e.Err[mime.InvalidMediaType]); !ok {
+		t.Errorf("want: invalid media type err; got: %v", err)
+	}
+	err = WriteContentType(msg, "application/*")
+	if _, ok := err.(e.Err[InvalidHeader]); !ok {
+		t.Errorf("want: invalid header err; got: %v", err)
+	}
+	msg.assertNoHeader("Content-Type")
+}
+
+func TestReadContentType(t *testing.T) {
+	msg := newMsgReader()
+	msg.headers["Content-Type"] = "application/problem+json"
+	got, err := ReadContentType(msg)
+	if err != nil {
+		t.Fatalf("want: content type; got: %v", err)
+	}
+	if got.Suffix != "json" {
+		t.Errorf("want: json suffix; got: %v", got)
+	}
+}
+
+func TestReadContentTypeMissing(t *testing.T) {
+	_, err := ReadContentType(newMsgReader())
+	if _, ok := err.(e.Err[InvalidHeader]); !ok {
+		t.Errorf("want: invalid header err; got: %v", err)
+	}
+	_, err = ReadContentType(nil)
+	if _, ok := err.(e.Err[NilPtr]); !ok {
+		t.Errorf("want: nil ptr err; got: %v", err)
+	}
+}
+
 func TestWriteContentLength(t *testing.T) {
 	msg := newMsgWriter(t)
 	WriteContentLength(msg, 42)
@@ -105,3 +147,45 @@ func TestWriteBearerToken(t *testing.T) {
 	WriteBearerToken(msg, provider)
 	msg.assertHeader("Authorization", "Bearer foo bar")
 }
+
+func TestWriteHeaderReplacesValues(t *testing.T) {
+	msg := newMsgWriter(t)
+	WriteHeader(msg, "Via", "old")
+	WriteHeader(msg, "Via", "1.1 alpha", "1.1 beta")
+	msg.assertHeader("Via", "1.1 alpha, 1.1 beta")
+}
+
+func TestWriteHeaderWithNoValues(t *testing.T) {
+	msg := newMsgWriter(t)
+	WriteHeader(msg, "Via")
+	msg.assertNoHeader("Via")
+}
+
+func TestAddHeaderKeepsValues(t *testing.T) {
+	msg := newMsgWriter(t)
+	WriteHeader(msg, "Via", "1.1 alpha")
+	AddHeader(msg, "Via", "1.1 beta", "1.1 gamma")
+	msg.assertHeader("Via", "1.1 alpha, 1.1 beta, 1.1 gamma")
+}
+
+func TestWriteHeaderNilWriter(t *testing.T) {
+	if _, ok := WriteHeader(nil, "Via", "x").(e.Err[NilPtr]); !ok {
+		t.Errorf("want: nil ptr err")
+	}
+	if _, ok := AddHeader(nil, "Via", "x").(e.Err[NilPtr]); !ok {
+		t.Errorf("want: nil ptr err")
+	}
+}
+
+func TestWriteHeaderError(t *testing.T) {
+	msg := newMsgWriter(t)
+	msg.headerWritingErr = UnexpectedResponseErr("boom")
+	err := WriteHeader(msg, "Via", "x", "y")
+	if _, ok := err.(e.Err[UnexpectedResponse]); !ok {
+		t.Errorf("want: unexpected response err; got: %v", err)
+	}
+	err = AddHeader(msg, "Via", "x", "y")
+	if _, ok := err.(e.Err[UnexpectedResponse]); !ok {
+		t.Errorf("want: unexpected response err; got: %v", err)
+	}
+}
@@ -0,0 +1,198 @@
+package hyper
+
+import (
+	"strings"
+
+	"github.com/c0c0n3/resto/hyper/wire"
+)
+
+// Link is a web link as found in an RFC 8288 "Link" header, e.g.
+//
+//     <https://my.api/data?page=2>; rel="next"; title="Next page"
+//
+type Link struct {
+	// The link target URI reference exactly as it appears in the header,
+	// i.e. it could be relative.
+	Target string
+	// The link relation types, lowercase. A link can have more than one,
+	// e.g. rel="next last".
+	Rel []string
+	// Any other link parameters keyed by lowercase name, e.g. "title",
+	// "type" or "anchor". Parameters with no value map to an empty string.
+	Params map[string]string
+}
+
+// HasRel tells if the link has the given relation type. Relation types
+// are case-insensitive.
+func (l Link) HasRel(rel string) bool {
+	for _, r := range l.Rel {
+		if strings.EqualFold(r, rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// FindLink returns the first link with the given relation type, if
+// there's one.
+func FindLink(links []Link, rel string) (Link, bool) {
+	for _, link := range links {
+		if link.HasRel(rel) {
+			return link, true
+		}
+	}
+	return Link{}, false
+}
+
+// ReadLinks parses all the "Link" headers in the given message.
+func ReadLinks(msg wire.MessageReader) ([]Link, error) {
+	if msg == nil {
+		return nil, NilMessageReaderErr()
+	}
+	links := []Link{}
+	for name, values := range msg.Headers() {
+		if !strings.EqualFold(name, "Link") {
+			continue
+		}
+		for _, value := range values {
+			parsed, err := ParseLinks(value)
+			if err != nil {
+				return nil, err
+			}
+			links = append(links, parsed...)
+		}
+	}
+	return links, nil
+}
+
+// ParseLinks parses the value of a "Link" header as specified by RFC
+// 8288, section 3. If a parameter occurs more than once in a link, only
+// the first occurrence counts.
+func ParseLinks(header string) ([]Link, error) {
+	p := &linkParser{input: header}
+	links := []Link{}
+	for {
+		p.skip(" \t,")
+		if p.done() {
+			return links, nil
+		}
+		link, err := p.link()
+		if err != nil {
+			return nil, err
+		}
+		links = append(links, link)
+	}
+}
+
+type linkParser struct {
+	input string
+	pos   int
+}
+
+func (p *linkParser) done() bool {
+	return p.pos >= len(p.input)
+}
+
+func (p *linkParser) peek() byte {
+	if p.done() {
+		return 0
+	}
+	return p.input[p.pos]
+}
+
+func (p *linkParser) skip(chars string) {
+	for !p.done() && strings.IndexByte(chars, p.peek()) >= 0 {
+		p.pos++
+	}
+}
+
+func (p *linkParser) fail(reason string) error {
+	return InvalidHeaderErr("Link: %s at %d: %s", reason, p.pos, p.input)
+}
+
+func (p *linkParser) link() (Link, error) {
+	if p.peek() != '<' {
+		return Link{}, p.fail("expected '<'")
+	}
+	end := strings.IndexByte(p.input[p.pos:], '>')
+	if end < 0 {
+		return Link{}, p.fail("missing '>'")
+	}
+	link := Link{
+		Target: strings.TrimSpace(p.input[p.pos+1 : p.pos+end]),
+		Rel:    []string{},
+		Params: map[string]string{},
+	}
+	p.pos += end + 1
+
+	seen := map[string]bool{}
+	for {
+		p.skip(" \t")
+		if p.done() || p.peek() == ',' {
+			return link, nil
+		}
+		if p.peek() != ';' {
+			return Link{}, p.fail("expected ';'")
+		}
+		p.pos++
+		name, value, err := p.param()
+		if err != nil {
+			return Link{}, err
+		}
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		if name == "rel" {
+			link.Rel = strings.Fields(strings.ToLower(value))
+		} else {
+			link.Params[name] = value
+		}
+	}
+}
+
+func (p *linkParser) param() (string, string, error) {
+	p.skip(" \t")
+	start := p.pos
+	for !p.done() && strings.IndexByte("=;, \t", p.peek()) < 0 {
+		p.pos++
+	}
+	name := strings.ToLower(p.input[start:p.pos])
+	p.skip(" \t")
+	if p.peek() != '=' {
+		return name, "", nil
+	}
+	p.pos++
+	p.skip(" \t")
+	if p.peek() == '"' {
+		value, err := p.quoted()
+		return name, value, err
+	}
+	start = p.pos
+	for !p.done() && strings.IndexByte(";, \t", p.peek()) < 0 {
+		p.pos++
+	}
+	return name, p.input[start:p.pos], nil
+}
+
+func (p *linkParser) quoted() (string, error) {
+	var value strings.Builder
+	p.pos++ // opening quote
+	for !p.done() {
+		c := p.peek()
+		p.pos++
+		switch c {
+		case '"':
+			return value.String(), nil
+		case '\\':
+			if p.done() {
+				return "", p.fail("unterminated escape")
+			}
+			value.WriteByte(p.peek())
+			p.pos++
+		default:
+			value.WriteByte(c)
+		}
+	}
+	return "", p.fail("unterminated quoted string")
+}
@@ -0,0 +1,87 @@
+package hyper
+
+import (
+	"reflect"
+	"testing"
+
+	e "github.com/c0c0n3/resto/util/err"
+)
+
+func TestParseLinks(t *testing.T) {
+	header := `<https://my.api/data?page=2>; rel="next last"; title="Page, \"two\"",` +
+		` </data?page=1> ;REL=Prev; rel=ignored; hreflang=en; crossorigin`
+	got, err := ParseLinks(header)
+	if err != nil {
+		t.Fatalf("want: links; got: %v", err)
+	}
+	want := []Link{
+		{
+			Target: "https://my.api/data?page=2",
+			Rel:    []string{"next", "last"},
+			Params: map[string]string{"title": `Page, "two"`},
+		},
+		{
+			Target: "/data?page=1",
+			Rel:    []string{"prev"},
+			Params: map[string]string{"hreflang": "en", "crossorigin": ""},
+		},
+	}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("want: %v; got: %v", want, got)
+	}
+}
+
+func TestParseEmptyLinks(t *testing.T) {
+	for _, header := range []string{"", " , "} {
+		got, err := ParseLinks(header)
+		if err != nil || len(got) != 0 {
+			t.Errorf("[%s] want: no links; got: %v, %v", header, got, err)
+		}
+	}
+}
+
+func TestParseInvalidLinks(t *testing.T) {
+	for _, header := range []string{
+		"https://no.brackets", "<https://no.end", `<x>; rel="open`,
+		"<x> rel=next", `<x>; title="\`,
+	} {
+		_, err := ParseLinks(header)
+		if _, ok := err.(e.Err[InvalidHeader]); !ok {
+			t.Errorf("[%s] want: invalid header err; got: %v", header, err)
+		}
+	}
+}
+
+type linkReader struct {
+	*msgReader
+	links []string
+}
+
+func (p *linkReader) Headers() map[string][]string {
+	return map[string][]string{"Link": p.links}
+}
+
+func TestReadAndFindLinks(t *testing.T) {
+	msg := &linkReader{
+		msgReader: newMsgReader(),
+		links:     []string{`</a>; rel="prev"`, `</b>; rel="NEXT"`},
+	}
+	links, err := ReadLinks(msg)
+	if err != nil {
+		t.Fatalf("want: links; got: %v", err)
+	}
+	next, ok := FindLink(links, "next")
+	if !ok || next.Target != "/b" {
+		t.Errorf("want: /b; got: %v", next)
+	}
+	if _, ok := FindLink(links, "last"); ok {
+		t.Errorf("want: no last link")
+	}
+}
+
+func TestReadLinksNilReader(t *testing.T) {
+	_, err := ReadLinks(nil)
+	if _, ok := err.(e.Err[NilPtr]); !ok {
+		t.Errorf("want: nil ptr err; got: %v", err)
+	}
+}
@@ -29,6 +29,17 @@ func (p *msgWriter) Header(name string, content string) error {
 	return nil
 }
 
+func (p *msgWriter) AddHeader(name string, content string) error {
+	if p.headerWritingErr != nil {
+		return p.headerWritingErr
+	}
+	if current, present := p.headers[name]; present {
+		content = current + ", " + content
+	}
+	p.headers[name] = content
+	return nil
+}
+
 func (p *msgWriter) Body(content io.ReadCloser) error {
 	data, err := io.ReadAll(content)
 	p.body = data
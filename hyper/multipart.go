@@ -0,0 +1,260 @@
+package hyper
+
+import (
+	"io"
+	"mime/multipart"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/c0c0n3/resto/hyper/wire"
+	"github.com/c0c0n3/resto/mime"
+)
+
+// FormPart is a part of a "multipart/form-data" body, i.e. either a
+// plain form field or a file.
+type FormPart struct {
+	// The name of the form field.
+	Name string
+	// The name of the file, empty for a plain form field.
+	FileName string
+	// The part content type. If empty, file parts get a content type of
+	// "application/octet-stream", whereas plain form fields get none.
+	ContentType mime.MediaType
+	// Where to read the part content from.
+	Content io.Reader
+	// The path of the file to read the part content from if Content is
+	// nil. The file only gets opened when its content has to go out on
+	// the wire and gets closed straight after.
+	Path string
+}
+
+// FormField builds a FormPart for a plain form field with the given
+// name and value.
+func FormField(name string, value string) FormPart {
+	return FormPart{Name: name, Content: strings.NewReader(value)}
+}
+
+// FormFile builds a FormPart for a file with the given field name and
+// file name, reading the file content from the given reader.
+func FormFile(name string, fileName string, content io.Reader) FormPart {
+	return FormPart{Name: name, FileName: fileName, Content: content}
+}
+
+// FormFilePath builds a FormPart for the file at the given path. The
+// part gets the file's base name for a file name.
+func FormFilePath(name string, path string) FormPart {
+	return FormPart{Name: name, FileName: filepath.Base(path), Path: path}
+}
+
+func (p FormPart) header() textproto.MIMEHeader {
+	disposition := `form-data; name="` + escapeQuotes(p.Name) + `"`
+	if p.FileName != "" {
+		disposition += `; filename="` + escapeQuotes(p.FileName) + `"`
+	}
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", disposition)
+
+	contentType := p.ContentType
+	if contentType == "" && p.FileName != "" {
+		contentType = mime.OCTET_STREAM
+	}
+	if contentType != "" {
+		header.Set("Content-Type", contentType.String())
+	}
+	return header
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+func escapeQuotes(s string) string {
+	return quoteEscaper.Replace(s)
+	// NOTE. Same escaping as the multipart package, which doesn't export
+	// it, see multipart.Writer.CreateFormFile.
+}
+
+func (p FormPart) writeTo(w *multipart.Writer) error {
+	content := p.Content
+	if content == nil {
+		if p.Path == "" {
+			return NilPartContentErr(p.Name)
+		}
+		file, err := os.Open(p.Path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		content = file
+	}
+	out, err := w.CreatePart(p.header())
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(out, content)
+	return err
+}
+
+// MultipartBody produces/consumes a "multipart/form-data" HTTP body
+// octet stream in constant space, no matter how big the parts are.
+//
+// To send a multipart body, list the parts to send in Parts. Then
+// WriteBody streams one part after the other and writes a "Content-Type"
+// header with the boundary between parts. Example.
+//
+//     body := &MultipartBody{
+//         Parts: []FormPart{
+//             FormField("title", "Q3 report"),
+//             FormFilePath("report", "/data/q3.pdf"),
+//         },
+//     }
+//     err := WriteBody(request, body)
+//
+// To read a multipart body, set Boundary to the boundary in the message
+// "Content-Type" header---or use ReadMultipart to do that for you. Then
+// Deserialize the body and call NextPart to go through the parts.
+type MultipartBody struct {
+	// The parts to send.
+	Parts []FormPart
+	// The boundary between parts. When sending, Serialize generates a
+	// random boundary if you leave it empty.
+	Boundary string
+
+	reader *multipart.Reader
+}
+
+func (p *MultipartBody) Streaming() bool {
+	return true
+}
+
+// Serialize returns a reader that streams the parts out as it gets
+// read. The parts only start going out on the first read, so nothing
+// happens if the body never gets sent.
+func (p *MultipartBody) Serialize() (io.ReadCloser, int, error) {
+	pr, pw := io.Pipe()
+	writer := multipart.NewWriter(pw)
+	if p.Boundary == "" {
+		p.Boundary = writer.Boundary()
+	} else if err := writer.SetBoundary(p.Boundary); err != nil {
+		return nil, 0, InvalidHeaderErr("multipart boundary: %v", err)
+	}
+
+	parts := p.Parts
+	write := func() {
+		for _, part := range parts {
+			if err := part.writeTo(writer); err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+		}
+		pw.CloseWithError(writer.Close())
+	}
+	return &lazyPipe{reader: pr, start: write}, 0, nil
+}
+
+// WriteHeaders writes a "Content-Type" header of "multipart/form-data"
+// with the boundary Serialize set up.
+func (p *MultipartBody) WriteHeaders(msg wire.MessageWriter) error {
+	return WriteContentType(msg, mime.MULTIPART.WithParam("boundary", p.Boundary))
+}
+
+// Deserialize gets ready to read the parts in the given body. It
+// doesn't read any part yet, call NextPart to do that.
+func (p *MultipartBody) Deserialize(body io.ReadCloser) error {
+	if p.Boundary == "" {
+		return InvalidHeaderErr("no multipart boundary")
+	}
+	p.reader = multipart.NewReader(ensureReader(body), p.Boundary)
+	return nil
+}
+
+// NextPart returns the next part in the body Deserialize got or io.EOF
+// if there are no more parts. Reading the next part discards whatever
+// is left of the previous one.
+func (p *MultipartBody) NextPart() (*multipart.Part, error) {
+	if p.reader == nil {
+		return nil, io.EOF
+	}
+	return p.reader.NextPart()
+}
+
+// ReadMultipart gets ready to read the parts in the body of the given
+// message. It reads the boundary from the "Content-Type" header, which
+// must be a "multipart/*" type, then deserializes the body. Use the
+// returned MultipartBody's NextPart to go through the parts. Example.
+//
+//     body, err := ReadMultipart(msg)
+//     for err == nil {
+//         var part *multipart.Part
+//         if part, err = body.NextPart(); err == nil {
+//             fmt.Printf("got part %s\n", part.FormName())
+//         }
+//     }
+//     if err != io.EOF { ... }
+//
+func ReadMultipart(msg wire.MessageReader) (*MultipartBody, error) {
+	contentType, err := ReadContentType(msg)
+	if err != nil {
+		return nil, err
+	}
+	if contentType.Type != "multipart" {
+		return nil, InvalidHeaderErr("not a multipart content type: %s",
+			contentType)
+	}
+	body := &MultipartBody{Boundary: contentType.Param("boundary")}
+	if err := ReadBody(msg, body); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+// ReadParts reads the multipart body of the given message, calling the
+// given function with each part in turn. ReadParts stops at the first
+// error the function returns and returns that error. The part is only
+// good until the function returns, since reading the next part discards
+// whatever is left of it.
+func ReadParts(msg wire.MessageReader, each func(part *multipart.Part) error) error {
+	body, err := ReadMultipart(msg)
+	if err != nil {
+		return err
+	}
+	for {
+		part, err := body.NextPart()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := each(part); err != nil {
+			return err
+		}
+	}
+}
+
+// lazyPipe starts writing to the pipe only when someone reads from it.
+// This way there's no goroutine left hanging if the body never gets
+// read, e.g. because the request couldn't be sent. If the pipe gets
+// closed before the writing starts, it never starts and onUnstartedClose,
+// if there's one, gets called instead to release what start would have.
+type lazyPipe struct {
+	reader           *io.PipeReader
+	start            func()
+	onUnstartedClose func()
+	once             sync.Once
+}
+
+func (p *lazyPipe) Read(buf []byte) (int, error) {
+	p.once.Do(func() { go p.start() })
+	return p.reader.Read(buf)
+}
+
+func (p *lazyPipe) Close() error {
+	p.once.Do(func() {
+		if p.onUnstartedClose != nil {
+			p.onUnstartedClose()
+		}
+	})
+	return p.reader.Close()
+}
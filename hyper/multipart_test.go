@@ -0,0 +1,137 @@
+package hyper
+
+import (
+	"io"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	e "github.com/c0c0n3/resto/util/err"
+)
+
+type part struct {
+	name, fileName, contentType, content string
+}
+
+func readParts(t *testing.T, msg *msgReader) []part {
+	t.Helper()
+	parts := []part{}
+	err := ReadParts(msg, func(p *multipart.Part) error {
+		data, err := io.ReadAll(p)
+		parts = append(parts, part{
+			p.FormName(), p.FileName(), p.Header.Get("Content-Type"),
+			string(data),
+		})
+		return err
+	})
+	if err != nil {
+		t.Fatalf("want: parts; got: %v", err)
+	}
+	return parts
+}
+
+func roundTrip(t *testing.T, body *MultipartBody) []part {
+	t.Helper()
+	writer := newMsgWriter(t)
+	if err := WriteBody(writer, body); err != nil {
+		t.Fatalf("want: body; got: %v", err)
+	}
+	writer.assertNoHeader("Content-Length")
+
+	reader := newMsgReader()
+	reader.headers["Content-Type"] = writer.headers["Content-Type"]
+	reader.body = writer.stringBody()
+	return readParts(t, reader)
+}
+
+func TestMultipartRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "q3.csv")
+	os.WriteFile(path, []byte("a,b\n1,2\n"), 0o644)
+
+	got := roundTrip(t, &MultipartBody{
+		Parts: []FormPart{
+			FormField("title", "Q3 \"report\""),
+			FormFile("notes", "notes.txt", strings.NewReader("draft")),
+			FormFilePath("data", path),
+			{Name: "meta", ContentType: "application/json",
+				Content: strings.NewReader(`{"v":1}`)},
+		},
+	})
+
+	want := []part{
+		{"title", "", "", `Q3 "report"`},
+		{"notes", "notes.txt", "application/octet-stream", "draft"},
+		{"data", "q3.csv", "application/octet-stream", "a,b\n1,2\n"},
+		{"meta", "", "application/json", `{"v":1}`},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("want: %v; got: %v", want, got)
+	}
+	for k := range want {
+		if got[k] != want[k] {
+			t.Errorf("[%d] want: %v; got: %v", k, want[k], got[k])
+		}
+	}
+}
+
+func TestMultipartBoundary(t *testing.T) {
+	writer := newMsgWriter(t)
+	body := &MultipartBody{Boundary: "xyz", Parts: []FormPart{FormField("a", "1")}}
+	WriteBody(writer, body)
+
+	writer.assertHeader("Content-Type", "multipart/form-data; boundary=xyz")
+	if !strings.HasPrefix(writer.stringBody(), "--xyz\r\n") {
+		t.Errorf("want: xyz boundary; got: %s", writer.stringBody())
+	}
+
+	random := &MultipartBody{}
+	if _, _, err := random.Serialize(); err != nil || random.Boundary == "" {
+		t.Errorf("want: random boundary; got: %v", err)
+	}
+	invalid := &MultipartBody{Boundary: "not valid!"}
+	if _, _, err := invalid.Serialize(); err == nil {
+		t.Errorf("want: invalid boundary err")
+	}
+}
+
+func TestMultipartPartErrors(t *testing.T) {
+	for _, p := range []FormPart{
+		{Name: "nothing"},
+		FormFilePath("missing", filepath.Join(t.TempDir(), "missing")),
+	} {
+		body := &MultipartBody{Parts: []FormPart{p}}
+		reader, _, _ := body.Serialize()
+		_, err := io.ReadAll(reader)
+		if err == nil {
+			t.Errorf("[%s] want: error; got: nil", p.Name)
+		}
+		if p.Name == "nothing" {
+			if _, ok := err.(e.Err[NilPtr]); !ok {
+				t.Errorf("want: nil ptr err; got: %v", err)
+			}
+		}
+	}
+}
+
+func TestReadMultipartErrors(t *testing.T) {
+	msg := newMsgReader()
+	if _, err := ReadMultipart(msg); err == nil {
+		t.Errorf("want: no content type err")
+	}
+
+	msg.headers["Content-Type"] = "application/json"
+	if _, err := ReadMultipart(msg); err == nil {
+		t.Errorf("want: not multipart err")
+	}
+
+	msg.headers["Content-Type"] = "multipart/form-data"
+	if _, err := ReadMultipart(msg); err == nil {
+		t.Errorf("want: no boundary err")
+	}
+
+	if _, err := (&MultipartBody{}).NextPart(); err != io.EOF {
+		t.Errorf("want: EOF before Deserialize; got: %v", err)
+	}
+}
@@ -0,0 +1,167 @@
+package hyper
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+
+	"github.com/c0c0n3/resto/hyper/wire"
+	"github.com/c0c0n3/resto/mime"
+)
+
+// Iterator goes through a sequence of values one at a time, the same
+// way a bufio.Scanner does. Next moves on to the next value, returning
+// false if there are no more values or there was an error, Item returns
+// the value Next moved on to and Err returns the error that stopped the
+// iteration, if any.
+type Iterator[T any] interface {
+	Next() bool
+	Item() T
+	Err() error
+}
+
+// NdjsonBody streams a sequence of values to an HTTP body as
+// newline-delimited JSON, i.e. one JSON document per line, in constant
+// space. Use NdjsonFromChan or NdjsonFromIter to build one.
+type NdjsonBody struct {
+	// Next returns the next value to write and true, or false if there
+	// are no more values. It returns an error to abort the stream.
+	Next func() (any, bool, error)
+}
+
+// NdjsonFromChan builds an NdjsonBody to stream the values it receives
+// from the given channel until the channel gets closed. If the body
+// stops going out, e.g. because the connection dropped, NdjsonBody stops
+// receiving from the channel, so make sure whoever sends to it doesn't
+// block forever, e.g. by selecting on the request context too.
+func NdjsonFromChan[T any](items <-chan T) *NdjsonBody {
+	return &NdjsonBody{
+		Next: func() (any, bool, error) {
+			item, ok := <-items
+			return item, ok, nil
+		},
+	}
+}
+
+// NdjsonFromIter builds an NdjsonBody to stream the values the given
+// Iterator goes through. If the Iterator stops because of an error,
+// so does the stream.
+func NdjsonFromIter[T any](items Iterator[T]) *NdjsonBody {
+	return &NdjsonBody{
+		Next: func() (any, bool, error) {
+			if items.Next() {
+				return items.Item(), true, nil
+			}
+			return nil, false, items.Err()
+		},
+	}
+}
+
+func (p *NdjsonBody) Streaming() bool {
+	return true
+}
+
+// Serialize returns a reader that streams the values out as it gets
+// read. The values only start going out on the first read.
+func (p *NdjsonBody) Serialize() (io.ReadCloser, int, error) {
+	if p.Next == nil {
+		return nil, 0, NilNdjsonFuncErr("Next")
+	}
+	pr, pw := io.Pipe()
+	write := func() {
+		encoder := json.NewEncoder(pw)
+		for {
+			item, ok, err := p.Next()
+			if err != nil || !ok {
+				pw.CloseWithError(err)
+				return
+			}
+			if err := encoder.Encode(item); err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+		}
+	}
+	return &lazyPipe{reader: pr, start: write}, 0, nil
+}
+
+// WriteHeaders writes a "Content-Type" header of "application/x-ndjson".
+func (p *NdjsonBody) WriteHeaders(msg wire.MessageWriter) error {
+	return WriteContentType(msg, mime.NDJSON)
+}
+
+// maxNdjsonLine is the longest line an NdjsonDecoder can read.
+const maxNdjsonLine = 1 << 20
+
+// NdjsonDecoder reads a newline-delimited JSON body one line at a time,
+// in constant space, calling Each with the value on each line in turn.
+// It stops at the first error Each returns. Blank lines get skipped, but
+// any other line must hold exactly one JSON value no longer than 1MiB.
+type NdjsonDecoder[T any] struct {
+	Each func(item T) error
+
+	done func()
+}
+
+// NdjsonToChan builds an NdjsonDecoder to send each value it reads to
+// the given channel. The decoder closes the channel when it's done, so
+// you can range over it. Since sending blocks until someone receives,
+// read the body in one goroutine and range over the channel in another.
+func NdjsonToChan[T any](items chan<- T) *NdjsonDecoder[T] {
+	return &NdjsonDecoder[T]{
+		Each: func(item T) error {
+			items <- item
+			return nil
+		},
+		done: func() { close(items) },
+	}
+}
+
+func (p *NdjsonDecoder[T]) Streaming() bool {
+	return true
+}
+
+func (p *NdjsonDecoder[T]) Deserialize(body io.ReadCloser) error {
+	if done := p.done; done != nil {
+		p.done = nil
+		defer done()
+	}
+	if p.Each == nil {
+		return NilNdjsonFuncErr("Each")
+	}
+	return p.decode(ensureReader(body))
+}
+
+func (p *NdjsonDecoder[T]) decode(body io.Reader) error {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 4096), maxNdjsonLine)
+	k := 0
+	for scanner.Scan() {
+		k++
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var item T
+		if err := json.Unmarshal(line, &item); err != nil {
+			return InvalidNdjsonErr("line %d: %v", k, err)
+		}
+		if err := p.Each(item); err != nil {
+			return err
+		}
+	}
+	err := scanner.Err()
+	if err == bufio.ErrTooLong {
+		return InvalidNdjsonErr("line %d: longer than %d bytes",
+			k+1, maxNdjsonLine)
+	}
+	return err
+}
+
+// ReadNdjson reads the newline-delimited JSON body of the given message,
+// calling each with each value in turn until there are no more values
+// or each returns an error.
+func ReadNdjson[T any](msg wire.MessageReader, each func(item T) error) error {
+	return ReadBody(msg, &NdjsonDecoder[T]{Each: each})
+}
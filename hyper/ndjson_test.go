@@ -0,0 +1,200 @@
+package hyper
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/c0c0n3/resto/mime"
+	"github.com/c0c0n3/resto/util/bytez"
+	e "github.com/c0c0n3/resto/util/err"
+)
+
+type ndjsonItem struct {
+	Id   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type sliceIter[T any] struct {
+	items []T
+	item  T
+	err   error
+}
+
+func (p *sliceIter[T]) Next() bool {
+	if len(p.items) == 0 {
+		return false
+	}
+	p.item, p.items = p.items[0], p.items[1:]
+	return true
+}
+
+func (p *sliceIter[T]) Item() T {
+	return p.item
+}
+
+func (p *sliceIter[T]) Err() error {
+	return p.err
+}
+
+const ndjsonItems = `{"id":1,"name":"a"}
+{"id":2,"name":"b"}
+`
+
+func TestWriteNdjsonFromChan(t *testing.T) {
+	items := make(chan ndjsonItem)
+	go func() {
+		items <- ndjsonItem{1, "a"}
+		items <- ndjsonItem{2, "b"}
+		close(items)
+	}()
+
+	msg := newMsgWriter(t)
+	if err := WriteBody(msg, NdjsonFromChan(items)); err != nil {
+		t.Fatalf("want: write; got: %v", err)
+	}
+	msg.assertHeader("Content-Type", mime.NDJSON.String())
+	msg.assertNoHeader("Content-Length")
+	if got := msg.stringBody(); got != ndjsonItems {
+		t.Errorf("want: %s; got: %s", ndjsonItems, got)
+	}
+}
+
+func TestWriteNdjsonFromIter(t *testing.T) {
+	items := &sliceIter[ndjsonItem]{
+		items: []ndjsonItem{{1, "a"}, {2, "b"}},
+	}
+	msg := newMsgWriter(t)
+	if err := WriteBody(msg, NdjsonFromIter[ndjsonItem](items)); err != nil {
+		t.Fatalf("want: write; got: %v", err)
+	}
+	if got := msg.stringBody(); got != ndjsonItems {
+		t.Errorf("want: %s; got: %s", ndjsonItems, got)
+	}
+}
+
+func TestWriteNdjsonIterErr(t *testing.T) {
+	boom := errors.New("boom")
+	items := &sliceIter[int]{items: []int{1}, err: boom}
+	reader, _, _ := NdjsonFromIter[int](items).Serialize()
+
+	got, err := io.ReadAll(reader)
+	if err != boom {
+		t.Errorf("want: boom; got: %v", err)
+	}
+	if string(got) != "1\n" {
+		t.Errorf("want: 1; got: %s", got)
+	}
+}
+
+func TestWriteNdjsonNilNext(t *testing.T) {
+	if _, _, err := (&NdjsonBody{}).Serialize(); err == nil {
+		t.Errorf("want: nil ptr error; got: nil")
+	}
+}
+
+func TestReadNdjson(t *testing.T) {
+	msg := newMsgReader()
+	msg.body = "\n" + ndjsonItems + "\n  {\"id\":3}\n"
+	got := []ndjsonItem{}
+	err := ReadNdjson(msg, func(item ndjsonItem) error {
+		got = append(got, item)
+		return nil
+	})
+
+	if err != nil {
+		t.Fatalf("want: items; got: %v", err)
+	}
+	want := []ndjsonItem{{1, "a"}, {2, "b"}, {3, ""}}
+	if len(got) != len(want) {
+		t.Fatalf("want: %v; got: %v", want, got)
+	}
+	for k := range want {
+		if got[k] != want[k] {
+			t.Errorf("[%d] want: %v; got: %v", k, want[k], got[k])
+		}
+	}
+}
+
+func TestReadNdjsonStopsAtEachErr(t *testing.T) {
+	boom := errors.New("boom")
+	count := 0
+	decoder := &NdjsonDecoder[ndjsonItem]{
+		Each: func(item ndjsonItem) error {
+			count++
+			return boom
+		},
+	}
+	err := decoder.Deserialize(bytez.NewBufferFrom([]byte(ndjsonItems)))
+	if err != boom || count != 1 {
+		t.Errorf("want: boom after 1 item; got: %v after %d", err, count)
+	}
+}
+
+func TestReadMalformedNdjson(t *testing.T) {
+	decoder := &NdjsonDecoder[ndjsonItem]{
+		Each: func(item ndjsonItem) error { return nil },
+	}
+	body := bytez.NewBufferFrom([]byte(ndjsonItems + "{\"id\": x}\n"))
+	err := decoder.Deserialize(body)
+	if _, ok := err.(e.Err[InvalidNdjson]); !ok {
+		t.Fatalf("want: invalid ndjson error; got: %v", err)
+	}
+	if !strings.Contains(err.Error(), "line 3") {
+		t.Errorf("want: line 3; got: %v", err)
+	}
+}
+
+func TestReadNdjsonOneValuePerLine(t *testing.T) {
+	bodies := []string{
+		"{\"id\":1}{\"id\":2}\n",
+		"{\"id\":1,\n\"name\":\"a\"}\n",
+		"{\"id\":1}\n\n" + strings.Repeat(" ", maxNdjsonLine) + "{}\n",
+	}
+	for k, body := range bodies {
+		decoder := &NdjsonDecoder[ndjsonItem]{
+			Each: func(item ndjsonItem) error { return nil },
+		}
+		err := decoder.Deserialize(bytez.NewBufferFrom([]byte(body)))
+		if _, ok := err.(e.Err[InvalidNdjson]); !ok {
+			t.Errorf("[%d] want: invalid ndjson error; got: %v", k, err)
+		}
+	}
+
+	decoder := &NdjsonDecoder[ndjsonItem]{
+		Each: func(item ndjsonItem) error { return nil },
+	}
+	body := "{\"id\":1}\r\n\n{\"id\":2}{}\n"
+	err := decoder.Deserialize(bytez.NewBufferFrom([]byte(body)))
+	if err == nil || !strings.Contains(err.Error(), "line 3") {
+		t.Errorf("want: error on line 3; got: %v", err)
+	}
+}
+
+func TestReadNdjsonNilEach(t *testing.T) {
+	decoder := &NdjsonDecoder[int]{}
+	if err := decoder.Deserialize(bytez.NewBuffer()); err == nil {
+		t.Errorf("want: nil ptr error; got: nil")
+	}
+}
+
+func TestNdjsonToChan(t *testing.T) {
+	items := make(chan ndjsonItem)
+	errs := make(chan error, 1)
+	go func() {
+		body := bytez.NewBufferFrom([]byte(ndjsonItems))
+		errs <- NdjsonToChan(items).Deserialize(body)
+	}()
+
+	got := []ndjsonItem{}
+	for item := range items {
+		got = append(got, item)
+	}
+	if err := <-errs; err != nil {
+		t.Errorf("want: no error; got: %v", err)
+	}
+	if len(got) != 2 || got[1].Name != "b" {
+		t.Errorf("want: 2 items; got: %v", got)
+	}
+}
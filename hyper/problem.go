@@ -0,0 +1,137 @@
+package hyper
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/c0c0n3/resto/util/bytez"
+)
+
+// Problem is an RFC 9457 problem details object, i.e. a machine-readable
+// description of an error in an HTTP response.
+type Problem struct {
+	// A URI reference that identifies the problem type. An empty value
+	// means "about:blank", i.e. the problem has no semantics other than
+	// those of the HTTP status code.
+	Type string
+	// A short, human-readable summary of the problem type.
+	Title string
+	// The HTTP status code of the response carrying the problem.
+	Status int
+	// A human-readable explanation specific to this occurrence of the
+	// problem.
+	Detail string
+	// A URI reference that identifies this occurrence of the problem.
+	Instance string
+	// Any extension members, keyed by member name. Values are whatever
+	// the JSON decoder makes of them, e.g. float64 for numbers.
+	Extensions map[string]any
+}
+
+var problemMembers = []string{"type", "title", "status", "detail", "instance"}
+
+func (p *Problem) members() map[string]any {
+	members := make(map[string]any, len(p.Extensions)+len(problemMembers))
+	for name, value := range p.Extensions {
+		members[name] = value
+	}
+	for _, name := range problemMembers {
+		delete(members, name)
+	}
+	standard := map[string]string{
+		"type": p.Type, "title": p.Title, "detail": p.Detail,
+		"instance": p.Instance,
+	}
+	for name, value := range standard {
+		if value != "" {
+			members[name] = value
+		}
+	}
+	if p.Status != 0 {
+		members["status"] = p.Status
+	}
+	return members
+}
+
+// MarshalJSON writes the problem as a JSON object, leaving out any
+// empty standard members. Extension members sit alongside the standard
+// ones.
+func (p *Problem) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.members())
+}
+
+// UnmarshalJSON reads a problem from a JSON object. Standard members of
+// the wrong type get ignored as RFC 9457 says. Any other member ends up
+// in Extensions.
+func (p *Problem) UnmarshalJSON(data []byte) error {
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(data, &members); err != nil {
+		return err
+	}
+	*p = Problem{}
+	json.Unmarshal(members["type"], &p.Type)
+	json.Unmarshal(members["title"], &p.Title)
+	json.Unmarshal(members["status"], &p.Status)
+	json.Unmarshal(members["detail"], &p.Detail)
+	json.Unmarshal(members["instance"], &p.Instance)
+	for _, name := range problemMembers {
+		delete(members, name)
+	}
+
+	if len(members) > 0 {
+		p.Extensions = make(map[string]any, len(members))
+	}
+	for name, raw := range members {
+		var value any
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return err
+		}
+		p.Extensions[name] = value
+	}
+	return nil
+}
+
+// ProblemError is the error you get when the server replies with a
+// problem details document instead of the response you expected. It
+// wraps the ResponseError with the response details, so errors.As works
+// with both.
+type ProblemError struct {
+	// The problem details the server sent.
+	Problem *Problem
+	// The response the problem came with.
+	Response *ResponseError
+}
+
+func (e *ProblemError) Error() string {
+	return fmt.Sprintf("%v; problem: %s (%s): %s",
+		e.Response, e.Problem.Title, e.Problem.Type, e.Problem.Detail)
+}
+
+func (e *ProblemError) Unwrap() error {
+	return e.Response
+}
+
+// ProblemBody holds a Problem that needs to be (de-)serialized (from)
+// to an HTTP body octet stream containing "application/problem+json"
+// data.
+type ProblemBody struct {
+	Data *Problem
+}
+
+func (p *ProblemBody) Streaming() bool {
+	return false
+}
+
+func (p *ProblemBody) Serialize() (io.ReadCloser, int, error) {
+	buf, err := json.Marshal(p.Data)
+	return bytez.NewBufferFrom(buf), len(buf), err
+}
+
+func (p *ProblemBody) Deserialize(reader io.ReadCloser) error {
+	if p.Data == nil {
+		p.Data = &Problem{}
+	}
+	decoder := json.NewDecoder(ensureReader(reader))
+	return decoder.Decode(p.Data)
+}
@@ -0,0 +1,60 @@
+package hyper
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestSerializeProblem(t *testing.T) {
+	msg := newMsgWriter(t)
+	body := &ProblemBody{Data: &Problem{
+		Type:       "https://my.api/probs/out-of-credit",
+		Title:      "You do not have enough credit.",
+		Status:     403,
+		Extensions: map[string]any{"balance": 30, "title": "ignored"},
+	}}
+	if err := WriteBody(msg, body); err != nil {
+		t.Fatalf("want: body; got: %v", err)
+	}
+
+	var got map[string]any
+	json.Unmarshal([]byte(msg.stringBody()), &got)
+	want := map[string]any{
+		"type":    "https://my.api/probs/out-of-credit",
+		"title":   "You do not have enough credit.",
+		"status":  float64(403),
+		"balance": float64(30),
+	}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("want: %v; got: %v", want, got)
+	}
+}
+
+func TestDeserializeProblem(t *testing.T) {
+	msg := newMsgReader()
+	msg.body = `{"type": "about:blank", "status": "oops", "detail": "d",
+		"instance": "/x/1", "accounts": ["/a/1"]}`
+	body := &ProblemBody{}
+	if err := ReadBody(msg, body); err != nil {
+		t.Fatalf("want: problem; got: %v", err)
+	}
+
+	want := &Problem{
+		Type:       "about:blank",
+		Detail:     "d",
+		Instance:   "/x/1",
+		Extensions: map[string]any{"accounts": []any{"/a/1"}},
+	}
+	if !reflect.DeepEqual(want, body.Data) {
+		t.Errorf("want: %v; got: %v", want, body.Data)
+	}
+}
+
+func TestDeserializeInvalidProblem(t *testing.T) {
+	msg := newMsgReader()
+	msg.body = `["not", "an", "object"]`
+	if err := ReadBody(msg, &ProblemBody{}); err == nil {
+		t.Errorf("want: error; got: nil")
+	}
+}
@@ -0,0 +1,132 @@
+package hyper
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/c0c0n3/resto/hyper/wire"
+	"github.com/c0c0n3/resto/util/err"
+)
+
+// The maximum number of body bytes a ResponseError keeps.
+const MaxBodyExcerpt = 1024
+
+// The response headers a ResponseError keeps. We don't keep all of them
+// so sensitive stuff like cookies doesn't end up in the logs.
+var errorHeaders = []string{
+	"Content-Type", "Content-Length", "Location", "Retry-After",
+	"WWW-Authenticate", "X-Request-Id", "X-Correlation-Id",
+}
+
+// ResponseError holds the details of a server response the client
+// didn't expect, e.g. a 503 when the client wanted a 200. Use errors.As
+// to get hold of it:
+//
+//     var resErr *hyper.ResponseError
+//     if errors.As(err, &resErr) && resErr.StatusCode == 404 {
+//         // not there
+//     }
+//
+// ResponseError wraps an err.Err[UnexpectedResponse], so errors.As also
+// works with that error type.
+type ResponseError struct {
+	// The method of the request the server replied to, if known.
+	Method wire.Method
+	// The URL of the request the server replied to, if known, as
+	// RedactUrl returns it, since the password and query string often
+	// hold credentials that shouldn't end up in the logs.
+	Url string
+	// The response status code.
+	StatusCode wire.StatusCode
+	// The reason phrase in the status line, e.g. "Not Found".
+	Reason string
+	// A few response headers that come in handy to troubleshoot, e.g.
+	// "Content-Type" and "Retry-After".
+	Headers http.Header
+	// The first MaxBodyExcerpt bytes of the response body.
+	Body []byte
+	// Whether the body was longer than the excerpt.
+	Truncated bool
+
+	cause err.Err[UnexpectedResponse]
+}
+
+// UnexpectedResponseError builds a ResponseError out of the given
+// response. It reads the first MaxBodyExcerpt bytes of the response
+// body, so handlers that come after it won't see them. The error message
+// gets formatted according to the given specifier and arguments as if
+// you called Sprintf.
+func UnexpectedResponseError(response wire.ResponseReader, format string, args ...any) *ResponseError {
+	code, status := response.StatusLine()
+	reason := strings.TrimSpace(
+		strings.TrimPrefix(status, fmt.Sprintf("%d", code)))
+	if reason == "" {
+		reason = http.StatusText(code.Value())
+	}
+
+	headers := make(http.Header)
+	for _, name := range errorHeaders {
+		if value := response.Header(name); value != "" {
+			headers.Set(name, value)
+		}
+	}
+
+	body, truncated := readExcerpt(response.Body())
+
+	return &ResponseError{
+		StatusCode: code,
+		Reason:     reason,
+		Headers:    headers,
+		Body:       body,
+		Truncated:  truncated,
+		cause:      UnexpectedResponseErr(format, args...),
+	}
+}
+
+func readExcerpt(body io.Reader) ([]byte, bool) {
+	if body == nil {
+		return []byte{}, false
+	}
+	data, _ := io.ReadAll(io.LimitReader(body, MaxBodyExcerpt+1))
+	if len(data) > MaxBodyExcerpt {
+		return data[:MaxBodyExcerpt], true
+	}
+	return data, false
+}
+
+func (e *ResponseError) Error() string {
+	var msg strings.Builder
+	msg.WriteString(e.cause.Error())
+	if e.Url != "" {
+		fmt.Fprintf(&msg, "; request: %s %s", e.Method, RedactUrl(e.Url))
+	}
+	fmt.Fprintf(&msg, "; response: %d %s", e.StatusCode, e.Reason)
+	if body := strings.TrimSpace(string(e.Body)); body != "" {
+		ellipsis := ""
+		if e.Truncated {
+			ellipsis = "..."
+		}
+		fmt.Fprintf(&msg, "; body: %s%s", body, ellipsis)
+	}
+	return msg.String()
+}
+
+// RedactUrl masks the password in the given URL, if any, and strips the
+// query string and fragment.
+func RedactUrl(raw string) string {
+	target, err := url.Parse(raw)
+	if err != nil {
+		return "(invalid URL)"
+	}
+	target.RawQuery = ""
+	target.ForceQuery = false
+	target.Fragment = ""
+	return target.Redacted()
+}
+
+func (e *ResponseError) Unwrap() error {
+	return e.cause
+}
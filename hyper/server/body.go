@@ -0,0 +1,83 @@
+package server
+
+import (
+	"io"
+
+	"github.com/c0c0n3/resto/hyper"
+	"github.com/c0c0n3/resto/hyper/wire"
+)
+
+// ResponseBody represents some data structure to be written to an
+// HTTP message body. The Body function takes care of converting
+// the data to a sequence of HTTP body octets.
+type ResponseBody interface {
+	[]byte | string | *hyper.JsonBody | *hyper.ProblemBody |
+		*hyper.StreamingBody | *hyper.NdjsonBody
+}
+
+func bodyContentToSerializer[T ResponseBody](data T) hyper.BodySerializer {
+	var serializer hyper.BodySerializer
+	switch target := any(data).(type) {
+	case []byte:
+		serializer = &hyper.ByteBody{Data: target}
+	case string:
+		serializer = &hyper.StringBody{Data: target}
+	case *hyper.JsonBody:
+		serializer = target
+	case *hyper.ProblemBody:
+		serializer = target
+	case *hyper.StreamingBody:
+		serializer = target
+	case *hyper.NdjsonBody:
+		serializer = target
+	}
+	return serializer
+}
+
+// Body turns the given content into HTTP octets which it writes to
+// the message body. Also it writes a "Content-Length" header with
+// the size of the resulting octet sequence, unless the content gets
+// streamed.
+func Body[T ResponseBody](content T) wire.ResponseBuilder {
+	serializer := bodyContentToSerializer(content)
+	return func(msg wire.ResponseWriter) error {
+		return hyper.WriteBody(msg, serializer)
+	}
+}
+
+// Json lets you serialise the given data structure to JSON and write
+// data to the message body. You use Json with the Body function as in
+// the example below.
+//
+//     data := &MyData{greeting: "howzit!"}
+//     return req.Reply(
+//         StatusCode(200),
+//         ContentType(mime.JSON),
+//         Body(Json(data)),
+//     )
+//
+func Json(data any) *hyper.JsonBody {
+	return &hyper.JsonBody{Data: data}
+}
+
+// Stream the content of a reader to the message body.
+func Stream(data io.ReadCloser) *hyper.StreamingBody {
+	return &hyper.StreamingBody{Data: data}
+}
+
+// Ndjson streams the values the given channel receives to the message
+// body as newline-delimited JSON, in constant space, until the channel
+// gets closed. Body takes care of writing the "Content-Type" header.
+// Example.
+//
+//     records := make(chan Record)
+//     go exportRecords(records) // closes records when done
+//     return req.Reply(
+//         StatusCode(200),
+//         Body(Ndjson(records)),
+//     )
+//
+// Use hyper.NdjsonFromIter to stream the values of an iterator instead.
+func Ndjson[T any](items <-chan T) *hyper.NdjsonBody {
+	return hyper.NdjsonFromChan(items)
+}
@@ -0,0 +1,25 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/c0c0n3/resto/hyper"
+	"github.com/c0c0n3/resto/hyper/wire"
+	"github.com/c0c0n3/resto/mime"
+)
+
+// StatusCode writes the response status line with the given code and
+// the standard reason phrase for it.
+func StatusCode(code int) wire.ResponseBuilder {
+	return func(res wire.ResponseWriter) error {
+		return res.StatusLine(wire.StatusCode(code), http.StatusText(code))
+	}
+}
+
+// ContentType writes a "Content-Type" header with the specified MIME
+// type.
+func ContentType(mediaType mime.MediaType) wire.ResponseBuilder {
+	return func(res wire.ResponseWriter) error {
+		return hyper.WriteContentType(res, mediaType)
+	}
+}
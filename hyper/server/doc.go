@@ -0,0 +1,74 @@
+/*
+
+Utils to write HTTP request handlers the same way you write HTTP
+requests with the client package.
+
+
+Request reading
+
+You read the client's request by chaining little, discrete, reusable
+pieces of functionality encapsulated by wire.RequestMatcher functions.
+Each matcher reads some fields of the request and checks they're what
+the server expects, returning an error if they aren't. The request
+reading process stops at the first error. Example:
+
+    params := PathParams{}
+    data := &MyData{}
+    req.Expect(
+        ExpectMethod(wire.PUT),
+        ExpectPath("/data/{id}", params),
+        ExpectContentType(mime.JSON),
+        ReadJsonRequest(data),
+    )
+
+Like for client request builders, it's easy to write your own matcher
+since it's just a function
+
+    func(wire.RequestReader) error
+
+
+Response building
+
+Symmetrically, you build the response to send back to the client out
+of wire.ResponseBuilder functions
+
+    func(wire.ResponseWriter) error
+
+and you do that by calling Request.Reply. If the request reading
+process failed, Reply ignores the builders you pass in and sends the
+client an error response instead. The response status code depends on
+the error returned by the matcher that failed, e.g. a 405 if the
+method isn't what the server expects, a 404 if the path doesn't match,
+a 415 for the wrong content type, a 406 if the server can't produce
+any of the media types the client accepts---see NegotiateContentType---
+and a 400 for a body that can't be read. Otherwise Reply sends the
+client the response written by the builders you pass in. Here's a
+handler putting everything together
+
+    handler := Handler(func(req *Request) *Response {
+        params := PathParams{}
+        data := &MyData{}
+        return req.Expect(
+            ExpectMethod(wire.PUT),
+            ExpectPath("/data/{id}", params),
+            ExpectContentType(mime.JSON),
+            ReadJsonRequest(data),
+        ).Reply(
+            StatusCode(200),
+            ContentType(mime.JSON),
+            Body(Json(data)),
+        )
+    })
+
+
+HTTP server
+
+Handler gives you back a wire.RequestHandler. To serve requests with
+it, turn it into an http.Handler with wire.NewHttpHandler. Then you
+can plug it into the standard lib's HTTP server or a servo.HttpServer
+
+    server := servo.NewHttpServer(8080, 5)
+    server.Route("/data/", wire.NewHttpHandler(handler).ServeHTTP)
+
+*/
+package server
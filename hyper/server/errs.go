@@ -0,0 +1,73 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/c0c0n3/resto/hyper"
+	"github.com/c0c0n3/resto/util/err"
+)
+
+// The request targets a resource that doesn't exist.
+type NotFound string
+
+// The request method isn't supported by the target resource.
+type MethodNotAllowed string
+
+// The request body comes in a format the server can't handle.
+type UnsupportedMediaType string
+
+// None of the media types the server can produce is acceptable to the
+// client.
+type NotAcceptable string
+
+// The request is malformed, e.g. it has an invalid body.
+type BadRequest string
+
+func NotFoundErr(format string, args ...any) err.Err[NotFound] {
+	return err.Mk[NotFound](format, args...)
+}
+
+func MethodNotAllowedErr(format string, args ...any) err.Err[MethodNotAllowed] {
+	return err.Mk[MethodNotAllowed](format, args...)
+}
+
+func UnsupportedMediaTypeErr(format string, args ...any) err.Err[UnsupportedMediaType] {
+	return err.Mk[UnsupportedMediaType](format, args...)
+}
+
+func NotAcceptableErr(format string, args ...any) err.Err[NotAcceptable] {
+	return err.Mk[NotAcceptable](format, args...)
+}
+
+func BadRequestErr(format string, args ...any) err.Err[BadRequest] {
+	return err.Mk[BadRequest](format, args...)
+}
+
+func nilPathParamsErr() err.Err[hyper.NilPtr] {
+	return err.Mk[hyper.NilPtr]("nil PathParams")
+}
+
+func nilMediaTypeErr() err.Err[hyper.NilPtr] {
+	return err.Mk[hyper.NilPtr]("nil MediaType")
+}
+
+// Map an error a RequestMatcher returned to the status code of the
+// response to send back to the client. Any error we don't know about
+// becomes a 400 since it must've been the request's fault, except for
+// nil pointers which are bugs in the server code.
+func statusCodeOf(e error) int {
+	switch e.(type) {
+	case err.Err[NotFound]:
+		return http.StatusNotFound
+	case err.Err[MethodNotAllowed]:
+		return http.StatusMethodNotAllowed
+	case err.Err[NotAcceptable]:
+		return http.StatusNotAcceptable
+	case err.Err[UnsupportedMediaType]:
+		return http.StatusUnsupportedMediaType
+	case err.Err[hyper.NilPtr]:
+		return http.StatusInternalServerError
+	default:
+		return http.StatusBadRequest
+	}
+}
@@ -0,0 +1,136 @@
+package server
+
+import (
+	"github.com/c0c0n3/resto/hyper"
+	"github.com/c0c0n3/resto/hyper/wire"
+	"github.com/c0c0n3/resto/mime"
+)
+
+// Request holds the client's request the server is processing.
+type Request struct {
+	reader wire.RequestReader
+	err    error
+}
+
+// Expect reads the client's request using the given matchers.
+//
+// Each wire.RequestMatcher reads some fields of the HTTP request and
+// checks they're what the server expects, possibly returning an error
+// if they aren't. Expect lets you chain matchers to read a full HTTP
+// request, each matcher contributes its bit and the request reading
+// process stops at the first error. Expect records that error so any
+// later call to Expect does nothing and Reply sends the client an error
+// response instead of the one you specify. Basically the server-side
+// twin of the client's poor man's monomorphic either+IO monad stack.
+func (p *Request) Expect(matchers ...wire.RequestMatcher) *Request {
+	if p.err != nil {
+		return p
+	}
+	for _, match := range matchers {
+		if match == nil {
+			p.err = hyper.NilRequestMatcherErr()
+			return p
+		}
+		if err := match(p.reader); err != nil {
+			p.err = err
+			return p
+		}
+	}
+	return p
+}
+
+// Err returns the error that stopped Expect from reading the request,
+// if any.
+func (p *Request) Err() error {
+	return p.err
+}
+
+// Reader returns the wire.RequestReader to read the raw request data.
+func (p *Request) Reader() wire.RequestReader {
+	return p.reader
+}
+
+// Reply builds the response to send back to the client.
+//
+// If Expect failed to read the request, the client gets an error
+// response with a status code that depends on the error---e.g. a 405
+// if the method wasn't what the server expected, a 415 if the content
+// type wasn't acceptable, and so on. Unknown errors become a 400. The
+// response body is a plain text message with the error details.
+// Otherwise, the client gets the response the given builders write.
+func (p *Request) Reply(builders ...wire.ResponseBuilder) *Response {
+	if p.err != nil {
+		return &Response{requestError: p.err}
+	}
+	return Reply(builders...)
+}
+
+// Response holds the reply to send back to the client.
+type Response struct {
+	requestError error
+	builders     []wire.ResponseBuilder
+}
+
+// Reply builds a response to send back to the client regardless of what
+// the client's request looks like. Use Request.Reply instead if you
+// need to read the request first.
+func Reply(builders ...wire.ResponseBuilder) *Response {
+	return &Response{builders: builders}
+}
+
+func errorReply(e error) []wire.ResponseBuilder {
+	return []wire.ResponseBuilder{
+		StatusCode(statusCodeOf(e)),
+		ContentType(mime.PLAIN_TEXT),
+		Body(e.Error()),
+	}
+}
+
+func (p *Response) write(writer wire.ResponseWriter) error {
+	builders := p.builders
+	if p.requestError != nil {
+		builders = errorReply(p.requestError)
+	}
+	for _, build := range builders {
+		if build == nil {
+			return hyper.NilResponseBuilderErr()
+		}
+		if err := build(writer); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Handler builds a wire.RequestHandler out of a function to serve the
+// client's request.
+//
+// The function gets a Request it can read through Request.Expect and
+// returns the Response to send back to the client which it typically
+// builds with Request.Reply. Example.
+//
+//     handler := Handler(func(req *Request) *Response {
+//         data := &MyData{}
+//         return req.Expect(
+//             ExpectMethod(wire.POST),
+//             ExpectContentType(mime.JSON),
+//             ReadJsonRequest(data),
+//         ).Reply(
+//             StatusCode(201),
+//             ContentType(mime.JSON),
+//             Body(Json(data)),
+//         )
+//     })
+//
+// The Response builders run in turn and in the same order as in the
+// input list, stopping at the first one that errors out. The handler
+// returns that error.
+func Handler(serve func(req *Request) *Response) wire.RequestHandler {
+	return func(reader wire.RequestReader, writer wire.ResponseWriter) error {
+		response := serve(&Request{reader: reader})
+		if response == nil {
+			return hyper.NilResponseBuilderErr()
+		}
+		return response.write(writer)
+	}
+}
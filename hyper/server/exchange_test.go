@@ -0,0 +1,216 @@
+package server
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/c0c0n3/resto/hyper/wire"
+	"github.com/c0c0n3/resto/mime"
+)
+
+type MyData struct {
+	Greeting string
+}
+
+func serve(handle wire.RequestHandler, req *http.Request) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	wire.NewHttpHandler(handle).ServeHTTP(rec, req)
+	return rec
+}
+
+func newJsonRequest(method, target, body string) *http.Request {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	return req
+}
+
+func putGreeting(req *Request) *Response {
+	params := PathParams{}
+	data := &MyData{}
+	return req.Expect(
+		ExpectMethod(wire.PUT),
+		ExpectPath("/greeting/{lang}", params),
+		ExpectContentType(mime.JSON),
+		ReadJsonRequest(data),
+	).Reply(
+		StatusCode(201),
+		ContentType(mime.JSON),
+		Body(Json(&MyData{data.Greeting + " " + params["lang"]})),
+	)
+}
+
+func TestPutJsonGreeting(t *testing.T) {
+	req := newJsonRequest("PUT", "/greeting/en-ZA", `{"Greeting": "howzit!"}`)
+	rec := serve(Handler(putGreeting), req)
+
+	if rec.Code != 201 {
+		t.Errorf("want: 201; got: %d", rec.Code)
+	}
+	if got := rec.Header().Get("Content-Type"); got != "application/json" {
+		t.Errorf("want: application/json; got: %s", got)
+	}
+	want := `{"Greeting":"howzit! en-ZA"}`
+	if got := rec.Body.String(); got != want {
+		t.Errorf("want: %s; got: %s", want, got)
+	}
+	if got := rec.Header().Get("Content-Length"); got != "28" {
+		t.Errorf("want: 28; got: %s", got)
+	}
+}
+
+var requestErrorFixtures = []struct {
+	req  *http.Request
+	want int
+}{
+	{newJsonRequest("GET", "/greeting/en", `{}`), 405},
+	{newJsonRequest("PUT", "/greet/en", `{}`), 404},
+	{httptest.NewRequest("PUT", "/greeting/en", strings.NewReader(`{}`)), 415},
+	{newJsonRequest("PUT", "/greeting/en", `{`), 400},
+}
+
+func TestMapRequestErrorToStatusCode(t *testing.T) {
+	for k, fixture := range requestErrorFixtures {
+		rec := serve(Handler(putGreeting), fixture.req)
+
+		if rec.Code != fixture.want {
+			t.Errorf("[%d] want: %d; got: %d", k, fixture.want, rec.Code)
+		}
+		if got := rec.Header().Get("Content-Type"); got != "text/plain" {
+			t.Errorf("[%d] want: text/plain; got: %s", k, got)
+		}
+		if rec.Body.Len() == 0 {
+			t.Errorf("[%d] want: error message; got: empty body", k)
+		}
+	}
+}
+
+func TestExpectStopsAtFirstError(t *testing.T) {
+	calls := 0
+	counter := func(req wire.RequestReader) error {
+		calls++
+		return nil
+	}
+	handler := Handler(func(req *Request) *Response {
+		return req.Expect(
+			counter,
+			ExpectMethod(wire.POST),
+			counter,
+		).Expect(
+			counter,
+		).Reply(
+			StatusCode(200),
+		)
+	})
+	rec := serve(handler, httptest.NewRequest("GET", "/", nil))
+
+	if rec.Code != 405 {
+		t.Errorf("want: 405; got: %d", rec.Code)
+	}
+	if calls != 1 {
+		t.Errorf("want: 1 call; got: %d", calls)
+	}
+}
+
+func TestNilRequestMatcherIsServerError(t *testing.T) {
+	handler := Handler(func(req *Request) *Response {
+		return req.Expect(nil).Reply()
+	})
+	rec := serve(handler, httptest.NewRequest("GET", "/", nil))
+
+	if rec.Code != 500 {
+		t.Errorf("want: 500; got: %d", rec.Code)
+	}
+}
+
+func TestNilResponseBuilderError(t *testing.T) {
+	handler := Handler(func(req *Request) *Response {
+		return Reply(StatusCode(204), nil)
+	})
+	rec := serve(handler, httptest.NewRequest("GET", "/", nil))
+
+	if rec.Code != 500 {
+		t.Errorf("want: 500; got: %d", rec.Code)
+	}
+}
+
+func TestNilResponseError(t *testing.T) {
+	handler := Handler(func(req *Request) *Response {
+		return nil
+	})
+	rec := serve(handler, httptest.NewRequest("GET", "/", nil))
+
+	if rec.Code != 500 {
+		t.Errorf("want: 500; got: %d", rec.Code)
+	}
+}
+
+func TestResponseBuilderErrorStopsBuilding(t *testing.T) {
+	boom := func(res wire.ResponseWriter) error {
+		return fmt.Errorf("boom!")
+	}
+	handler := Handler(func(req *Request) *Response {
+		return req.Reply(StatusCode(201), boom, Body("not sent"))
+	})
+	rec := serve(handler, httptest.NewRequest("GET", "/", nil))
+
+	if rec.Code != 500 {
+		t.Errorf("want: 500; got: %d", rec.Code)
+	}
+	if got := rec.Body.String(); got != "boom!\n" {
+		t.Errorf("want: boom!; got: %s", got)
+	}
+}
+
+func TestReplyWithStream(t *testing.T) {
+	handler := Handler(func(req *Request) *Response {
+		content := io.NopCloser(strings.NewReader("streamed"))
+		return Reply(Body(Stream(content)))
+	})
+	rec := serve(handler, httptest.NewRequest("GET", "/", nil))
+
+	if rec.Code != 200 {
+		t.Errorf("want: 200; got: %d", rec.Code)
+	}
+	if got := rec.Body.String(); got != "streamed" {
+		t.Errorf("want: streamed; got: %s", got)
+	}
+	if got := rec.Header().Get("Content-Length"); got != "" {
+		t.Errorf("want: no length; got: %s", got)
+	}
+}
+
+func TestReplyWithNdjson(t *testing.T) {
+	handler := Handler(func(req *Request) *Response {
+		records := make(chan MyData)
+		go func() {
+			records <- MyData{Greeting: "howzit!"}
+			records <- MyData{Greeting: "hi"}
+			close(records)
+		}()
+		return Reply(Body(Ndjson(records)))
+	})
+	rec := serve(handler, httptest.NewRequest("GET", "/", nil))
+
+	want := "{\"Greeting\":\"howzit!\"}\n{\"Greeting\":\"hi\"}\n"
+	if got := rec.Body.String(); got != want {
+		t.Errorf("want: %s; got: %s", want, got)
+	}
+	if got := rec.Header().Get("Content-Type"); got != "application/x-ndjson" {
+		t.Errorf("want: ndjson; got: %s", got)
+	}
+}
+
+func TestRequestErr(t *testing.T) {
+	req := &Request{reader: nil}
+	if req.Err() != nil {
+		t.Errorf("want: nil; got: %v", req.Err())
+	}
+	req.Expect(nil)
+	if req.Err() == nil {
+		t.Errorf("want: error; got: nil")
+	}
+}
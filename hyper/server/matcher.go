@@ -0,0 +1,205 @@
+package server
+
+import (
+	"mime/multipart"
+	"net/url"
+	"strings"
+
+	"github.com/c0c0n3/resto/hyper"
+	"github.com/c0c0n3/resto/hyper/wire"
+	"github.com/c0c0n3/resto/mime"
+)
+
+// ExpectMethod builds a wire.RequestMatcher to check the request method
+// is among the given ones. If it isn't, the client gets a 405.
+func ExpectMethod(verb ...wire.Method) wire.RequestMatcher {
+	return func(req wire.RequestReader) error {
+		got, _ := req.RequestLine()
+		for _, v := range verb {
+			if got == v {
+				return nil
+			}
+		}
+		return MethodNotAllowedErr("%v", got)
+	}
+}
+
+// ExpectContentType builds a wire.RequestMatcher to check the request
+// "Content-Type" header matches one of the given MIME types, which can
+// have wildcards---see mime.ParsedType.Matches. Any parameters in the
+// header, e.g. a charset, are ignored unless the given MIME type has
+// them too. If there's no match, the client gets a 415.
+func ExpectContentType(mediaType ...mime.MediaType) wire.RequestMatcher {
+	return func(req wire.RequestReader) error {
+		header := req.Header("Content-Type")
+		if got, err := mime.Parse(header); err == nil {
+			for _, mt := range mediaType {
+				if want, err := mt.Parse(); err == nil && want.Matches(got) {
+					return nil
+				}
+			}
+		}
+		return UnsupportedMediaTypeErr("%s", header)
+	}
+}
+
+// NegotiateContentType builds a wire.RequestMatcher to pick the media
+// type of the response out of the ones the server can produce, going by
+// the client's preferences in the request "Accept" header. The matcher
+// stores the pick in chosen. List offers in order of preference since
+// the first one wins if the client likes more than one equally---see
+// hyper.Negotiate for the details. If the client accepts none of the
+// offers, it gets a 406. Example.
+//
+//     var mediaType mime.MediaType
+//     return req.Expect(
+//         ExpectMethod(wire.GET),
+//         NegotiateContentType(&mediaType, mime.JSON, mime.YAML),
+//     ).Reply(
+//         StatusCode(200),
+//         ContentType(mediaType),
+//         Body(encode(data, mediaType)),
+//     )
+//
+func NegotiateContentType(chosen *mime.MediaType, offers ...mime.MediaType) wire.RequestMatcher {
+	return func(req wire.RequestReader) error {
+		if chosen == nil {
+			return nilMediaTypeErr()
+		}
+		accept := strings.Join(req.Headers()["Accept"], ", ")
+		mediaType, ok := hyper.Negotiate(accept, offers...)
+		if !ok {
+			return NotAcceptableErr("%s", accept)
+		}
+		*chosen = mediaType
+		return nil
+	}
+}
+
+// PathParams holds the values of the path parameters ExpectPath extracts
+// from the request path, keyed by parameter name.
+type PathParams map[string]string
+
+func pathSegments(path string) []string {
+	return strings.Split(strings.Trim(path, "/"), "/")
+}
+
+func isPathParam(segment string) bool {
+	return strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}")
+}
+
+func matchPath(template string, path string, params PathParams) bool {
+	want := pathSegments(template)
+	got := pathSegments(path)
+	if len(want) != len(got) {
+		return false
+	}
+
+	values := make(PathParams)
+	for k, segment := range want {
+		value, err := url.PathUnescape(got[k])
+		if err != nil {
+			return false
+		}
+		if isPathParam(segment) {
+			values[strings.Trim(segment, "{}")] = value
+		} else if segment != value {
+			return false
+		}
+	}
+	for name, value := range values {
+		params[name] = value
+	}
+	return true
+}
+
+// ExpectPath builds a wire.RequestMatcher to check the request path
+// matches the given template and extract any path parameters into the
+// given PathParams. A path parameter is a template segment enclosed in
+// curly braces. If the path doesn't match, the client gets a 404.
+//
+// Example.
+//
+//     params := PathParams{}
+//     req.Expect(
+//         ExpectPath("/users/{id}", params),
+//     )
+//     // request for "/users/123" ==> params["id"] == "123"
+//
+func ExpectPath(template string, params PathParams) wire.RequestMatcher {
+	return func(req wire.RequestReader) error {
+		if params == nil {
+			return nilPathParamsErr()
+		}
+		_, target := req.RequestLine()
+		path, _, _ := strings.Cut(target, "?")
+		if !matchPath(template, path, params) {
+			return NotFoundErr("%s", path)
+		}
+		return nil
+	}
+}
+
+// ReadJsonRequest builds a wire.RequestMatcher to deserialise a JSON
+// request body. If the body isn't valid JSON or doesn't fit the output
+// data structure, the client gets a 400.
+func ReadJsonRequest[T any](output *T) wire.RequestMatcher {
+	return func(req wire.RequestReader) error {
+		deserializer := &hyper.JsonBody{Data: output}
+		if err := hyper.ReadBody(req, deserializer); err != nil {
+			return BadRequestErr("%v", err)
+		}
+		return nil
+	}
+}
+
+// ReadFormRequest builds a wire.RequestMatcher to deserialise a
+// URL-encoded form request body into the given url.Values, string map
+// or struct with "form" tags---see hyper.FormBody for the decoding rules.
+// If the body isn't a valid form or doesn't fit the output, the client
+// gets a 400.
+func ReadFormRequest[T any](output *T) wire.RequestMatcher {
+	return func(req wire.RequestReader) error {
+		deserializer := &hyper.FormBody{Data: output}
+		if err := hyper.ReadBody(req, deserializer); err != nil {
+			return BadRequestErr("%v", err)
+		}
+		return nil
+	}
+}
+
+// ReadRequest builds a wire.RequestMatcher to read in a request body
+// through the given hyper.BodyDeserializer. If the deserializer fails,
+// the client gets a 400.
+func ReadRequest(output hyper.BodyDeserializer) wire.RequestMatcher {
+	return func(req wire.RequestReader) error {
+		if output == nil {
+			return hyper.NilBodyDeserializerErr()
+		}
+		if err := hyper.ReadBody(req, output); err != nil {
+			return BadRequestErr("%v", err)
+		}
+		return nil
+	}
+}
+
+// ReadMultipartRequest builds a wire.RequestMatcher to read a multipart
+// request body, calling the given function with each part in turn. The
+// matcher stops at the first error the function returns. If the body
+// isn't valid multipart content, the client gets a 400.
+func ReadMultipartRequest(each func(part *multipart.Part) error) wire.RequestMatcher {
+	return func(req wire.RequestReader) error {
+		return hyper.ReadParts(req, each)
+	}
+}
+
+// ReadNdjsonRequest builds a wire.RequestMatcher to read a
+// newline-delimited JSON request body one value at a time, calling the
+// given function with each value in turn. The matcher stops at the first
+// error the function returns. If a line isn't valid JSON or doesn't fit
+// T, the client gets a 400.
+func ReadNdjsonRequest[T any](each func(item T) error) wire.RequestMatcher {
+	return func(req wire.RequestReader) error {
+		return hyper.ReadNdjson(req, each)
+	}
+}
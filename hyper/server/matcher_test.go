@@ -0,0 +1,327 @@
+package server
+
+import (
+	"mime/multipart"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/c0c0n3/resto/hyper"
+	"github.com/c0c0n3/resto/hyper/wire"
+	"github.com/c0c0n3/resto/mime"
+	e "github.com/c0c0n3/resto/util/err"
+)
+
+func readerFor(method, target string) wire.RequestReader {
+	var reader wire.RequestReader
+	handle := func(req wire.RequestReader, res wire.ResponseWriter) error {
+		reader = req
+		return nil
+	}
+	serve(handle, httptest.NewRequest(method, target, nil))
+	return reader
+}
+
+func TestExpectMethod(t *testing.T) {
+	req := readerFor("DELETE", "/")
+	if err := ExpectMethod(wire.GET, wire.DELETE)(req); err != nil {
+		t.Errorf("want: match; got: %v", err)
+	}
+	err := ExpectMethod(wire.GET)(req)
+	if _, ok := err.(e.Err[MethodNotAllowed]); !ok {
+		t.Errorf("want: method not allowed; got: %v", err)
+	}
+	err = ExpectMethod()(req)
+	if _, ok := err.(e.Err[MethodNotAllowed]); !ok {
+		t.Errorf("want: method not allowed; got: %v", err)
+	}
+}
+
+var contentTypeFixtures = []struct {
+	header string
+	match  bool
+}{
+	{"application/json", true},
+	{"Application/JSON", true},
+	{"application/json; charset=utf-8", true},
+	{" application/json ;charset=utf-8", true},
+	{"application/yaml", false},
+	{"application/json+yaml", false},
+	{"bogus", false},
+	{"", false},
+}
+
+func TestExpectContentType(t *testing.T) {
+	for k, fixture := range contentTypeFixtures {
+		req := httptest.NewRequest("POST", "/", nil)
+		req.Header.Set("Content-Type", fixture.header)
+		var err error
+		handle := func(r wire.RequestReader, res wire.ResponseWriter) error {
+			err = ExpectContentType(mime.JSON)(r)
+			return nil
+		}
+		serve(handle, req)
+
+		if fixture.match && err != nil {
+			t.Errorf("[%d] want: match; got: %v", k, err)
+		}
+		if _, ok := err.(e.Err[UnsupportedMediaType]); !fixture.match && !ok {
+			t.Errorf("[%d] want: unsupported media type; got: %v", k, err)
+		}
+	}
+}
+
+var pathFixtures = []struct {
+	template string
+	target   string
+	want     PathParams
+}{
+	{"/", "/", PathParams{}},
+	{"/a/b", "/a/b/", PathParams{}},
+	{"/a/{x}", "/a/1?y=2", PathParams{"x": "1"}},
+	{"/a/{x}/b/{y}", "/a/1/b/2", PathParams{"x": "1", "y": "2"}},
+	{"/a/{x}", "/a/x%20y", PathParams{"x": "x y"}},
+	{"/a/{x}", "/a", nil},
+	{"/a/{x}", "/a/1/2", nil},
+	{"/a/b", "/a/c", nil},
+}
+
+func TestExpectPath(t *testing.T) {
+	for k, fixture := range pathFixtures {
+		req := readerFor("GET", fixture.target)
+		got := PathParams{}
+		err := ExpectPath(fixture.template, got)(req)
+
+		if fixture.want == nil {
+			if _, ok := err.(e.Err[NotFound]); !ok {
+				t.Errorf("[%d] want: not found; got: %v", k, err)
+			}
+			if len(got) > 0 {
+				t.Errorf("[%d] want: no params; got: %v", k, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("[%d] want: match; got: %v", k, err)
+		}
+		if len(got) != len(fixture.want) {
+			t.Errorf("[%d] want: %v; got: %v", k, fixture.want, got)
+		}
+		for name, value := range fixture.want {
+			if got[name] != value {
+				t.Errorf("[%d] want: %v; got: %v", k, fixture.want, got)
+			}
+		}
+	}
+}
+
+func TestExpectPathNilParams(t *testing.T) {
+	req := readerFor("GET", "/")
+	err := ExpectPath("/", nil)(req)
+	if _, ok := err.(e.Err[hyper.NilPtr]); !ok {
+		t.Errorf("want: nil ptr err; got: %v", err)
+	}
+}
+
+func TestReadRequest(t *testing.T) {
+	req := httptest.NewRequest("POST", "/", strings.NewReader("howzit!"))
+	output := &hyper.StringBody{}
+	var err error
+	handle := func(r wire.RequestReader, res wire.ResponseWriter) error {
+		err = ReadRequest(output)(r)
+		return nil
+	}
+	serve(handle, req)
+
+	if err != nil {
+		t.Errorf("want: body; got: %v", err)
+	}
+	if output.Data != "howzit!" {
+		t.Errorf("want: howzit!; got: %s", output.Data)
+	}
+}
+
+func TestReadRequestNilDeserializer(t *testing.T) {
+	req := readerFor("POST", "/")
+	err := ReadRequest(nil)(req)
+	if _, ok := err.(e.Err[hyper.NilPtr]); !ok {
+		t.Errorf("want: nil ptr err; got: %v", err)
+	}
+}
+
+func TestNegotiateContentType(t *testing.T) {
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Add("Accept", "application/json;q=0.5")
+	req.Header.Add("Accept", "application/yaml")
+	var chosen mime.MediaType
+	var err error
+	handle := func(r wire.RequestReader, res wire.ResponseWriter) error {
+		err = NegotiateContentType(&chosen, mime.JSON, mime.YAML)(r)
+		return nil
+	}
+	serve(handle, req)
+
+	if err != nil {
+		t.Errorf("want: match; got: %v", err)
+	}
+	if chosen != mime.YAML {
+		t.Errorf("want: %s; got: %s", mime.YAML, chosen)
+	}
+}
+
+func TestNegotiateContentTypeNotAcceptable(t *testing.T) {
+	handle := Handler(func(req *Request) *Response {
+		var chosen mime.MediaType
+		return req.Expect(
+			NegotiateContentType(&chosen, mime.JSON),
+		).Reply(
+			StatusCode(200),
+		)
+	})
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept", "text/*")
+	rec := serve(handle, req)
+
+	if rec.Code != 406 {
+		t.Errorf("want: 406; got: %d", rec.Code)
+	}
+}
+
+func TestNegotiateContentTypeNilOutput(t *testing.T) {
+	err := NegotiateContentType(nil, mime.JSON)(readerFor("GET", "/"))
+	if _, ok := err.(e.Err[hyper.NilPtr]); !ok {
+		t.Errorf("want: nil ptr; got: %v", err)
+	}
+}
+
+func TestExpectContentTypeWildcard(t *testing.T) {
+	req := httptest.NewRequest("POST", "/", nil)
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+	var err error
+	handle := func(r wire.RequestReader, res wire.ResponseWriter) error {
+		err = ExpectContentType(mime.YAML, "application/*+json")(r)
+		return nil
+	}
+	serve(handle, req)
+
+	if err != nil {
+		t.Errorf("want: match; got: %v", err)
+	}
+}
+
+func uploadHandler(names *[]string) wire.RequestHandler {
+	return Handler(func(req *Request) *Response {
+		return req.Expect(
+			ReadMultipartRequest(func(part *multipart.Part) error {
+				*names = append(*names, part.FileName())
+				return nil
+			}),
+		).Reply(StatusCode(204))
+	})
+}
+
+func TestReadMultipartRequest(t *testing.T) {
+	body := "--xyz\r\n" +
+		"Content-Disposition: form-data; name=\"f\"; filename=\"a.txt\"\r\n\r\n" +
+		"a\r\n--xyz\r\n" +
+		"Content-Disposition: form-data; name=\"f\"; filename=\"b.txt\"\r\n\r\n" +
+		"b\r\n--xyz--\r\n"
+	req := httptest.NewRequest("POST", "/", strings.NewReader(body))
+	req.Header.Set("Content-Type", "multipart/form-data; boundary=xyz")
+	names := []string{}
+	rec := serve(uploadHandler(&names), req)
+
+	if rec.Code != 204 {
+		t.Errorf("want: 204; got: %d", rec.Code)
+	}
+	if strings.Join(names, " ") != "a.txt b.txt" {
+		t.Errorf("want: a.txt b.txt; got: %v", names)
+	}
+}
+
+func TestReadMultipartRequestNotMultipart(t *testing.T) {
+	req := httptest.NewRequest("POST", "/", strings.NewReader("{}"))
+	req.Header.Set("Content-Type", "application/json")
+	names := []string{}
+	rec := serve(uploadHandler(&names), req)
+
+	if rec.Code != 400 {
+		t.Errorf("want: 400; got: %d", rec.Code)
+	}
+}
+
+type searchForm struct {
+	Query string `form:"q"`
+	Page  int    `form:"page"`
+}
+
+func searchHandler(form *searchForm) wire.RequestHandler {
+	return Handler(func(req *Request) *Response {
+		return req.Expect(
+			ExpectContentType(mime.URL_ENCODED),
+			ReadFormRequest(form),
+		).Reply(StatusCode(204))
+	})
+}
+
+func TestReadFormRequest(t *testing.T) {
+	req := httptest.NewRequest("POST", "/", strings.NewReader("q=go+http&page=2"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	form := &searchForm{}
+	rec := serve(searchHandler(form), req)
+
+	if rec.Code != 204 {
+		t.Errorf("want: 204; got: %d", rec.Code)
+	}
+	if form.Query != "go http" || form.Page != 2 {
+		t.Errorf("want: go http, 2; got: %+v", form)
+	}
+}
+
+func TestReadInvalidFormRequest(t *testing.T) {
+	req := httptest.NewRequest("POST", "/", strings.NewReader("page=two"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := serve(searchHandler(&searchForm{}), req)
+
+	if rec.Code != 400 {
+		t.Errorf("want: 400; got: %d", rec.Code)
+	}
+}
+
+type exportRecord struct {
+	Id int
+}
+
+func importHandler(ids *[]int) wire.RequestHandler {
+	return Handler(func(req *Request) *Response {
+		return req.Expect(
+			ReadNdjsonRequest(func(r exportRecord) error {
+				*ids = append(*ids, r.Id)
+				return nil
+			}),
+		).Reply(StatusCode(204))
+	})
+}
+
+func TestReadNdjsonRequest(t *testing.T) {
+	req := httptest.NewRequest("POST", "/", strings.NewReader("{\"Id\":1}\n{\"Id\":2}\n"))
+	ids := []int{}
+	rec := serve(importHandler(&ids), req)
+
+	if rec.Code != 204 {
+		t.Errorf("want: 204; got: %d", rec.Code)
+	}
+	if len(ids) != 2 || ids[0] != 1 || ids[1] != 2 {
+		t.Errorf("want: [1 2]; got: %v", ids)
+	}
+}
+
+func TestReadNdjsonRequestMalformed(t *testing.T) {
+	req := httptest.NewRequest("POST", "/", strings.NewReader("{\"Id\":1}\nnope\n"))
+	ids := []int{}
+	rec := serve(importHandler(&ids), req)
+
+	if rec.Code != 400 {
+		t.Errorf("want: 400; got: %d", rec.Code)
+	}
+}
@@ -0,0 +1,197 @@
+package hyper
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/c0c0n3/resto/hyper/wire"
+)
+
+// maxEventLine is the longest line an EventDecoder can read.
+const maxEventLine = 1 << 20
+
+// Event is a Server-Sent Event as found in a "text/event-stream" body.
+type Event struct {
+	// The last event ID the stream set, i.e. the ID of this event if it
+	// has one or otherwise that of the latest event before it with an ID.
+	// A client sends it back in the "Last-Event-ID" header when it
+	// reconnects.
+	Id string
+	// The event type, "message" if the event doesn't say.
+	Type string
+	// The event data. If the event has more than one data line, Data
+	// joins them with a newline.
+	Data string
+	// How long the client should wait before reconnecting, if the event
+	// says, zero otherwise.
+	Retry time.Duration
+}
+
+// MarshalText encodes the event in the "text/event-stream" format,
+// blank line at the end included. The event only gets an "id" line if
+// it has an ID, an "event" line if its type isn't "message" and a
+// "retry" line if Retry is positive. Each line in Data goes out on a
+// separate "data" line. The ID and type can't contain newlines and the
+// ID can't contain NUL either, since the client would mangle them.
+func (e Event) MarshalText() ([]byte, error) {
+	if strings.ContainsAny(e.Id, "\r\n\x00") {
+		return nil, InvalidEventErr("invalid event ID: %q", e.Id)
+	}
+	if strings.ContainsAny(e.Type, "\r\n") {
+		return nil, InvalidEventErr("invalid event type: %q", e.Type)
+	}
+
+	buf := &bytes.Buffer{}
+	if e.Id != "" {
+		buf.WriteString("id: " + e.Id + "\n")
+	}
+	if e.Type != "" && e.Type != "message" {
+		buf.WriteString("event: " + e.Type + "\n")
+	}
+	if e.Retry > 0 {
+		ms := strconv.FormatInt(e.Retry.Milliseconds(), 10)
+		buf.WriteString("retry: " + ms + "\n")
+	}
+	data := strings.ReplaceAll(e.Data, "\r\n", "\n")
+	data = strings.ReplaceAll(data, "\r", "\n")
+	for _, line := range strings.Split(data, "\n") {
+		buf.WriteString("data: " + line + "\n")
+	}
+	buf.WriteString("\n")
+	return buf.Bytes(), nil
+}
+
+// EventDecoder reads a "text/event-stream" body one event at a time,
+// calling Each with each event in turn as soon as it's in. It stops at
+// the first error Each returns. It parses the stream as the HTML
+// standard says, so it skips comments and events with no data.
+type EventDecoder struct {
+	Each func(event Event) error
+	// The last event ID. Set it to the ID of the last event you got
+	// before reconnecting to carry on from there. After reading the body,
+	// it holds the ID of the last event the stream set, if any.
+	LastEventId string
+	// The reconnection delay the stream asked for in its latest "retry"
+	// field, zero if the stream didn't ask for any.
+	Retry time.Duration
+}
+
+func (p *EventDecoder) Streaming() bool {
+	return true
+}
+
+func (p *EventDecoder) Deserialize(body io.ReadCloser) error {
+	if p.Each == nil {
+		return NilEventFuncErr()
+	}
+	scanner := bufio.NewScanner(ensureReader(body))
+	scanner.Buffer(make([]byte, 0, 4096), maxEventLine)
+	scanner.Split(scanEventLines)
+
+	data, eventType := &strings.Builder{}, ""
+	var retry time.Duration
+	hasData, first := false, true
+	for scanner.Scan() {
+		line := scanner.Text()
+		if first {
+			line = strings.TrimPrefix(line, "\uFEFF")
+			first = false
+		}
+		if line == "" {
+			if hasData {
+				event := Event{
+					Id:    p.LastEventId,
+					Type:  eventType,
+					Data:  data.String(),
+					Retry: retry,
+				}
+				if event.Type == "" {
+					event.Type = "message"
+				}
+				if err := p.Each(event); err != nil {
+					return err
+				}
+			}
+			data.Reset()
+			eventType, retry, hasData = "", 0, false
+			continue
+		}
+		if line[0] == ':' {
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "data":
+			if hasData {
+				data.WriteByte('\n')
+			}
+			data.WriteString(value)
+			hasData = true
+		case "event":
+			eventType = value
+		case "id":
+			if !strings.ContainsRune(value, 0) {
+				p.LastEventId = value
+			}
+		case "retry":
+			if ms, ok := parseRetry(value); ok {
+				retry = ms
+				p.Retry = ms
+			}
+		}
+	}
+	return scanner.Err()
+	// NOTE. Incomplete events. If the stream ends before the blank line
+	// that closes an event, the event gets dropped as the standard says.
+}
+
+func parseRetry(value string) (time.Duration, bool) {
+	if value == "" || strings.Trim(value, "0123456789") != "" {
+		return 0, false
+	}
+	ms, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return time.Duration(ms) * time.Millisecond, true
+}
+
+// scanEventLines is a bufio.SplitFunc to split a stream into lines that
+// end in "\r\n", "\n" or "\r".
+func scanEventLines(data []byte, atEOF bool) (int, []byte, error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+	if k := bytes.IndexAny(data, "\r\n"); k >= 0 {
+		if data[k] == '\n' {
+			return k + 1, data[:k], nil
+		}
+		if k+1 < len(data) {
+			if data[k+1] == '\n' {
+				return k + 2, data[:k], nil
+			}
+			return k + 1, data[:k], nil
+		}
+		if atEOF {
+			return k + 1, data[:k], nil
+		}
+		return 0, nil, nil // need to see if "\n" comes after "\r"
+	}
+	if atEOF {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
+
+// ReadEvents reads the "text/event-stream" body of the given message,
+// calling each with each event in turn until the stream ends or each
+// returns an error.
+func ReadEvents(msg wire.MessageReader, each func(event Event) error) error {
+	return ReadBody(msg, &EventDecoder{Each: each})
+}
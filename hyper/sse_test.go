@@ -0,0 +1,160 @@
+package hyper
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/c0c0n3/resto/util/bytez"
+)
+
+func decodeEvents(t *testing.T, stream string) ([]Event, *EventDecoder) {
+	got := []Event{}
+	decoder := &EventDecoder{
+		Each: func(event Event) error {
+			got = append(got, event)
+			return nil
+		},
+	}
+	body := io.NopCloser(iotest.OneByteReader(strings.NewReader(stream)))
+	if err := decoder.Deserialize(body); err != nil {
+		t.Fatalf("want: events; got: %v", err)
+	}
+	return got, decoder
+}
+
+func assertEvents(t *testing.T, want []Event, got []Event) {
+	if len(got) != len(want) {
+		t.Fatalf("want: %v; got: %v", want, got)
+	}
+	for k := range want {
+		if got[k] != want[k] {
+			t.Errorf("[%d] want: %+v; got: %+v", k, want[k], got[k])
+		}
+	}
+}
+
+func TestDecodeEvents(t *testing.T) {
+	stream := "\uFEFF: a comment\n" +
+		"data: first\n\n" +
+		"id: 1\nevent: status\ndata:line 1\ndata:  line 2\n\n" +
+		"retry: 250\ndata\n\n" +
+		"id\r\ndata: crlf\r\n\r\n" +
+		"id: 2\rdata: cr\r\r" +
+		"data: dropped, no blank line"
+	got, decoder := decodeEvents(t, stream)
+
+	want := []Event{
+		{Type: "message", Data: "first"},
+		{Id: "1", Type: "status", Data: "line 1\n line 2"},
+		{Id: "1", Type: "message", Data: "", Retry: 250 * time.Millisecond},
+		{Id: "", Type: "message", Data: "crlf"},
+		{Id: "2", Type: "message", Data: "cr"},
+	}
+	assertEvents(t, want, got)
+	if decoder.LastEventId != "2" || decoder.Retry != 250*time.Millisecond {
+		t.Errorf("want: 2, 250ms; got: %s, %v", decoder.LastEventId, decoder.Retry)
+	}
+}
+
+func TestDecodeEventsSkipsEmptyEvents(t *testing.T) {
+	stream := "id: 7\n\nevent: ping\n\nretry: 1000\n\n: keep-alive\n\n"
+	got, decoder := decodeEvents(t, stream)
+
+	assertEvents(t, []Event{}, got)
+	if decoder.LastEventId != "7" || decoder.Retry != time.Second {
+		t.Errorf("want: 7, 1s; got: %s, %v", decoder.LastEventId, decoder.Retry)
+	}
+}
+
+func TestDecodeEventsIgnoresBadFields(t *testing.T) {
+	stream := "retry: 1s\nid: a\x00b\nfoo: bar\ndata: x\n\n"
+	got, decoder := decodeEvents(t, stream)
+
+	assertEvents(t, []Event{{Type: "message", Data: "x"}}, got)
+	if decoder.LastEventId != "" || decoder.Retry != 0 {
+		t.Errorf("want: no id, no retry; got: %s, %v", decoder.LastEventId, decoder.Retry)
+	}
+}
+
+func TestDecodeEventsKeepsLastEventId(t *testing.T) {
+	var got Event
+	decoder := &EventDecoder{
+		LastEventId: "41",
+		Each: func(event Event) error {
+			got = event
+			return nil
+		},
+	}
+	decoder.Deserialize(bytez.NewBufferFrom([]byte("data: x\n\n")))
+	if got.Id != "41" {
+		t.Errorf("want: 41; got: %s", got.Id)
+	}
+}
+
+func TestDecodeEventsStopsAtEachErr(t *testing.T) {
+	boom := errors.New("boom")
+	count := 0
+	msg := newMsgReader()
+	msg.body = "data: a\n\ndata: b\n\n"
+	err := ReadEvents(msg, func(event Event) error {
+		count++
+		return boom
+	})
+	if err != boom || count != 1 {
+		t.Errorf("want: boom after 1 event; got: %v after %d", err, count)
+	}
+}
+
+func TestDecodeEventsNilEach(t *testing.T) {
+	decoder := &EventDecoder{}
+	if err := decoder.Deserialize(bytez.NewBuffer()); err == nil {
+		t.Errorf("want: nil ptr error; got: nil")
+	}
+}
+
+func TestMarshalEvent(t *testing.T) {
+	cases := []struct {
+		event Event
+		want  string
+	}{
+		{Event{Data: "x"}, "data: x\n\n"},
+		{Event{}, "data: \n\n"},
+		{Event{Id: "1", Type: "message", Data: "x"}, "id: 1\ndata: x\n\n"},
+		{
+			Event{Id: "2", Type: "tick", Data: "a\r\nb\rc\n d", Retry: 1500 * time.Millisecond},
+			"id: 2\nevent: tick\nretry: 1500\ndata: a\ndata: b\ndata: c\ndata:  d\n\n",
+		},
+	}
+	for k, c := range cases {
+		got, err := c.event.MarshalText()
+		if err != nil || string(got) != c.want {
+			t.Errorf("[%d] want: %q; got: %q, %v", k, c.want, got, err)
+		}
+	}
+}
+
+func TestMarshalEventRoundTrip(t *testing.T) {
+	want := []Event{
+		{Id: "1", Type: "tick", Data: "a\nb"},
+		{Id: "1", Type: "message", Data: " leading space"},
+	}
+	stream := ""
+	for _, event := range want {
+		data, _ := event.MarshalText()
+		stream += string(data)
+	}
+	got, _ := decodeEvents(t, stream)
+	assertEvents(t, want, got)
+}
+
+func TestMarshalInvalidEvent(t *testing.T) {
+	for _, event := range []Event{{Id: "a\nb"}, {Id: "a\x00b"}, {Type: "a\rb"}} {
+		if _, err := event.MarshalText(); err == nil {
+			t.Errorf("[%q] want: error; got: nil", event.Id+event.Type)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package wire
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/c0c0n3/resto/yoorel"
+)
+
+// ResponseCookies parses the "Set-Cookie" headers in the given response,
+// skipping any malformed cookie.
+func ResponseCookies(res ResponseReader) []*http.Cookie {
+	if res == nil {
+		return nil
+	}
+	headers := make(http.Header)
+	for name, values := range res.Headers() {
+		if http.CanonicalHeaderKey(name) == "Set-Cookie" {
+			headers["Set-Cookie"] = append(headers["Set-Cookie"], values...)
+		}
+	}
+	return (&http.Response{Header: headers}).Cookies()
+	// NOTE. Cookie parsing. The http package only exposes its Set-Cookie
+	// parser through http.Response, so we cook up a throwaway response.
+}
+
+// Cookies builds a Middleware to keep cookies in the given jar.
+//
+// Before sending a request, the Middleware looks up the cookies in the
+// jar for the request URL and adds them to the request's "Cookie" header,
+// after any cookies already there. After getting the server's response,
+// it stores any cookies the response sets in the jar. Example.
+//
+//     jar, _ := cookiejar.New(nil)
+//     send := Chain(NewSender[DefaultClient](), Cookies(jar))
+//
+// The Middleware only sees the final response to a request, so it
+// misses the cookies set by any redirect response the underlying
+// http.Client follows. If that matters, give the jar to the http.Client
+// instead. If jar is nil, the Middleware does nothing.
+func Cookies(jar http.CookieJar) Middleware {
+	return func(next Sender) Sender {
+		if jar == nil {
+			return next
+		}
+		return func(build RequestBuilder) (ResponseReader, error) {
+			record, err := Record(build)
+			if err != nil {
+				return nil, err
+			}
+			if record.Url == nil {
+				return next(record.Replay)
+			}
+
+			target := yoorel.ToURL(record.Url)
+			pairs := []string{}
+			if existing := record.Headers.Get("Cookie"); existing != "" {
+				pairs = append(pairs, existing)
+			}
+			for _, c := range jar.Cookies(target) {
+				pairs = append(pairs, (&http.Cookie{Name: c.Name, Value: c.Value}).String())
+			}
+			if len(pairs) > 0 {
+				record.Headers.Set("Cookie", strings.Join(pairs, "; "))
+			}
+
+			res, err := next(record.Replay)
+			if err != nil {
+				return res, err
+			}
+			if cookies := ResponseCookies(res); len(cookies) > 0 {
+				jar.SetCookies(target, cookies)
+			}
+			return res, nil
+		}
+	}
+}
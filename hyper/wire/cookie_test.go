@@ -0,0 +1,95 @@
+package wire
+
+import (
+	"net/http"
+	"net/http/cookiejar"
+	"testing"
+
+	"github.com/c0c0n3/resto/yoorel"
+)
+
+type cookieServer struct {
+	cookies []string
+}
+
+func (p *cookieServer) send(req *http.Request) (*http.Response, error) {
+	p.cookies = append(p.cookies, req.Header.Get("Cookie"))
+	res := &http.Response{StatusCode: 200, Header: make(http.Header)}
+	if req.URL.Path == "/login" {
+		res.Header.Add("Set-Cookie", "session=s3cr3t; Path=/")
+		res.Header.Add("Set-Cookie", "theme=dark")
+	}
+	return res, nil
+}
+
+func getPath(path string, cookie string) RequestBuilder {
+	return func(req RequestWriter) error {
+		url := yoorel.BuilderFrom("http://legacy.api" + path).Build().Right()
+		if err := req.RequestLine(GET, url); err != nil {
+			return err
+		}
+		if cookie != "" {
+			return req.Header("Cookie", cookie)
+		}
+		return nil
+	}
+}
+
+func TestCookiesKeepsSessionCookie(t *testing.T) {
+	server := &cookieServer{}
+	jar, _ := cookiejar.New(nil)
+	send := Chain(NewSender(server.send), Cookies(jar))
+
+	send(getPath("/login", ""))
+	send(getPath("/data", ""))
+	send(getPath("/data", "extra=1"))
+
+	want := []string{"", "session=s3cr3t; theme=dark", "extra=1; session=s3cr3t; theme=dark"}
+	for k, w := range want {
+		if server.cookies[k] != w {
+			t.Errorf("[%d] want: %s; got: %s", k, w, server.cookies[k])
+		}
+	}
+}
+
+func TestCookiesNilJar(t *testing.T) {
+	server := &cookieServer{}
+	send := Chain(NewSender(server.send), Cookies(nil))
+
+	send(getPath("/login", ""))
+	send(getPath("/data", ""))
+
+	if server.cookies[1] != "" {
+		t.Errorf("want: no cookies; got: %s", server.cookies[1])
+	}
+}
+
+type setCookieReader struct {
+	resReader
+	headers map[string][]string
+}
+
+func (p *setCookieReader) Headers() map[string][]string {
+	return p.headers
+}
+
+func TestResponseCookies(t *testing.T) {
+	res := &setCookieReader{
+		headers: map[string][]string{
+			"set-cookie": {"a=1; HttpOnly", "=no-name"},
+			"Set-Cookie": {"b=2"},
+			"Cookie":     {"c=3"},
+		},
+	}
+	cookies := ResponseCookies(res)
+	got := map[string]string{}
+	for _, c := range cookies {
+		got[c.Name] = c.Value
+	}
+	if len(got) != 2 || got["a"] != "1" || got["b"] != "2" {
+		t.Errorf("want: a=1, b=2; got: %v", got)
+	}
+	if ResponseCookies(nil) != nil {
+		t.Errorf("want: no cookies for nil response")
+	}
+}
@@ -0,0 +1,179 @@
+package wire
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Decompress builds a Middleware to decode gzip and deflate response
+// bodies.
+//
+// If the request has no "Accept-Encoding" header, the Middleware adds
+// one to say it takes gzip and deflate. Then, if the response comes
+// back with a "Content-Encoding" of gzip, x-gzip or deflate, the
+// Middleware hands over a response whose body gets decoded as you read
+// it and whose headers have no "Content-Encoding" or "Content-Length",
+// since they don't apply to the decoded body anymore. Responses with no
+// "Content-Encoding" or with one the Middleware doesn't know go through
+// untouched. Example.
+//
+//     client := &http.Client{
+//         Transport: &http.Transport{DisableCompression: true},
+//     }
+//     send := Chain(NewSender(client), Decompress())
+//
+// Notice the http package only decodes gzip responses by itself if you
+// don't set the "Accept-Encoding" header and don't disable compression
+// in the http.Transport. Decompress works either way.
+func Decompress() Middleware {
+	return Intercept(
+		func(req *RequestRecorder) error {
+			if req.Headers.Get("Accept-Encoding") == "" {
+				req.Headers.Set("Accept-Encoding", "gzip, deflate")
+			}
+			return nil
+		},
+		func(res ResponseReader) (ResponseReader, error) {
+			codings, ok := contentCodings(res.Header("Content-Encoding"))
+			if !ok || len(codings) == 0 {
+				return res, nil
+			}
+			return &decodedResponse{
+				ResponseReader: res,
+				body:           &decodedBody{raw: res.Body(), codings: codings},
+			}, nil
+		},
+	)
+}
+
+// contentCodings parses a "Content-Encoding" header, returning the
+// codings in the order they got applied and skipping identity. It
+// returns false if there's a coding it can't decode.
+func contentCodings(header string) ([]string, bool) {
+	codings := []string{}
+	for _, c := range strings.Split(header, ",") {
+		c = strings.ToLower(strings.TrimSpace(c))
+		switch c {
+		case "", "identity":
+		case "gzip", "x-gzip", "deflate":
+			codings = append(codings, c)
+		default:
+			return nil, false
+		}
+	}
+	return codings, true
+}
+
+// decodedResponse is a ResponseReader with a decoded body and no
+// "Content-Encoding" or "Content-Length" headers.
+type decodedResponse struct {
+	ResponseReader
+	body *decodedBody
+}
+
+func isEncodingHeader(name string) bool {
+	name = http.CanonicalHeaderKey(name)
+	return name == "Content-Encoding" || name == "Content-Length"
+}
+
+func (p *decodedResponse) Header(name string) string {
+	if isEncodingHeader(name) {
+		return ""
+	}
+	return p.ResponseReader.Header(name)
+}
+
+func (p *decodedResponse) Headers() map[string][]string {
+	headers := make(map[string][]string)
+	for name, values := range p.ResponseReader.Headers() {
+		if !isEncodingHeader(name) {
+			headers[name] = values
+		}
+	}
+	return headers
+}
+
+func (p *decodedResponse) Body() io.ReadCloser {
+	return p.body
+}
+
+func (p *decodedResponse) Unwrap() ResponseReader {
+	return p.ResponseReader
+}
+
+// decodedBody sets up the decoders on the first read, so an empty body,
+// e.g. the one of a HEAD response, doesn't trip them up.
+type decodedBody struct {
+	raw     io.ReadCloser
+	codings []string
+	decoded io.Reader
+	closers []io.Closer
+	err     error
+}
+
+func (p *decodedBody) setup() error {
+	if p.raw == nil {
+		return io.EOF
+	}
+	reader := io.Reader(p.raw)
+	for k := len(p.codings) - 1; k >= 0; k-- {
+		decoder, err := newDecoder(p.codings[k], reader)
+		if err != nil {
+			return err
+		}
+		p.closers = append(p.closers, decoder)
+		reader = decoder
+	}
+	p.decoded = reader
+	return nil
+}
+
+func (p *decodedBody) Read(buf []byte) (int, error) {
+	if p.decoded == nil && p.err == nil {
+		p.err = p.setup()
+	}
+	if p.err != nil {
+		return 0, p.err
+	}
+	return p.decoded.Read(buf)
+}
+
+func (p *decodedBody) Close() error {
+	for _, c := range p.closers {
+		c.Close()
+	}
+	if p.raw == nil {
+		return nil
+	}
+	return p.raw.Close()
+}
+
+func newDecoder(coding string, encoded io.Reader) (io.ReadCloser, error) {
+	if coding != "deflate" {
+		return gzip.NewReader(encoded)
+	}
+	buffered := bufio.NewReader(encoded)
+	header, err := buffered.Peek(2)
+	if err != nil && len(header) == 0 {
+		return nil, err
+	}
+	if isZlibHeader(header) {
+		return zlib.NewReader(buffered)
+	}
+	return flate.NewReader(buffered), nil
+	// NOTE. Deflate. HTTP says deflate means zlib-wrapped deflate data,
+	// but some servers send raw deflate data instead, so we take both.
+}
+
+func isZlibHeader(header []byte) bool {
+	if len(header) < 2 {
+		return false
+	}
+	cmf, flg := uint16(header[0]), uint16(header[1])
+	return cmf&0x0f == 8 && (cmf<<8|flg)%31 == 0
+}
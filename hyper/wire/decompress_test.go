@@ -0,0 +1,150 @@
+package wire
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"testing"
+)
+
+func gzipped(data string) []byte {
+	buf := &bytes.Buffer{}
+	w := gzip.NewWriter(buf)
+	w.Write([]byte(data))
+	w.Close()
+	return buf.Bytes()
+}
+
+func zlibbed(data string) []byte {
+	buf := &bytes.Buffer{}
+	w := zlib.NewWriter(buf)
+	w.Write([]byte(data))
+	w.Close()
+	return buf.Bytes()
+}
+
+func deflated(data string) []byte {
+	buf := &bytes.Buffer{}
+	w, _ := flate.NewWriter(buf, flate.DefaultCompression)
+	w.Write([]byte(data))
+	w.Close()
+	return buf.Bytes()
+}
+
+type encodingServer struct {
+	acceptEncoding string
+	coding         string
+	body           []byte
+}
+
+func (p *encodingServer) send(req *http.Request) (*http.Response, error) {
+	p.acceptEncoding = req.Header.Get("Accept-Encoding")
+	res := &http.Response{
+		StatusCode:    200,
+		Header:        make(http.Header),
+		Body:          io.NopCloser(bytes.NewReader(p.body)),
+		ContentLength: int64(len(p.body)),
+	}
+	if p.coding != "" {
+		res.Header.Set("Content-Encoding", p.coding)
+	}
+	res.Header.Set("Content-Length", "1234")
+	res.Header.Set("Content-Type", "text/plain")
+	return res, nil
+}
+
+func TestDecompressResponse(t *testing.T) {
+	cases := []struct {
+		coding string
+		body   []byte
+	}{
+		{"gzip", gzipped("howzit!")},
+		{"X-Gzip", gzipped("howzit!")},
+		{"deflate", zlibbed("howzit!")},
+		{"deflate", deflated("howzit!")},
+		{"identity, gzip", gzipped("howzit!")},
+		{"deflate, gzip", gzipped(string(zlibbed("howzit!")))},
+		{"", []byte("howzit!")},
+	}
+	for _, k := range cases {
+		server := &encodingServer{coding: k.coding, body: k.body}
+		send := Chain(NewSender(server.send), Decompress())
+
+		res, err := send(getPath("/data", ""))
+		if err != nil {
+			t.Fatalf("[%s] want: response; got: %v", k.coding, err)
+		}
+		got, err := io.ReadAll(res.Body())
+		if err != nil || string(got) != "howzit!" {
+			t.Errorf("[%s] want: howzit!; got: %s, %v", k.coding, got, err)
+		}
+		res.Body().Close()
+
+		if server.acceptEncoding != "gzip, deflate" {
+			t.Errorf("[%s] want: gzip, deflate; got: %s",
+				k.coding, server.acceptEncoding)
+		}
+		if k.coding == "" {
+			continue
+		}
+		if res.Header("Content-Encoding") != "" || res.Header("content-length") != "" {
+			t.Errorf("[%s] want: no encoding headers; got: %v", k.coding, res.Headers())
+		}
+		if _, ok := res.Headers()["Content-Encoding"]; ok {
+			t.Errorf("[%s] want: no encoding headers; got: %v", k.coding, res.Headers())
+		}
+		if res.Header("Content-Type") != "text/plain" {
+			t.Errorf("[%s] want: content type; got: %v", k.coding, res.Headers())
+		}
+	}
+}
+
+func TestDecompressKeepsAcceptEncoding(t *testing.T) {
+	server := &encodingServer{}
+	send := Chain(NewSender(server.send), Decompress())
+	send(func(req RequestWriter) error {
+		if err := getPath("/data", "")(req); err != nil {
+			return err
+		}
+		return req.Header("Accept-Encoding", "gzip")
+	})
+
+	if server.acceptEncoding != "gzip" {
+		t.Errorf("want: gzip; got: %s", server.acceptEncoding)
+	}
+}
+
+func TestDecompressSkipsUnknownCoding(t *testing.T) {
+	server := &encodingServer{coding: "br", body: []byte("brotli")}
+	send := Chain(NewSender(server.send), Decompress())
+
+	res, _ := send(getPath("/data", ""))
+	got, _ := io.ReadAll(res.Body())
+	if string(got) != "brotli" || res.Header("Content-Encoding") != "br" {
+		t.Errorf("want: untouched response; got: %s, %v", got, res.Headers())
+	}
+}
+
+func TestDecompressEmptyBody(t *testing.T) {
+	server := &encodingServer{coding: "gzip"}
+	send := Chain(NewSender(server.send), Decompress())
+
+	res, _ := send(getPath("/data", ""))
+	got, err := io.ReadAll(res.Body())
+	if err != nil || len(got) != 0 {
+		t.Errorf("want: empty body; got: %s, %v", got, err)
+	}
+}
+
+func TestDecompressCorruptBody(t *testing.T) {
+	server := &encodingServer{coding: "gzip", body: []byte("not gzip")}
+	send := Chain(NewSender(server.send), Decompress())
+
+	res, _ := send(getPath("/data", ""))
+	if _, err := io.ReadAll(res.Body()); err == nil {
+		t.Errorf("want: error; got: nil")
+	}
+}
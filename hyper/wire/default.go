@@ -1,8 +1,10 @@
 package wire
 
 import (
+	"context"
 	"io"
 	"net/http"
+	"net/url"
 	"strconv"
 
 	"github.com/c0c0n3/resto/util/bytez"
@@ -14,13 +16,17 @@ import (
 type reqBuf struct {
 	method  string
 	url     string
+	query   url.Values
 	headers http.Header
 	body    io.ReadCloser
+	ctx     context.Context
 }
 
 func emptyReqBuf() *reqBuf {
 	return &reqBuf{
+		query:   make(url.Values),
 		headers: make(http.Header),
+		ctx:     context.Background(),
 	}
 }
 
@@ -29,6 +35,15 @@ func (p *reqBuf) Header(name string, content string) error {
 	return nil
 }
 
+func (p *reqBuf) AddHeader(name string, content string) error {
+	p.headers.Add(name, content)
+	return nil
+}
+
+func (p *reqBuf) PeekHeader(name string) string {
+	return p.headers.Get(name)
+}
+
 func (p *reqBuf) Body(content io.ReadCloser) error {
 	p.body = content
 	return nil
@@ -40,11 +55,32 @@ func (p *reqBuf) RequestLine(verb Method, resource yoorel.HttpUrl) error {
 	return nil
 }
 
+func (p *reqBuf) QueryParam(key string, value string) error {
+	p.query.Add(key, value)
+	return nil
+}
+
+func (p *reqBuf) Context(ctx context.Context) error {
+	if ctx != nil {
+		p.ctx = ctx
+	}
+	return nil
+}
+
 func (p *reqBuf) toHttpRequest() (*http.Request, error) {
-	req, err := http.NewRequest(p.method, p.url, p.body)
+	req, err := http.NewRequestWithContext(p.ctx, p.method, p.url, p.body)
 	if err != nil {
 		return nil, err
 	}
+	if len(p.query) > 0 {
+		query := req.URL.Query()
+		for key, values := range p.query {
+			for _, v := range values {
+				query.Add(key, v)
+			}
+		}
+		req.URL.RawQuery = query.Encode()
+	}
 	req.Header = p.headers
 	return req, nil
 }
@@ -55,6 +91,34 @@ type resReader struct {
 	res *http.Response
 }
 
+// Stop reading the body as soon as the request context is done.
+// The http package already does that for the body of a response it
+// receives from the network, but a StdLibSender could cook up its own
+// response---e.g. a test stub. So we make sure the body reader honours
+// the context regardless of where the response came from.
+type ctxBody struct {
+	ctx  context.Context
+	body io.ReadCloser
+}
+
+func (p *ctxBody) Read(buf []byte) (int, error) {
+	if err := p.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return p.body.Read(buf)
+}
+
+func (p *ctxBody) Close() error {
+	return p.body.Close()
+}
+
+func withContext(ctx context.Context, res *http.Response) *http.Response {
+	if ctx.Done() != nil && res.Body != nil {
+		res.Body = &ctxBody{ctx: ctx, body: res.Body}
+	}
+	return res
+}
+
 func (p *resReader) Header(name string) string {
 	return p.res.Header.Get(name)
 }
@@ -144,7 +208,7 @@ func sendRequest(build RequestBuilder, send StdLibSender) (ResponseReader, error
 	if err != nil {
 		return nil, err
 	}
-	return &resReader{response}, nil
+	return &resReader{withContext(buf.ctx, response)}, nil
 }
 
 // Build a Sender to make HTTP requests.
@@ -163,6 +227,9 @@ func sendRequest(build RequestBuilder, send StdLibSender) (ResponseReader, error
 //
 // where the given client is what the send function will use to make
 // an HTTP request and read the response each time it gets called.
+// If the RequestBuilder attaches a context to the request, the Sender
+// passes it on to the http package, so cancelling the context aborts
+// the request as well as reading the response body.
 // Also notice the Sender can actually exchange messages using any
 // function of type StdLibSender
 //
@@ -1,6 +1,8 @@
 package wire
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -215,3 +217,61 @@ func TestResReaderEmptyOnNilBody(t *testing.T) {
 		t.Errorf("want: empty body; got: %v", buf)
 	}
 }
+
+func TestSendRequestWithContext(t *testing.T) {
+	mock := &echoMock{}
+	send := NewSender(mock.send)
+	ctx, cancel := context.WithCancel(context.Background())
+	withCtx := func(req RequestWriter) error {
+		if err := postRequest(req); err != nil {
+			return err
+		}
+		return req.Context(ctx)
+	}
+	response, err := send(withCtx)
+
+	if err != nil {
+		t.Fatalf("want: response; got: %v", err)
+	}
+	if mock.capturedRequest.Context() != ctx {
+		t.Errorf("want: request context; got: %v", mock.capturedRequest.Context())
+	}
+
+	cancel()
+	_, err = io.ReadAll(response.Body())
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("want: canceled; got: %v", err)
+	}
+}
+
+func TestReqBufIgnoresNilContext(t *testing.T) {
+	buf := emptyReqBuf()
+	//lint:ignore SA1012 testing nil context handling
+	if err := buf.Context(nil); err != nil {
+		t.Errorf("want: no error; got: %v", err)
+	}
+	if buf.ctx == nil {
+		t.Errorf("want: background context; got: nil")
+	}
+}
+
+func TestSendRequestMergesQueryParams(t *testing.T) {
+	mock := &echoMock{}
+	send := NewSender(mock.send)
+	withQuery := func(req RequestWriter) error {
+		req.QueryParam("page", "2")
+		url := yoorel.BuilderFrom("http://nowhere/data?sort=asc").Build().Right()
+		if err := req.RequestLine(GET, url); err != nil {
+			return err
+		}
+		return req.QueryParam("tag", "a b")
+	}
+	if _, err := send(withQuery); err != nil {
+		t.Fatalf("want: response; got: %v", err)
+	}
+
+	want := "page=2&sort=asc&tag=a+b"
+	if got := mock.capturedRequest.URL.RawQuery; got != want {
+		t.Errorf("want: %s; got: %s", want, got)
+	}
+}
@@ -31,9 +31,27 @@ Exchanging messages
 The Sender and RequestHandler function types encapsulate the sending
 of a request to a server and the server-side processing of it, respectively.
 You can implement your own functions or get a default implementation
-backed by the "net/http" package from the standard library. (At the
-moment, there's a fully-fledged Sender implementation you get through
-NewSender, but there's no server-side RequestHandler implementation.)
+backed by the "net/http" package from the standard library. On the
+client side, NewSender gives you a fully-fledged Sender. On the server
+side, NewHttpHandler turns your RequestHandler into an http.Handler
+you can plug into the standard lib's HTTP server.
+
+
+Decorating a Sender
+
+A Middleware is a function that takes a Sender and returns a new one
+with some extra functionality, e.g. logging, retries or authentication.
+You can layer as many as you like on top of a base Sender with Chain
+
+    send := Chain(
+        NewSender[DefaultClient](),
+        logging, Retry(DefaultRetryPolicy()), auth,
+    )
+
+where logging sees the request first and auth sees it last, just before
+the base Sender puts it on the wire. If a Middleware needs to look at
+or change the outgoing request, it can record it with a RequestRecorder.
+Intercept makes that easy, see the example there.
 
 
 Example - POSTing to HttpBin
@@ -0,0 +1,21 @@
+package wire
+
+import (
+	"github.com/c0c0n3/resto/util/err"
+)
+
+// An attempt to write a message part that can't be written anymore
+// because what comes after it has already gone out on the wire.
+type AlreadyWritten string
+
+func headerAfterBodyErr(name string) err.Err[AlreadyWritten] {
+	return err.Mk[AlreadyWritten]("can't write header %s after body", name)
+}
+
+func statusLineAfterBodyErr() err.Err[AlreadyWritten] {
+	return err.Mk[AlreadyWritten]("can't write status line after body")
+}
+
+func bodyAlreadyWrittenErr() err.Err[AlreadyWritten] {
+	return err.Mk[AlreadyWritten]("body already written")
+}
@@ -26,3 +26,14 @@ type RequestHandler func(req RequestReader, res ResponseWriter) error
 // and returns a ResponseReader to read the server's response or an error
 // if something goes wrong.
 type Sender func(RequestBuilder) (ResponseReader, error)
+
+// A function to write an HTTP response.
+// The implementation uses the given ResponseWriter to do that and returns
+// an error if something goes wrong.
+type ResponseBuilder func(res ResponseWriter) error
+
+// A function to read an HTTP request on the server side.
+// The implementation reads (part of) the client's request from the given
+// RequestReader, checking it's what the server expects, and returns an
+// error if something goes wrong or the request isn't acceptable.
+type RequestMatcher func(req RequestReader) error
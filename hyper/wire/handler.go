@@ -0,0 +1,183 @@
+package wire
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/c0c0n3/resto/util/bytez"
+)
+
+// Request reading. It implements RequestReader by sourcing data from
+// http.Request.
+type reqReader struct {
+	req *http.Request
+}
+
+func (p *reqReader) Header(name string) string {
+	return p.req.Header.Get(name)
+}
+
+func (p *reqReader) Headers() map[string][]string {
+	return p.req.Header
+}
+
+func (p *reqReader) Body() io.ReadCloser {
+	if p.req.Body == nil {
+		return bytez.NewBuffer()
+	}
+	return p.req.Body
+}
+
+func (p *reqReader) RequestLine() (verb Method, path string) {
+	return Method(p.req.Method), p.req.URL.RequestURI()
+}
+
+// Response writing. It implements ResponseWriter by sinking data into
+// http.ResponseWriter.
+//
+// The http package wants headers first, then the status line and then
+// the body, whereas ResponseWriter lets you write headers and status
+// line in any order. So we hold on to the status code until we've got
+// to write the body or the RequestHandler returns, whichever comes first.
+// After that, headers and status line can't be written anymore.
+type resWriter struct {
+	out       http.ResponseWriter
+	code      StatusCode
+	committed bool
+	bodySent  bool
+}
+
+func newResWriter(out http.ResponseWriter) *resWriter {
+	return &resWriter{
+		out:  out,
+		code: StatusCode(http.StatusOK),
+	}
+}
+
+func (p *resWriter) Header(name string, content string) error {
+	if p.committed {
+		return headerAfterBodyErr(name)
+	}
+	p.out.Header().Set(name, content)
+	return nil
+}
+
+func (p *resWriter) AddHeader(name string, content string) error {
+	if p.committed {
+		return headerAfterBodyErr(name)
+	}
+	p.out.Header().Add(name, content)
+	return nil
+}
+
+func (p *resWriter) PeekHeader(name string) string {
+	return p.out.Header().Get(name)
+}
+
+func (p *resWriter) StatusLine(code StatusCode, reason string) error {
+	if p.committed {
+		return statusLineAfterBodyErr()
+	}
+	p.code = code
+	return nil
+	// NOTE. The http package doesn't let you pick a reason phrase, it
+	// uses the standard one for the code.
+}
+
+func (p *resWriter) commit() {
+	if !p.committed {
+		p.out.WriteHeader(p.code.Value())
+		p.committed = true
+	}
+}
+
+func (p *resWriter) Body(content io.ReadCloser) error {
+	if p.bodySent {
+		return bodyAlreadyWrittenErr()
+	}
+	p.bodySent = true
+	if content == nil {
+		p.commit()
+		return nil
+	}
+	defer content.Close()
+
+	streaming := p.out.Header().Get("Content-Length") == ""
+	p.commit()
+
+	var sink io.Writer = p.out
+	if flusher, ok := p.out.(http.Flusher); ok && streaming {
+		sink = &flushWriter{p.out, flusher}
+	}
+	_, err := io.Copy(sink, content)
+	return err
+}
+
+// Flush each chunk of a streaming body as soon as it's been written
+// so the client gets the data straight away rather than when the
+// http package's buffer fills up.
+type flushWriter struct {
+	out     io.Writer
+	flusher http.Flusher
+}
+
+func (p *flushWriter) Write(chunk []byte) (int, error) {
+	n, err := p.out.Write(chunk)
+	p.flusher.Flush()
+	return n, err
+}
+
+func serveRequest(handle RequestHandler, w http.ResponseWriter, r *http.Request) {
+	if r.Body != nil {
+		defer r.Body.Close()
+	}
+
+	res := newResWriter(w)
+	if err := handle(&reqReader{r}, res); err != nil {
+		if !res.committed {
+			headers := w.Header()
+			for name := range headers {
+				delete(headers, name)
+			}
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+		// NOTE. Staged headers. They describe the response the handler
+		// meant to send, e.g. its "Content-Length", so they'd be wrong
+		// for the error response.
+	}
+	res.commit()
+}
+
+// Build an http.Handler to serve HTTP requests with the given RequestHandler.
+//
+// The returned http.Handler feeds the RequestHandler with a RequestReader
+// backed by the incoming http.Request and a ResponseWriter backed by the
+// http.ResponseWriter. So you can write server-side code with the same
+// interfaces you use on the client side and then plug it into the
+// standard lib's HTTP server. Here's an example
+//
+//     echo := func(req RequestReader, res ResponseWriter) error {
+//         if err := res.StatusLine(200, "OK"); err != nil {
+//             return err
+//         }
+//         return res.Body(req.Body())
+//     }
+//     http.Handle("/echo", NewHttpHandler(echo))
+//
+// The ResponseWriter lets you write headers and status line in any order,
+// but once you start writing the body, you can't write any more headers
+// or change the status line. If you don't write a status line, the client
+// gets a 200. If there's no "Content-Length" header when you write the
+// body, the body gets streamed and flushed as it gets written. If the
+// RequestHandler returns an error before writing the body, the client
+// gets a 500 with the error message in the body and none of the headers
+// the RequestHandler wrote, otherwise the error
+// gets dropped since there's no way to tell the client about it.
+// Either way, the request body gets closed when the RequestHandler
+// returns.
+func NewHttpHandler(handle RequestHandler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		serveRequest(handle, w, r)
+	})
+}
@@ -0,0 +1,228 @@
+package wire
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/c0c0n3/resto/util/bytez"
+	e "github.com/c0c0n3/resto/util/err"
+)
+
+type closeTracker struct {
+	io.Reader
+	closed bool
+}
+
+func (p *closeTracker) Close() error {
+	p.closed = true
+	return nil
+}
+
+func serve(handle RequestHandler, req *http.Request) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	NewHttpHandler(handle).ServeHTTP(rec, req)
+	return rec
+}
+
+func TestServeEcho(t *testing.T) {
+	echo := func(req RequestReader, res ResponseWriter) error {
+		verb, path := req.RequestLine()
+		if err := res.Header("X-Line", fmt.Sprintf("%s %s", verb, path)); err != nil {
+			return err
+		}
+		if err := res.Header("greeting", req.Header("greeting")); err != nil {
+			return err
+		}
+		return res.Body(req.Body())
+	}
+	req := httptest.NewRequest("POST", "/echo?x=1", strings.NewReader("*"))
+	req.Header.Set("greeting", "howzit!")
+	rec := serve(echo, req)
+
+	if rec.Code != 200 {
+		t.Errorf("want: 200; got: %d", rec.Code)
+	}
+	if got := rec.Header().Get("X-Line"); got != "POST /echo?x=1" {
+		t.Errorf("want: POST /echo?x=1; got: %s", got)
+	}
+	if got := rec.Header().Get("greeting"); got != "howzit!" {
+		t.Errorf("want: howzit!; got: %s", got)
+	}
+	if got := rec.Body.String(); got != "*" {
+		t.Errorf("want: *; got: %s", got)
+	}
+}
+
+func TestServeStatusLineAfterHeaders(t *testing.T) {
+	handle := func(req RequestReader, res ResponseWriter) error {
+		if err := res.Header("Content-Length", "1"); err != nil {
+			return err
+		}
+		if err := res.StatusLine(201, "Created"); err != nil {
+			return err
+		}
+		if err := res.Header("X-Late", "still in time"); err != nil {
+			return err
+		}
+		return res.Body(bytez.Reader([]byte{42}))
+	}
+	rec := serve(handle, httptest.NewRequest("GET", "/", nil))
+
+	if rec.Code != 201 {
+		t.Errorf("want: 201; got: %d", rec.Code)
+	}
+	if got := rec.Header().Get("X-Late"); got != "still in time" {
+		t.Errorf("want: still in time; got: %s", got)
+	}
+	if got := rec.Body.String(); got != "*" {
+		t.Errorf("want: *; got: %s", got)
+	}
+	if rec.Flushed {
+		t.Errorf("want: no flushing for non-streaming body")
+	}
+}
+
+func TestServeWriteAfterBodyErrors(t *testing.T) {
+	var headerErr, statusErr, bodyErr error
+	handle := func(req RequestReader, res ResponseWriter) error {
+		if err := res.Body(bytez.Reader([]byte{42})); err != nil {
+			return err
+		}
+		headerErr = res.Header("X-Late", "too late")
+		statusErr = res.StatusLine(500, "Oops")
+		bodyErr = res.Body(bytez.Reader([]byte{42}))
+		return nil
+	}
+	rec := serve(handle, httptest.NewRequest("GET", "/", nil))
+
+	if rec.Code != 200 {
+		t.Errorf("want: 200; got: %d", rec.Code)
+	}
+	for _, err := range []error{headerErr, statusErr, bodyErr} {
+		if _, ok := err.(e.Err[AlreadyWritten]); !ok {
+			t.Errorf("want: already written err; got: %v", err)
+		}
+	}
+	if got := rec.Header().Get("X-Late"); got != "" {
+		t.Errorf("want: no header; got: %s", got)
+	}
+}
+
+func TestServeStreamingBodyGetsFlushed(t *testing.T) {
+	content := &closeTracker{Reader: strings.NewReader("streamed")}
+	handle := func(req RequestReader, res ResponseWriter) error {
+		return res.Body(content)
+	}
+	rec := serve(handle, httptest.NewRequest("GET", "/", nil))
+
+	if !rec.Flushed {
+		t.Errorf("want: flushed; got: not flushed")
+	}
+	if got := rec.Body.String(); got != "streamed" {
+		t.Errorf("want: streamed; got: %s", got)
+	}
+	if !content.closed {
+		t.Errorf("want: closed response content; got: open")
+	}
+}
+
+func TestServeClosesRequestBody(t *testing.T) {
+	body := &closeTracker{Reader: strings.NewReader("ignored")}
+	handle := func(req RequestReader, res ResponseWriter) error {
+		return nil
+	}
+	req := httptest.NewRequest("PUT", "/", nil)
+	req.Body = body
+	rec := serve(handle, req)
+
+	if rec.Code != 200 {
+		t.Errorf("want: 200; got: %d", rec.Code)
+	}
+	if !body.closed {
+		t.Errorf("want: closed request body; got: open")
+	}
+}
+
+func TestServeHandlerErrorBeforeBody(t *testing.T) {
+	handle := func(req RequestReader, res ResponseWriter) error {
+		res.StatusLine(201, "Created")
+		return fmt.Errorf("boom!")
+	}
+	rec := serve(handle, httptest.NewRequest("GET", "/", nil))
+
+	if rec.Code != 500 {
+		t.Errorf("want: 500; got: %d", rec.Code)
+	}
+	if got := rec.Body.String(); got != "boom!\n" {
+		t.Errorf("want: boom!; got: %s", got)
+	}
+}
+
+func TestServeHandlerErrorDropsStagedHeaders(t *testing.T) {
+	handle := func(req RequestReader, res ResponseWriter) error {
+		res.Header("Content-Length", "1000")
+		res.Header("Content-Encoding", "gzip")
+		res.Header("ETag", `"v1"`)
+		return fmt.Errorf("boom!")
+	}
+	res := serve(handle, httptest.NewRequest("GET", "/", nil)).Result()
+
+	if res.StatusCode != 500 {
+		t.Errorf("want: 500; got: %d", res.StatusCode)
+	}
+	for _, name := range []string{"Content-Length", "Content-Encoding", "ETag"} {
+		if got := res.Header.Get(name); got != "" {
+			t.Errorf("want: no %s; got: %s", name, got)
+		}
+	}
+	if got := res.Header.Get("Content-Type"); !strings.HasPrefix(got, "text/plain") {
+		t.Errorf("want: text/plain; got: %s", got)
+	}
+}
+
+func TestServeHandlerErrorAfterBody(t *testing.T) {
+	handle := func(req RequestReader, res ResponseWriter) error {
+		res.Body(bytez.Reader([]byte{42}))
+		return fmt.Errorf("boom!")
+	}
+	rec := serve(handle, httptest.NewRequest("GET", "/", nil))
+
+	if rec.Code != 200 {
+		t.Errorf("want: 200; got: %d", rec.Code)
+	}
+	if got := rec.Body.String(); got != "*" {
+		t.Errorf("want: *; got: %s", got)
+	}
+}
+
+func TestReqReaderEmptyOnNilBody(t *testing.T) {
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Body = nil
+	reader := reqReader{req}
+
+	buf, err := io.ReadAll(reader.Body())
+	if err != nil {
+		t.Errorf("want: empty body; got: %v", err)
+	}
+	if len(buf) > 0 {
+		t.Errorf("want: empty body; got: %v", buf)
+	}
+}
+
+func TestServeRepeatedHeaders(t *testing.T) {
+	handle := func(req RequestReader, res ResponseWriter) error {
+		res.Header("Link", "</a>; rel=\"next\"")
+		return res.AddHeader("Link", "</b>; rel=\"last\"")
+	}
+	rec := serve(handle, httptest.NewRequest("GET", "/", nil))
+
+	want := []string{"</a>; rel=\"next\"", "</b>; rel=\"last\""}
+	if got := rec.Header()["Link"]; !reflect.DeepEqual(want, got) {
+		t.Errorf("want: %v; got: %v", want, got)
+	}
+}
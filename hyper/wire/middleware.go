@@ -0,0 +1,82 @@
+package wire
+
+// Middleware decorates a Sender with some extra functionality, e.g.
+// logging, retries, authentication and the like. The implementation
+// takes the Sender to decorate and returns a new Sender that typically
+// does some work before and/or after calling the Sender it wraps.
+type Middleware func(Sender) Sender
+
+// Chain decorates the given base Sender with the given Middleware.
+//
+// The first Middleware in the list is the outermost one, i.e. the one
+// that gets to see the request first and the response last. So
+//
+//     send := Chain(base, logging, retry, auth)
+//
+// is the same as
+//
+//     send := logging(retry(auth(base)))
+//
+// which means logging sees the request before it gets to retry and
+// auth, whereas auth sees it last, just before base sends it. The other
+// way around for the response. Nil Middleware gets skipped.
+func Chain(base Sender, middleware ...Middleware) Sender {
+	send := base
+	for k := len(middleware) - 1; k >= 0; k-- {
+		if middleware[k] != nil {
+			send = middleware[k](send)
+		}
+	}
+	return send
+}
+
+// RequestInterceptor inspects and possibly modifies the outgoing request
+// through the given RequestRecorder. It returns an error to stop the
+// request from being sent.
+type RequestInterceptor func(req *RequestRecorder) error
+
+// ResponseInterceptor inspects the server's response and returns the
+// ResponseReader to hand over to whoever sent the request. This can be
+// the same ResponseReader it got or a new one, e.g. to rewrite the body.
+// It returns an error to discard the response.
+type ResponseInterceptor func(res ResponseReader) (ResponseReader, error)
+
+// Intercept builds a Middleware out of a RequestInterceptor and a
+// ResponseInterceptor, either of which can be nil.
+//
+// The returned Middleware records the outgoing request, hands it over to
+// the RequestInterceptor and then sends whatever the interceptor left in
+// the RequestRecorder through the Sender it decorates. Then it passes the
+// response on to the ResponseInterceptor. If the request couldn't be sent,
+// the ResponseInterceptor doesn't get called. Example.
+//
+//     auth := Intercept(
+//         func(req *RequestRecorder) error {
+//             req.Headers.Set("Authorization", "Bearer t0k3n")
+//             return nil
+//         },
+//         nil,
+//     )
+//     send := Chain(NewSender[DefaultClient](), auth)
+//
+func Intercept(onRequest RequestInterceptor, onResponse ResponseInterceptor) Middleware {
+	return func(next Sender) Sender {
+		return func(build RequestBuilder) (ResponseReader, error) {
+			record, err := Record(build)
+			if err != nil {
+				return nil, err
+			}
+			if onRequest != nil {
+				if err := onRequest(record); err != nil {
+					return nil, err
+				}
+			}
+
+			res, err := next(record.Replay)
+			if err != nil || onResponse == nil {
+				return res, err
+			}
+			return onResponse(res)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package wire
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/c0c0n3/resto/util/bytez"
+)
+
+func tracer(name string, trace *[]string) Middleware {
+	return func(next Sender) Sender {
+		return func(build RequestBuilder) (ResponseReader, error) {
+			*trace = append(*trace, name+" in")
+			res, err := next(build)
+			*trace = append(*trace, name+" out")
+			return res, err
+		}
+	}
+}
+
+func TestChainOrder(t *testing.T) {
+	trace := []string{}
+	base := func(build RequestBuilder) (ResponseReader, error) {
+		trace = append(trace, "base")
+		return nil, nil
+	}
+	send := Chain(base, tracer("a", &trace), nil, tracer("b", &trace))
+	send(getNowhere)
+
+	want := "a in, b in, base, b out, a out"
+	if got := strings.Join(trace, ", "); got != want {
+		t.Errorf("want: %s; got: %s", want, got)
+	}
+}
+
+func TestChainNoMiddleware(t *testing.T) {
+	mock := &echoMock{}
+	send := Chain(NewSender(mock.send))
+	if _, err := send(postRequest); err != nil {
+		t.Errorf("want: response; got: %v", err)
+	}
+	if mock.capturedRequest == nil {
+		t.Errorf("want: request sent; got: nothing")
+	}
+}
+
+type rewrittenBody struct {
+	ResponseReader
+}
+
+func (p *rewrittenBody) Body() io.ReadCloser {
+	return bytez.NewBufferFrom([]byte("rewritten"))
+}
+
+func TestInterceptRequestAndResponse(t *testing.T) {
+	mock := &echoMock{}
+	onRequest := func(req *RequestRecorder) error {
+		if req.Method != POST {
+			t.Errorf("want: POST; got: %v", req.Method)
+		}
+		req.Headers.Set("greeting", "hola!")
+		req.Headers.Set("X-Extra", "extra")
+		return nil
+	}
+	onResponse := func(res ResponseReader) (ResponseReader, error) {
+		return &rewrittenBody{res}, nil
+	}
+	send := Chain(NewSender(mock.send), Intercept(onRequest, onResponse))
+	res, err := send(postRequest)
+
+	if err != nil {
+		t.Fatalf("want: response; got: %v", err)
+	}
+	if got := mock.capturedRequest.Header.Get("greeting"); got != "hola!" {
+		t.Errorf("want: hola!; got: %s", got)
+	}
+	if got := mock.capturedRequest.Header.Get("X-Extra"); got != "extra" {
+		t.Errorf("want: extra; got: %s", got)
+	}
+	if body, _ := io.ReadAll(res.Body()); string(body) != "rewritten" {
+		t.Errorf("want: rewritten; got: %s", body)
+	}
+}
+
+func TestInterceptRequestError(t *testing.T) {
+	mock := &echoMock{}
+	onRequest := func(req *RequestRecorder) error {
+		return fmt.Errorf("boom!")
+	}
+	send := Chain(NewSender(mock.send), Intercept(onRequest, nil))
+	_, err := send(postRequest)
+
+	if err == nil || err.Error() != "boom!" {
+		t.Errorf("want: boom!; got: %v", err)
+	}
+	if mock.capturedRequest != nil {
+		t.Errorf("want: no request sent; got: %v", mock.capturedRequest)
+	}
+}
+
+func TestInterceptSkipsResponseOnSendError(t *testing.T) {
+	mock := &echoMock{errorToReturn: fmt.Errorf("net err")}
+	called := false
+	onResponse := func(res ResponseReader) (ResponseReader, error) {
+		called = true
+		return res, nil
+	}
+	send := Chain(NewSender(mock.send), Intercept(nil, onResponse))
+	_, err := send(postRequest)
+
+	if err == nil {
+		t.Errorf("want: net err; got: nil")
+	}
+	if called {
+		t.Errorf("want: no response interception; got: called")
+	}
+}
+
+func TestInterceptBuilderError(t *testing.T) {
+	send := Chain(NewSender(func(*http.Request) (*http.Response, error) {
+		t.Errorf("want: no request sent")
+		return nil, nil
+	}), Intercept(nil, nil))
+	_, err := send(func(RequestWriter) error {
+		return fmt.Errorf("boom!")
+	})
+
+	if err == nil || err.Error() != "boom!" {
+		t.Errorf("want: boom!; got: %v", err)
+	}
+}
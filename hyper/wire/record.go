@@ -0,0 +1,195 @@
+package wire
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/c0c0n3/resto/util/bytez"
+	"github.com/c0c0n3/resto/yoorel"
+)
+
+// RequestRecorder is a RequestWriter that keeps hold of the request
+// parts written to it so you can inspect them, change them and then
+// replay them to another RequestWriter. Sender decorators use it to see
+// and modify the outgoing request.
+type RequestRecorder struct {
+	// The request method, empty if no request line got written.
+	Method Method
+	// The request URL, nil if no request line got written.
+	Url yoorel.HttpUrl
+	// The request headers.
+	Headers http.Header
+	// The request context.
+	Ctx context.Context
+
+	body      io.ReadCloser
+	bodyBytes []byte
+	query     url.Values
+	transfer  *bool
+}
+
+// Record runs the given RequestBuilder to write a request to a new
+// RequestRecorder.
+func Record(build RequestBuilder) (*RequestRecorder, error) {
+	record := &RequestRecorder{
+		Headers: make(http.Header),
+		Ctx:     context.Background(),
+		query:   make(url.Values),
+	}
+	if err := build(record); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+func (p *RequestRecorder) Header(name string, content string) error {
+	p.Headers.Set(name, content)
+	return nil
+}
+
+func (p *RequestRecorder) AddHeader(name string, content string) error {
+	p.Headers.Add(name, content)
+	return nil
+}
+
+func (p *RequestRecorder) PeekHeader(name string) string {
+	return p.Headers.Get(name)
+}
+
+func (p *RequestRecorder) Body(content io.ReadCloser) error {
+	p.body = content
+	p.bodyBytes = nil
+	return nil
+}
+
+func (p *RequestRecorder) BodyTransfer(streaming bool) error {
+	p.transfer = &streaming
+	return nil
+}
+
+func (p *RequestRecorder) RequestLine(verb Method, resource yoorel.HttpUrl) error {
+	p.Method = verb
+	p.Url = resource
+	return p.mergeQuery()
+}
+
+// QueryParam adds the given key-value pair to the query part of Url.
+// If there's no Url yet, the pair gets added as soon as RequestLine
+// writes one.
+func (p *RequestRecorder) QueryParam(key string, value string) error {
+	p.query.Add(key, value)
+	return p.mergeQuery()
+}
+
+func (p *RequestRecorder) mergeQuery() error {
+	if p.Url == nil || len(p.query) == 0 {
+		return nil
+	}
+	builder := yoorel.BuilderFrom(yoorel.ToURL(p.Url))
+	for key, values := range p.query {
+		for _, v := range values {
+			builder = builder.Query(key, v)
+		}
+	}
+	merged := builder.Build()
+	if !merged.IsRight() {
+		return merged.Left()
+	}
+	p.Url = merged.Right()
+	p.query = make(url.Values)
+	return nil
+}
+
+func (p *RequestRecorder) Context(ctx context.Context) error {
+	if ctx != nil {
+		p.Ctx = ctx
+	}
+	return nil
+}
+
+// BufferBody reads the body into memory so it can be replayed as many
+// times as you like. BufferBody only does that for bodies that were
+// already in memory to begin with. If the body got written through
+// BodyTransfer, e.g. by hyper.WriteBody, BufferBody goes by the streaming
+// flag. Otherwise it takes a body with a "Content-Length" header to be
+// in memory. BufferBody leaves streaming bodies alone and returns false
+// to say the body can only be sent once.
+func (p *RequestRecorder) BufferBody() (bool, error) {
+	if p.body == nil {
+		return true, nil
+	}
+	if p.streamingBody() {
+		return false, nil
+	}
+	defer p.body.Close()
+	data, err := io.ReadAll(p.body)
+	if err != nil {
+		return false, err
+	}
+	p.body = nil
+	p.bodyBytes = data
+	return true, nil
+}
+
+func (p *RequestRecorder) streamingBody() bool {
+	if p.transfer != nil {
+		return *p.transfer
+	}
+	return p.Headers.Get("Content-Length") == ""
+}
+
+// BodyBytes returns the body content BufferBody read into memory, if
+// any.
+func (p *RequestRecorder) BodyBytes() []byte {
+	return p.bodyBytes
+}
+
+// Replay writes the recorded request to the given RequestWriter. Replay
+// is a RequestBuilder, so you can pass it on to a Sender. Unless you call
+// BufferBody first, the body only gets replayed once.
+func (p *RequestRecorder) Replay(req RequestWriter) error {
+	if p.Url != nil {
+		if err := req.RequestLine(p.Method, p.Url); err != nil {
+			return err
+		}
+	}
+	for key, values := range p.query {
+		for _, v := range values {
+			if err := req.QueryParam(key, v); err != nil {
+				return err
+			}
+		}
+	}
+	for name, values := range p.Headers {
+		for k, v := range values {
+			write := req.AddHeader
+			if k == 0 {
+				write = req.Header
+			}
+			if err := write(name, v); err != nil {
+				return err
+			}
+		}
+	}
+	if err := req.Context(p.Ctx); err != nil {
+		return err
+	}
+	if p.bodyBytes != nil {
+		return replayBody(req, false, bytez.Reader(p.bodyBytes))
+	}
+	if p.body != nil {
+		return replayBody(req, p.streamingBody(), p.body)
+	}
+	return nil
+}
+
+func replayBody(req RequestWriter, streaming bool, body io.ReadCloser) error {
+	if transfer, ok := req.(BodyTransferWriter); ok {
+		if err := transfer.BodyTransfer(streaming); err != nil {
+			return err
+		}
+	}
+	return req.Body(body)
+}
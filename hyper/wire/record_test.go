@@ -0,0 +1,155 @@
+package wire
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/c0c0n3/resto/util/bytez"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	record, err := Record(postRequest)
+	if err != nil {
+		t.Fatalf("want: record; got: %v", err)
+	}
+	if record.Method != POST {
+		t.Errorf("want: POST; got: %v", record.Method)
+	}
+	if got := record.Url.WireFormat(); got != "https://httpbin.org:443/post" {
+		t.Errorf("want: https://httpbin.org:443/post; got: %s", got)
+	}
+	if got := record.Headers.Get("greeting"); got != "howzit!" {
+		t.Errorf("want: howzit!; got: %s", got)
+	}
+
+	mock := &echoMock{}
+	if _, err := NewSender(mock.send)(record.Replay); err != nil {
+		t.Fatalf("want: response; got: %v", err)
+	}
+	if got := mock.capturedRequest.Header.Get("greeting"); got != "howzit!" {
+		t.Errorf("want: howzit!; got: %s", got)
+	}
+	if body, _ := io.ReadAll(mock.capturedRequest.Body); string(body) != "*" {
+		t.Errorf("want: *; got: %s", body)
+	}
+}
+
+func TestBufferBodyReplaysManyTimes(t *testing.T) {
+	record, _ := Record(postRequestWithContentLen)
+	if ok, err := record.BufferBody(); !ok || err != nil {
+		t.Fatalf("want: buffered; got: %v, %v", ok, err)
+	}
+	for k := 0; k < 3; k++ {
+		mock := &echoMock{}
+		NewSender(mock.send)(record.Replay)
+		body, _ := io.ReadAll(mock.capturedRequest.Body)
+		if string(body) != string([]byte{1, 2, 3, 4, 5}) {
+			t.Errorf("[%d] want: 1..5; got: %v", k, body)
+		}
+	}
+	if len(record.BodyBytes()) != 5 {
+		t.Errorf("want: 5 bytes; got: %v", record.BodyBytes())
+	}
+}
+
+func TestBufferBodyLeavesStreamsAlone(t *testing.T) {
+	streaming := func(req RequestWriter) error {
+		return req.Body(io.NopCloser(strings.NewReader("streamed")))
+	}
+	record, _ := Record(streaming)
+	ok, err := record.BufferBody()
+
+	if ok || err != nil {
+		t.Errorf("want: not buffered; got: %v, %v", ok, err)
+	}
+	if record.BodyBytes() != nil {
+		t.Errorf("want: no bytes; got: %v", record.BodyBytes())
+	}
+}
+
+func TestBufferBodyWithNoBody(t *testing.T) {
+	record, _ := Record(getNowhere)
+	if ok, err := record.BufferBody(); !ok || err != nil {
+		t.Errorf("want: buffered; got: %v, %v", ok, err)
+	}
+}
+
+func TestReplayOverwritesBody(t *testing.T) {
+	record, _ := Record(postRequestWithContentLen)
+	record.BufferBody()
+	record.Body(bytez.NewBufferFrom([]byte("new")))
+
+	mock := &echoMock{}
+	NewSender(mock.send)(record.Replay)
+	if body, _ := io.ReadAll(mock.capturedRequest.Body); string(body) != "new" {
+		t.Errorf("want: new; got: %s", body)
+	}
+}
+
+func TestReplayRepeatedHeaders(t *testing.T) {
+	repeated := func(req RequestWriter) error {
+		if err := getNowhere(req); err != nil {
+			return err
+		}
+		req.Header("Via", "1.1 alpha")
+		return req.AddHeader("Via", "1.1 beta")
+	}
+	record, _ := Record(repeated)
+
+	mock := &echoMock{}
+	NewSender(mock.send)(record.Replay)
+	want := []string{"1.1 alpha", "1.1 beta"}
+	if got := mock.capturedRequest.Header["Via"]; !reflect.DeepEqual(want, got) {
+		t.Errorf("want: %v; got: %v", want, got)
+	}
+}
+
+func TestRecordQueryParams(t *testing.T) {
+	withQuery := func(req RequestWriter) error {
+		req.QueryParam("page", "2")
+		if err := getNowhere(req); err != nil {
+			return err
+		}
+		return req.QueryParam("tag", "a")
+	}
+	record, err := Record(withQuery)
+	if err != nil {
+		t.Fatalf("want: record; got: %v", err)
+	}
+	want := []string{"2"}
+	if got := record.Url.QueryValues("page"); !reflect.DeepEqual(want, got) {
+		t.Errorf("want: %v; got: %v", want, got)
+	}
+	want = []string{"a"}
+	if got := record.Url.QueryValues("tag"); !reflect.DeepEqual(want, got) {
+		t.Errorf("want: %v; got: %v", want, got)
+	}
+
+	mock := &echoMock{}
+	NewSender(mock.send)(record.Replay)
+	if got := mock.capturedRequest.URL.RawQuery; got != "page=2&tag=a" {
+		t.Errorf("want: page=2&tag=a; got: %s", got)
+	}
+}
+
+func TestReplayPendingQueryParams(t *testing.T) {
+	record, _ := Record(func(req RequestWriter) error {
+		return req.QueryParam("page", "2")
+	})
+	if record.Url != nil {
+		t.Errorf("want: no url; got: %v", record.Url)
+	}
+
+	mock := &echoMock{}
+	NewSender(mock.send)(func(req RequestWriter) error {
+		if err := getNowhere(req); err != nil {
+			return err
+		}
+		return record.Replay(req)
+	})
+	if got := mock.capturedRequest.URL.RawQuery; got != "page=2" {
+		t.Errorf("want: page=2; got: %s", got)
+	}
+}
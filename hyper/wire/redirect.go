@@ -0,0 +1,136 @@
+package wire
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/c0c0n3/resto/util/err"
+	"github.com/c0c0n3/resto/yoorel"
+)
+
+// Too many redirects to follow.
+type TooManyRedirects string
+
+func tooManyRedirectsErr(max int) err.Err[TooManyRedirects] {
+	return err.Mk[TooManyRedirects]("stopped after %d redirects", max)
+}
+
+// RedirectPolicy tells a Sender whether and how to follow redirects.
+type RedirectPolicy struct {
+	// Follow at most this many redirects in a row, then give up with an
+	// error. Zero or less means don't follow redirects at all but return
+	// the 3xx response as is, so response handlers get to see it.
+	MaxRedirects int
+	// Drop the "Authorization" header when a redirect goes to a host
+	// other than that of the original request, so credentials don't
+	// leak to third parties.
+	StripAuthorization bool
+}
+
+// DefaultRedirectPolicy returns a RedirectPolicy to follow at most 10
+// redirects, stripping the "Authorization" header on cross-host ones.
+func DefaultRedirectPolicy() RedirectPolicy {
+	return RedirectPolicy{
+		MaxRedirects:       10,
+		StripAuthorization: true,
+	}
+}
+
+func (p RedirectPolicy) checkRedirect(req *http.Request, via []*http.Request) error {
+	if p.MaxRedirects <= 0 {
+		return http.ErrUseLastResponse
+	}
+	if len(via) > p.MaxRedirects {
+		return tooManyRedirectsErr(p.MaxRedirects)
+	}
+	if p.StripAuthorization && len(via) > 0 &&
+		!strings.EqualFold(hostAndPort(req.URL), hostAndPort(via[0].URL)) {
+		req.Header.Del("Authorization")
+	}
+	return nil
+	// NOTE. Header copies. On each redirect, http.Client copies the
+	// headers of the original request into the new one, so comparing
+	// hosts with the original request is enough to strip the header
+	// from any cross-host hop, even after a redirect back to the
+	// original host.
+}
+
+// hostAndPort returns the URL host with an explicit port, so e.g.
+// "http://my.api" and "http://my.api:80" have the same host.
+func hostAndPort(u *url.URL) string {
+	port := u.Port()
+	if port == "" {
+		port = strconv.Itoa(yoorel.DefaultPort(yoorel.Scheme(u.Scheme)))
+	}
+	return net.JoinHostPort(u.Hostname(), port)
+}
+
+// NewRedirectSender builds a Sender like NewSender does, but following
+// redirects according to the given policy. Example.
+//
+//     policy := DefaultRedirectPolicy()
+//     policy.MaxRedirects = 3
+//     send := NewRedirectSender(policy)
+//
+// If you pass in an http.Client, the Sender uses a copy of it with the
+// redirect policy slotted in, otherwise a copy of http.DefaultClient.
+// Either way, the client you pass in stays the same.
+func NewRedirectSender(policy RedirectPolicy, client ...*http.Client) Sender {
+	base := http.DefaultClient
+	if len(client) > 0 && client[0] != nil {
+		base = client[0]
+	}
+	c := *base
+	c.CheckRedirect = policy.checkRedirect
+	return NewSender(&c)
+}
+
+// RedirectReader is a ResponseReader that knows which redirects the
+// request went through to get to the response. The ResponseReaders
+// Senders built with NewSender or NewRedirectSender return implement
+// this interface.
+type RedirectReader interface {
+	ResponseReader
+	// The URL of each request sent, starting with the original one and
+	// followed by the target of each redirect, in order. So the last
+	// URL is the one of the response. Empty if unknown.
+	RedirectHistory() []*url.URL
+}
+
+func (p *resReader) RedirectHistory() []*url.URL {
+	history := []*url.URL{}
+	for req := p.res.Request; req != nil; {
+		history = append([]*url.URL{req.URL}, history...)
+		if req.Response == nil {
+			break
+		}
+		req = req.Response.Request
+	}
+	return history
+	// NOTE. Redirect chain. http.Client links each redirect request to
+	// the response that caused it, which in turn links to the request
+	// it replied to, all the way back to the original request.
+}
+
+// RedirectHistory returns the URL of each request sent to get the given
+// response, starting with the original one and followed by the target of
+// each redirect. The last URL is the one of the response. If res doesn't
+// implement RedirectReader, RedirectHistory looks for one by unwrapping
+// res through any "Unwrap() ResponseReader" method. If there's none,
+// RedirectHistory returns nil.
+func RedirectHistory(res ResponseReader) []*url.URL {
+	for res != nil {
+		if reader, ok := res.(RedirectReader); ok {
+			return reader.RedirectHistory()
+		}
+		wrapper, ok := res.(interface{ Unwrap() ResponseReader })
+		if !ok {
+			return nil
+		}
+		res = wrapper.Unwrap()
+	}
+	return nil
+}
@@ -0,0 +1,192 @@
+package wire
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/c0c0n3/resto/util/err"
+	"github.com/c0c0n3/resto/yoorel"
+)
+
+// redirectServer redirects /hop/n to /hop/n-1 and then /hop/0 to
+// whatever target is, echoing the "Authorization" header it gets.
+func redirectServer(target *string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Auth", r.Header.Get("Authorization"))
+			switch r.URL.Path {
+			case "/hop/3":
+				http.Redirect(w, r, "/hop/2", http.StatusFound)
+			case "/hop/2":
+				http.Redirect(w, r, "/hop/1", http.StatusFound)
+			case "/hop/1":
+				http.Redirect(w, r, "/hop/0", http.StatusFound)
+			case "/hop/0":
+				http.Redirect(w, r, *target, http.StatusFound)
+			}
+		}))
+}
+
+func getWithAuth(target string) RequestBuilder {
+	return func(req RequestWriter) error {
+		url := yoorel.BuilderFrom(target).Build().Right()
+		if err := req.RequestLine(GET, url); err != nil {
+			return err
+		}
+		return req.Header("Authorization", "Bearer t0k3n")
+	}
+}
+
+func historyPaths(res ResponseReader) string {
+	paths := []string{}
+	for _, u := range RedirectHistory(res) {
+		paths = append(paths, u.Path)
+	}
+	return strings.Join(paths, " ")
+}
+
+func TestFollowRedirectsAndRecordHistory(t *testing.T) {
+	target := "/done"
+	server := redirectServer(&target)
+	defer server.Close()
+
+	send := NewRedirectSender(DefaultRedirectPolicy())
+	res, err := send(getWithAuth(server.URL + "/hop/2"))
+	if err != nil {
+		t.Fatalf("want: response; got: %v", err)
+	}
+
+	if code, _ := res.StatusLine(); code != 200 {
+		t.Errorf("want: 200; got: %d", code)
+	}
+	if got := historyPaths(res); got != "/hop/2 /hop/1 /hop/0 /done" {
+		t.Errorf("want: full history; got: %s", got)
+	}
+	if got := res.Header("X-Auth"); got != "Bearer t0k3n" {
+		t.Errorf("want: same-host auth kept; got: %s", got)
+	}
+}
+
+func TestDisableRedirects(t *testing.T) {
+	target := "/done"
+	server := redirectServer(&target)
+	defer server.Close()
+
+	send := NewRedirectSender(RedirectPolicy{})
+	res, err := send(getWithAuth(server.URL + "/hop/1"))
+	if err != nil {
+		t.Fatalf("want: response; got: %v", err)
+	}
+
+	if code, _ := res.StatusLine(); code != 302 {
+		t.Errorf("want: 302; got: %d", code)
+	}
+	if got := res.Header("Location"); got != "/hop/0" {
+		t.Errorf("want: location /hop/0; got: %s", got)
+	}
+	if got := historyPaths(res); got != "/hop/1" {
+		t.Errorf("want: no redirects; got: %s", got)
+	}
+}
+
+func TestTooManyRedirects(t *testing.T) {
+	target := "/done"
+	server := redirectServer(&target)
+	defer server.Close()
+
+	send := NewRedirectSender(RedirectPolicy{MaxRedirects: 2})
+	_, got := send(getWithAuth(server.URL + "/hop/3"))
+
+	var want err.Err[TooManyRedirects]
+	if !errors.As(got, &want) {
+		t.Errorf("want: too many redirects err; got: %v", got)
+	}
+}
+
+// subdomainRedirect redirects from example.com to api.example.com, a
+// redirect after which http.Client itself keeps the "Authorization"
+// header, echoing the header it gets.
+type subdomainRedirect struct{}
+
+func (subdomainRedirect) RoundTrip(req *http.Request) (*http.Response, error) {
+	res := &http.Response{
+		StatusCode: 200,
+		Header:     http.Header{"X-Auth": {req.Header.Get("Authorization")}},
+		Body:       http.NoBody,
+		Request:    req,
+	}
+	if req.URL.Hostname() == "example.com" {
+		res.StatusCode = 302
+		res.Header.Set("Location", "http://api.example.com/data")
+	}
+	return res, nil
+}
+
+func TestStripAuthorizationOnCrossHostRedirect(t *testing.T) {
+	client := &http.Client{Transport: subdomainRedirect{}}
+	for _, strip := range []bool{true, false} {
+		policy := DefaultRedirectPolicy()
+		policy.StripAuthorization = strip
+		send := NewRedirectSender(policy, client)
+		res, err := send(getWithAuth("http://example.com/data"))
+		if err != nil {
+			t.Fatalf("want: response; got: %v", err)
+		}
+		got := res.Header("X-Auth")
+		if strip && got != "" || !strip && got != "Bearer t0k3n" {
+			t.Errorf("[strip=%v] got auth: %s", strip, got)
+		}
+	}
+}
+
+func TestNewRedirectSenderLeavesClientAlone(t *testing.T) {
+	client := &http.Client{}
+	NewRedirectSender(RedirectPolicy{}, client)
+	if client.CheckRedirect != nil {
+		t.Errorf("want: client unchanged")
+	}
+}
+
+func TestHostAndPort(t *testing.T) {
+	for _, c := range [][]string{
+		{"http://my.api", "my.api:80"},
+		{"https://my.api/x", "my.api:443"},
+		{"http://my.api:8080", "my.api:8080"},
+	} {
+		u, _ := url.Parse(c[0])
+		if got := hostAndPort(u); got != c[1] {
+			t.Errorf("want: %s; got: %s", c[1], got)
+		}
+	}
+}
+
+type wrappedReader struct {
+	ResponseReader
+}
+
+func (p wrappedReader) Unwrap() ResponseReader {
+	return p.ResponseReader
+}
+
+func TestRedirectHistoryUnwrap(t *testing.T) {
+	mock := &echoMock{}
+	res, _ := NewSender(mock.send)(getNowhere)
+	if got := RedirectHistory(wrappedReader{res}); len(got) != 0 {
+		t.Errorf("want: empty history for stub response; got: %v", got)
+	}
+
+	target := "/done"
+	server := redirectServer(&target)
+	defer server.Close()
+	res, _ = NewSender[DefaultClient]()(getWithAuth(server.URL + "/hop/0"))
+	if got := historyPaths(wrappedReader{wrappedReader{res}}); got != "/hop/0 /done" {
+		t.Errorf("want: history through wrappers; got: %s", got)
+	}
+	if got := RedirectHistory(nil); got != nil {
+		t.Errorf("want: nil; got: %v", got)
+	}
+}
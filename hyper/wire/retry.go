@@ -0,0 +1,251 @@
+package wire
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/c0c0n3/resto/util/set"
+)
+
+// RetryPolicy tells the Retry decorator which requests to retry, when
+// and how many times.
+type RetryPolicy struct {
+	// How many times to send the request at most, including the first
+	// attempt. Any value less than 2 means no retries.
+	MaxAttempts int
+	// Only retry requests with these methods. Typically you'd only want
+	// to list idempotent methods here.
+	Methods []Method
+	// Retry if the server replies with one of these status codes.
+	// Connection errors always trigger a retry.
+	StatusCodes []StatusCode
+	// How long to wait before the first retry. Each retry after that
+	// waits twice as long as the previous one, plus or minus some
+	// random jitter. Zero means retry straight away, every time, unless
+	// the server asks us to wait through a "Retry-After" header.
+	BaseDelay time.Duration
+	// Never wait longer than this between retries. If the server asks
+	// us to wait longer than this through a "Retry-After" header, we
+	// give up and return the server's response.
+	MaxDelay time.Duration
+}
+
+// DefaultRetryPolicy returns a RetryPolicy to send GET, HEAD, PUT and
+// DELETE requests at most three times if there's a connection error or
+// the server replies with a 429, 502, 503 or 504. The first retry
+// happens after about 100ms and the wait between retries never goes
+// over 10s.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 3,
+		Methods:     []Method{GET, HEAD, PUT, DELETE},
+		StatusCodes: []StatusCode{429, 502, 503, 504},
+		BaseDelay:   100 * time.Millisecond,
+		MaxDelay:    10 * time.Second,
+	}
+}
+
+type retrier struct {
+	policy  RetryPolicy
+	methods set.Set[Method]
+	codes   set.Set[StatusCode]
+	next    Sender
+	sleep   func(ctx context.Context, d time.Duration) error
+}
+
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func newRetrier(policy RetryPolicy, next Sender) *retrier {
+	return &retrier{
+		policy:  policy,
+		methods: set.From(policy.Methods...),
+		codes:   set.From(policy.StatusCodes...),
+		next:    next,
+		sleep:   sleepCtx,
+	}
+}
+
+// IsConnectionError tells if the given error is a transient transport
+// error, i.e. one that might go away if you send the request again.
+// Those are the errors you get if the connection can't be made, times
+// out, gets reset or closed before the whole response comes in. Errors
+// that will happen again no matter what, e.g. an unsupported URL scheme,
+// an invalid server certificate or too many redirects, aren't connection
+// errors.
+func IsConnectionError(err error) bool {
+	if err == nil || isCertificateError(err) {
+		return false
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		return true
+	}
+	var errno syscall.Errno
+	if errors.As(err, &errno) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+	// NOTE. http.Client wraps any error that happens while exchanging
+	// messages in a url.Error. Since url.Error is a net.Error, we can't
+	// just check for that---it'd match any error, including those the
+	// request builders return. So we look at what url.Error wraps.
+}
+
+func isCertificateError(err error) bool {
+	var authorityErr x509.UnknownAuthorityError
+	var invalidErr x509.CertificateInvalidError
+	var hostnameErr x509.HostnameError
+	return errors.As(err, &authorityErr) ||
+		errors.As(err, &invalidErr) ||
+		errors.As(err, &hostnameErr)
+}
+
+func (p *retrier) shouldRetry(ctx context.Context, res ResponseReader, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if err != nil {
+		return IsConnectionError(err)
+	}
+	code, _ := res.StatusLine()
+	return p.codes.Member(code)
+}
+
+// The "Retry-After" header holds either a number of seconds or a date.
+func retryAfter(res ResponseReader) (time.Duration, bool) {
+	if res == nil {
+		return 0, false
+	}
+	value := strings.TrimSpace(res.Header("Retry-After"))
+	if value == "" {
+		return 0, false
+	}
+	if secs, err := strconv.ParseUint(value, 10, 32); err == nil {
+		return time.Duration(secs) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		wait := time.Until(date)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+	return 0, false
+}
+
+// Exponential backoff with "equal jitter": wait at least half of the
+// exponential delay and at most the full delay.
+func (p *retrier) backoff(attempt int) time.Duration {
+	if p.policy.BaseDelay <= 0 {
+		return 0
+	}
+	delay := p.policy.MaxDelay
+	if attempt < 32 {
+		if exp := p.policy.BaseDelay << (attempt - 1); exp > 0 && exp < delay {
+			delay = exp
+		}
+	}
+	half := delay / 2
+	if half <= 0 {
+		return delay
+	}
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+func discard(res ResponseReader) {
+	if res != nil {
+		body := res.Body()
+		io.Copy(io.Discard, io.LimitReader(body, 64*1024))
+		body.Close()
+	}
+	// NOTE. Draining the body lets the http package reuse the connection.
+	// But we don't want to read a huge body just for that, so we cap it.
+}
+
+func (p *retrier) send(build RequestBuilder) (ResponseReader, error) {
+	record, err := Record(build)
+	if err != nil {
+		return nil, err
+	}
+	if p.policy.MaxAttempts < 2 || !p.methods.Member(record.Method) {
+		return p.next(record.Replay)
+	}
+	if replayable, err := record.BufferBody(); err != nil {
+		return nil, err
+	} else if !replayable {
+		return p.next(record.Replay)
+	}
+
+	for attempt := 1; ; attempt++ {
+		res, err := p.next(record.Replay)
+		if attempt >= p.policy.MaxAttempts ||
+			!p.shouldRetry(record.Ctx, res, err) {
+			return res, err
+		}
+
+		delay, present := retryAfter(res)
+		if !present {
+			delay = p.backoff(attempt)
+		} else if delay > p.policy.MaxDelay {
+			return res, err
+		}
+		discard(res)
+		if err := p.sleep(record.Ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Retry builds a Sender decorator to retry requests according to the
+// given RetryPolicy.
+//
+// The decorated Sender sends the request through the Sender it wraps
+// and, if there's a connection error or the server replies with one of
+// the status codes listed in the policy, it waits a bit and sends the
+// request again until it either succeeds or runs out of attempts. The
+// wait between attempts grows exponentially, but if the server replies
+// with a "Retry-After" header, the Sender waits as long as the server
+// says. If the request has a context, the Sender stops retrying as soon
+// as the context is done.
+//
+// Only requests with one of the methods listed in the policy get retried.
+// Also, the request body must be replayable, so streaming bodies, e.g.
+// hyper.StreamingBody, never get retried, not even if you give them a
+// "Content-Length" header. Non-streaming bodies like those you get out
+// of hyper.ByteBody, hyper.StringBody or hyper.JsonBody are fine though.
+// (Bodies written straight to the RequestWriter, without hyper.WriteBody,
+// count as streaming unless they come with a "Content-Length" header.)
+// See IsConnectionError about which errors trigger a retry.
+//
+// Example.
+//
+//     send := Chain(NewSender[DefaultClient](), Retry(DefaultRetryPolicy()))
+//     client := client.New(send)
+//
+func Retry(policy RetryPolicy) Middleware {
+	return func(next Sender) Sender {
+		return newRetrier(policy, next).send
+	}
+}
@@ -0,0 +1,393 @@
+package wire
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/c0c0n3/resto/util/bytez"
+	"github.com/c0c0n3/resto/yoorel"
+)
+
+type scriptedServer struct {
+	replies    []any // either an error or a status code
+	retryAfter string
+	bodies     []string
+}
+
+func (p *scriptedServer) send(req *http.Request) (*http.Response, error) {
+	body := ""
+	if req.Body != nil {
+		data, _ := io.ReadAll(req.Body)
+		body = string(data)
+	}
+	p.bodies = append(p.bodies, body)
+
+	reply := p.replies[0]
+	if len(p.replies) > 1 {
+		p.replies = p.replies[1:]
+	}
+	if err, ok := reply.(error); ok {
+		return nil, err
+	}
+	res := &http.Response{
+		StatusCode: reply.(int),
+		Header:     make(http.Header),
+	}
+	if p.retryAfter != "" {
+		res.Header.Set("Retry-After", p.retryAfter)
+	}
+	return res, nil
+}
+
+func (p *scriptedServer) calls() int {
+	return len(p.bodies)
+}
+
+type sleepRecorder struct {
+	delays []time.Duration
+}
+
+func (p *sleepRecorder) sleep(ctx context.Context, d time.Duration) error {
+	p.delays = append(p.delays, d)
+	return ctx.Err()
+}
+
+func newTestRetrier(server *scriptedServer) (*retrier, *sleepRecorder) {
+	sleeper := &sleepRecorder{}
+	r := newRetrier(DefaultRetryPolicy(), NewSender(server.send))
+	r.sleep = sleeper.sleep
+	return r, sleeper
+}
+
+func request(verb Method, body io.ReadCloser, length string) RequestBuilder {
+	return func(req RequestWriter) error {
+		url := yoorel.BuilderFrom("http://nowhere/").Build().Right()
+		if err := req.RequestLine(verb, url); err != nil {
+			return err
+		}
+		if length != "" {
+			if err := req.Header("Content-Length", length); err != nil {
+				return err
+			}
+		}
+		if body != nil {
+			return req.Body(body)
+		}
+		return nil
+	}
+}
+
+func connErr() error {
+	return &url.Error{Op: "Get", URL: "http://nowhere/", Err: io.ErrUnexpectedEOF}
+}
+
+func assertCode(t *testing.T, res ResponseReader, want int) {
+	if res == nil {
+		t.Fatalf("want: %d; got: nil response", want)
+	}
+	if code, _ := res.StatusLine(); code.Value() != want {
+		t.Errorf("want: %d; got: %d", want, code)
+	}
+}
+
+func TestRetryUntilSuccess(t *testing.T) {
+	server := &scriptedServer{replies: []any{503, connErr(), 200}}
+	r, sleeper := newTestRetrier(server)
+	res, err := r.send(request(GET, nil, ""))
+
+	if err != nil {
+		t.Fatalf("want: response; got: %v", err)
+	}
+	assertCode(t, res, 200)
+	if server.calls() != 3 {
+		t.Errorf("want: 3 calls; got: %d", server.calls())
+	}
+	if len(sleeper.delays) != 2 {
+		t.Errorf("want: 2 delays; got: %v", sleeper.delays)
+	}
+}
+
+func TestRetryGiveUpAfterMaxAttempts(t *testing.T) {
+	server := &scriptedServer{replies: []any{429}}
+	r, _ := newTestRetrier(server)
+	res, err := r.send(request(DELETE, nil, ""))
+
+	if err != nil {
+		t.Fatalf("want: response; got: %v", err)
+	}
+	assertCode(t, res, 429)
+	if server.calls() != 3 {
+		t.Errorf("want: 3 calls; got: %d", server.calls())
+	}
+}
+
+func TestRetryReturnLastConnectionError(t *testing.T) {
+	server := &scriptedServer{replies: []any{connErr()}}
+	r, _ := newTestRetrier(server)
+	_, err := r.send(request(GET, nil, ""))
+
+	if _, ok := err.(*url.Error); !ok {
+		t.Errorf("want: url error; got: %v", err)
+	}
+	if server.calls() != 3 {
+		t.Errorf("want: 3 calls; got: %d", server.calls())
+	}
+}
+
+func TestNoRetryOnOtherStatusCodesOrErrors(t *testing.T) {
+	replies := []any{500, 404, fmt.Errorf("not a connection error")}
+	for _, reply := range replies {
+		server := &scriptedServer{replies: []any{reply, 200}}
+		r, _ := newTestRetrier(server)
+		r.send(request(GET, nil, ""))
+
+		if server.calls() != 1 {
+			t.Errorf("[%v] want: 1 call; got: %d", reply, server.calls())
+		}
+	}
+}
+
+func TestNoRetryOnPermanentTransportErrors(t *testing.T) {
+	wrap := func(err error) error {
+		return &url.Error{Op: "Get", URL: "http://nowhere/", Err: err}
+	}
+	replies := []any{
+		wrap(errors.New(`unsupported protocol scheme "ftp"`)),
+		wrap(errors.New("stopped after 10 redirects")),
+		wrap(x509.UnknownAuthorityError{}),
+		wrap(x509.HostnameError{Host: "nowhere"}),
+	}
+	for _, reply := range replies {
+		server := &scriptedServer{replies: []any{reply, 200}}
+		r, _ := newTestRetrier(server)
+		r.send(request(GET, nil, ""))
+
+		if server.calls() != 1 {
+			t.Errorf("[%v] want: 1 call; got: %d", reply, server.calls())
+		}
+	}
+}
+
+func TestIsConnectionError(t *testing.T) {
+	wrap := func(err error) error {
+		return &url.Error{Op: "Get", URL: "http://nowhere/", Err: err}
+	}
+	dialErr := &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}
+	errs := []error{
+		wrap(dialErr),
+		wrap(syscall.ECONNRESET),
+		wrap(io.EOF),
+		wrap(context.DeadlineExceeded),
+	}
+	for k, err := range errs {
+		if !IsConnectionError(err) {
+			t.Errorf("[%d] want: connection error; got: %v", k, err)
+		}
+	}
+	if IsConnectionError(nil) {
+		t.Errorf("want: nil isn't a connection error")
+	}
+}
+
+func TestNoRetryForNonIdempotentMethods(t *testing.T) {
+	for _, verb := range []Method{POST, PATCH} {
+		server := &scriptedServer{replies: []any{503, 200}}
+		r, _ := newTestRetrier(server)
+		res, _ := r.send(request(verb, nil, ""))
+
+		assertCode(t, res, 503)
+		if server.calls() != 1 {
+			t.Errorf("[%v] want: 1 call; got: %d", verb, server.calls())
+		}
+	}
+}
+
+func TestRetryReplaysBufferedBody(t *testing.T) {
+	server := &scriptedServer{replies: []any{502, 504, 200}}
+	r, _ := newTestRetrier(server)
+	body := bytez.NewBufferFrom([]byte("howzit!"))
+	res, _ := r.send(request(PUT, body, "7"))
+
+	assertCode(t, res, 200)
+	if server.calls() != 3 {
+		t.Fatalf("want: 3 calls; got: %d", server.calls())
+	}
+	for k, got := range server.bodies {
+		if got != "howzit!" {
+			t.Errorf("[%d] want: howzit!; got: %s", k, got)
+		}
+	}
+}
+
+func TestNoRetryForStreamingBody(t *testing.T) {
+	server := &scriptedServer{replies: []any{503, 200}}
+	r, _ := newTestRetrier(server)
+	body := io.NopCloser(strings.NewReader("streamed"))
+	res, _ := r.send(request(PUT, body, ""))
+
+	assertCode(t, res, 503)
+	if server.calls() != 1 {
+		t.Errorf("want: 1 call; got: %d", server.calls())
+	}
+	if server.bodies[0] != "streamed" {
+		t.Errorf("want: streamed; got: %s", server.bodies[0])
+	}
+}
+
+func TestNoRetryForStreamingBodyWithLength(t *testing.T) {
+	server := &scriptedServer{replies: []any{503, 200}}
+	r, _ := newTestRetrier(server)
+	streaming := func(req RequestWriter) error {
+		if err := request(PUT, nil, "8")(req); err != nil {
+			return err
+		}
+		req.(BodyTransferWriter).BodyTransfer(true)
+		return req.Body(io.NopCloser(strings.NewReader("streamed")))
+	}
+	res, _ := r.send(streaming)
+
+	assertCode(t, res, 503)
+	if server.calls() != 1 {
+		t.Errorf("want: 1 call; got: %d", server.calls())
+	}
+}
+
+func TestRetryAfterSeconds(t *testing.T) {
+	server := &scriptedServer{replies: []any{503, 200}, retryAfter: "2"}
+	r, sleeper := newTestRetrier(server)
+	res, _ := r.send(request(GET, nil, ""))
+
+	assertCode(t, res, 200)
+	if len(sleeper.delays) != 1 || sleeper.delays[0] != 2*time.Second {
+		t.Errorf("want: 2s; got: %v", sleeper.delays)
+	}
+}
+
+func TestRetryAfterDate(t *testing.T) {
+	date := time.Now().Add(5 * time.Second).UTC().Format(http.TimeFormat)
+	server := &scriptedServer{replies: []any{503, 200}, retryAfter: date}
+	r, sleeper := newTestRetrier(server)
+	res, _ := r.send(request(GET, nil, ""))
+
+	assertCode(t, res, 200)
+	if len(sleeper.delays) != 1 {
+		t.Fatalf("want: 1 delay; got: %v", sleeper.delays)
+	}
+	if d := sleeper.delays[0]; d <= 3*time.Second || d > 5*time.Second {
+		t.Errorf("want: about 5s; got: %v", d)
+	}
+}
+
+func TestRetryAfterTooLong(t *testing.T) {
+	server := &scriptedServer{replies: []any{503, 200}, retryAfter: "3600"}
+	r, sleeper := newTestRetrier(server)
+	res, _ := r.send(request(GET, nil, ""))
+
+	assertCode(t, res, 503)
+	if len(sleeper.delays) != 0 {
+		t.Errorf("want: no delays; got: %v", sleeper.delays)
+	}
+}
+
+func TestRetryStopsWhenContextDone(t *testing.T) {
+	server := &scriptedServer{replies: []any{503, 200}}
+	r, _ := newTestRetrier(server)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	withCtx := func(req RequestWriter) error {
+		if err := request(GET, nil, "")(req); err != nil {
+			return err
+		}
+		return req.Context(ctx)
+	}
+	res, _ := r.send(withCtx)
+
+	assertCode(t, res, 503)
+	if server.calls() != 1 {
+		t.Errorf("want: 1 call; got: %d", server.calls())
+	}
+}
+
+func TestRetryCancelWhileWaiting(t *testing.T) {
+	server := &scriptedServer{replies: []any{503, 200}, retryAfter: "5"}
+	r := newRetrier(DefaultRetryPolicy(), NewSender(server.send))
+	ctx, cancel := context.WithCancel(context.Background())
+	withCtx := func(req RequestWriter) error {
+		if err := request(GET, nil, "")(req); err != nil {
+			return err
+		}
+		return req.Context(ctx)
+	}
+	time.AfterFunc(20*time.Millisecond, cancel)
+	_, err := r.send(withCtx)
+
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("want: canceled; got: %v", err)
+	}
+	if server.calls() != 1 {
+		t.Errorf("want: 1 call; got: %d", server.calls())
+	}
+}
+
+func TestRetryPropagatesBuilderError(t *testing.T) {
+	server := &scriptedServer{replies: []any{200}}
+	send := Retry(DefaultRetryPolicy())(NewSender(server.send))
+	_, err := send(func(RequestWriter) error {
+		return fmt.Errorf("boom!")
+	})
+
+	if err == nil || err.Error() != "boom!" {
+		t.Errorf("want: boom!; got: %v", err)
+	}
+	if server.calls() != 0 {
+		t.Errorf("want: no calls; got: %d", server.calls())
+	}
+}
+
+func TestBackoffBounds(t *testing.T) {
+	r := newRetrier(DefaultRetryPolicy(), nil)
+	base := DefaultRetryPolicy().BaseDelay
+	for attempt := 1; attempt < 100; attempt++ {
+		want := DefaultRetryPolicy().MaxDelay
+		if attempt < 10 {
+			if exp := base << (attempt - 1); exp < want {
+				want = exp
+			}
+		}
+		got := r.backoff(attempt)
+		if got < want/2 || got > want {
+			t.Errorf("[%d] want: [%v, %v]; got: %v", attempt, want/2, want, got)
+		}
+	}
+}
+
+func TestBackoffWithNoBaseDelay(t *testing.T) {
+	policy := DefaultRetryPolicy()
+	policy.BaseDelay = 0
+	r := newRetrier(policy, nil)
+	for attempt := 1; attempt < 5; attempt++ {
+		if got := r.backoff(attempt); got != 0 {
+			t.Errorf("[%d] want: 0; got: %v", attempt, got)
+		}
+	}
+
+	server := &scriptedServer{replies: []any{503, 200}}
+	r, sleeper := newTestRetrier(server)
+	r.policy.BaseDelay = 0
+	res, _ := r.send(request(GET, nil, ""))
+
+	assertCode(t, res, 200)
+	if len(sleeper.delays) != 1 || sleeper.delays[0] != 0 {
+		t.Errorf("want: no wait; got: %v", sleeper.delays)
+	}
+}
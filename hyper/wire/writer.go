@@ -1,6 +1,7 @@
 package wire
 
 import (
+	"context"
 	"io"
 
 	"github.com/c0c0n3/resto/yoorel"
@@ -13,17 +14,48 @@ import (
 // (Notice unlike Go's standard lib, this interface doesn't force you
 // to keep all the headers in memory.)
 type MessageWriter interface {
-	// Write a message header.
+	// Write a message header, replacing any content written earlier
+	// for the same header.
 	Header(name string, content string) error
+	// Add content to a message header, keeping any content written
+	// earlier for the same header. Use it to write repeated headers
+	// like "Cookie", "Link", "Via" and friends.
+	AddHeader(name string, content string) error
 	// Write the message body.
 	Body(content io.ReadCloser) error
 }
 
+// HeaderPeeker is a MessageWriter that can tell what got written to a
+// header so far. It's optional since a streaming MessageWriter may not
+// keep the headers around, so check for it with a type assertion.
+type HeaderPeeker interface {
+	// Read the content written so far for the given header, an empty
+	// string if none. Header names are case-insensitive.
+	PeekHeader(name string) string
+}
+
+// BodyTransferWriter is a MessageWriter that wants to know if the body
+// it's about to get is streamed. Streamed bodies may be huge and can
+// only be read once, so e.g. a Sender decorator that retries requests
+// shouldn't read them into memory. It's optional too, so check for it
+// with a type assertion.
+type BodyTransferWriter interface {
+	// Say if the body the next call to Body writes is streamed.
+	BodyTransfer(streaming bool) error
+}
+
 // Write an HTTP request.
 type RequestWriter interface {
 	MessageWriter
 	// Write the request line.
 	RequestLine(verb Method, resource yoorel.HttpUrl) error
+	// Add a key-value pair to the query part of the request URL. This
+	// works whether you write the request line before or after adding
+	// query parameters.
+	QueryParam(key string, value string) error
+	// Attach a context to the request to carry deadlines, cancellation
+	// signals and request-scoped values.
+	Context(ctx context.Context) error
 }
 
 // Write an HTTP response.
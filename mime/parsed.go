@@ -0,0 +1,178 @@
+package mime
+
+import (
+	stdmime "mime"
+	"strings"
+
+	"github.com/c0c0n3/resto/util/err"
+)
+
+// A media type that doesn't conform to RFC 6838.
+type InvalidMediaType string
+
+func invalidMediaTypeErr(value string, reason any) err.Err[InvalidMediaType] {
+	return err.Mk[InvalidMediaType]("'%s': %v", value, reason)
+}
+
+// ParsedType is a media type broken down into its components. E.g.
+// "application/vnd.api+json; charset=UTF-8" has a type of "application",
+// a subtype of "vnd.api+json", a suffix of "json" and a charset parameter
+// of "UTF-8".
+//
+// Type, subtype, suffix and parameter names are always lowercase since
+// they're case-insensitive. Parameter values are kept as they are.
+type ParsedType struct {
+	// The top-level type, e.g. "application" or "*".
+	Type string
+	// The subtype, suffix included, e.g. "json", "vnd.api+json" or "*".
+	Subtype string
+	// The structured syntax suffix without the plus sign, e.g. "json"
+	// for "application/ld+json". Empty if the subtype has no suffix.
+	Suffix string
+	// The media type parameters keyed by lowercase name.
+	Params map[string]string
+}
+
+// Parse a media type as found in a "Content-Type" header.
+func Parse(value string) (ParsedType, error) {
+	essence, params, e := stdmime.ParseMediaType(value)
+	if e != nil {
+		return ParsedType{}, invalidMediaTypeErr(value, e)
+	}
+	tpe, subtype, found := strings.Cut(essence, "/")
+	if !found {
+		return ParsedType{}, invalidMediaTypeErr(value, "no subtype")
+	}
+	if tpe == "*" && subtype != "*" {
+		return ParsedType{}, invalidMediaTypeErr(value, "wildcard type")
+	}
+	suffix := ""
+	if k := strings.LastIndex(subtype, "+"); k >= 0 {
+		suffix = subtype[k+1:]
+	}
+	return ParsedType{
+		Type:    tpe,
+		Subtype: subtype,
+		Suffix:  suffix,
+		Params:  params,
+	}, nil
+}
+
+// Parse the media type into its components.
+func (t MediaType) Parse() (ParsedType, error) {
+	return Parse(t.String())
+}
+
+// WithParam adds the given parameter to the media type, e.g.
+//
+//     JSON.WithParam("charset", "utf-8") == "application/json; charset=utf-8"
+//
+// The value gets quoted if needed.
+func (t MediaType) WithParam(name string, value string) MediaType {
+	param := stdmime.FormatMediaType("x/x", map[string]string{name: value})
+	return MediaType(t.String() + strings.TrimPrefix(param, "x/x"))
+}
+
+// Matches tells if the media type matches the given one, where either
+// can have wildcards---see ParsedType.Matches. It's false if either of
+// the two isn't a valid media type.
+func (t MediaType) Matches(other MediaType) bool {
+	pattern, e1 := t.Parse()
+	target, e2 := other.Parse()
+	if e1 != nil || e2 != nil {
+		return false
+	}
+	return pattern.Matches(target)
+}
+
+// Essence returns type and subtype without any parameters, e.g.
+// "application/json" for "application/json; charset=utf-8".
+func (p ParsedType) Essence() MediaType {
+	return MediaType(p.Type + "/" + p.Subtype)
+}
+
+// Param returns the value of the parameter with the given name or an
+// empty string if there's no such parameter. Names are case-insensitive.
+func (p ParsedType) Param(name string) string {
+	return p.Params[strings.ToLower(name)]
+}
+
+// Charset returns the value of the charset parameter, if any.
+func (p ParsedType) Charset() string {
+	return p.Param("charset")
+}
+
+// WithParam returns a copy of the media type with the given parameter
+// set to the given value.
+func (p ParsedType) WithParam(name string, value string) ParsedType {
+	params := make(map[string]string, len(p.Params)+1)
+	for k, v := range p.Params {
+		params[k] = v
+	}
+	params[strings.ToLower(name)] = value
+	p.Params = params
+	return p
+}
+
+// IsWildcard tells if the type or subtype is a wildcard.
+func (p ParsedType) IsWildcard() bool {
+	return p.Type == "*" || strings.HasPrefix(p.Subtype, "*")
+}
+
+// Equal tells if the two media types are the same. Type, subtype and
+// parameter names get compared disregarding case, parameter values are
+// case-sensitive except for the charset.
+func (p ParsedType) Equal(other ParsedType) bool {
+	return p.Essence() == other.Essence() && len(p.Params) == len(other.Params) &&
+		p.hasParamsOf(other)
+}
+
+func (p ParsedType) hasParamsOf(other ParsedType) bool {
+	for name, value := range other.Params {
+		got, ok := p.Params[name]
+		if !ok {
+			return false
+		}
+		if name == "charset" && !strings.EqualFold(got, value) {
+			return false
+		}
+		if name != "charset" && got != value {
+			return false
+		}
+	}
+	return true
+}
+
+// Matches tells if the target media type is an instance of the media
+// type or range p. A wildcard type or subtype in p matches any type or
+// subtype in target, e.g. "*/*" matches anything and "application/*"
+// matches "application/json". A subtype of "*+suffix" matches any
+// subtype with that suffix, e.g. "application/*+json" matches
+// "application/problem+json". The target has to have all the parameters
+// of p with the same values, but it can have more. So "text/plain"
+// matches "text/plain; charset=utf-8" but not the other way around.
+func (p ParsedType) Matches(target ParsedType) bool {
+	if p.Type != "*" && p.Type != target.Type {
+		return false
+	}
+	switch {
+	case p.Subtype == "*":
+	case strings.HasPrefix(p.Subtype, "*+"):
+		if strings.TrimPrefix(p.Subtype, "*+") != target.Suffix {
+			return false
+		}
+	case p.Subtype != target.Subtype:
+		return false
+	}
+	return target.hasParamsOf(p)
+}
+
+// MediaType formats the parsed media type back into a MediaType, e.g.
+// "application/json; charset=utf-8".
+func (p ParsedType) MediaType() MediaType {
+	return MediaType(p.String())
+}
+
+func (p ParsedType) String() string {
+	return stdmime.FormatMediaType(string(p.Essence()), p.Params)
+}
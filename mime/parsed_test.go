@@ -0,0 +1,116 @@
+package mime
+
+import (
+	"reflect"
+	"testing"
+
+	e "github.com/c0c0n3/resto/util/err"
+)
+
+func TestParse(t *testing.T) {
+	got, err := Parse(`Application/Vnd.API+JSON; Charset="UTF-8"; profile=x`)
+	if err != nil {
+		t.Fatalf("want: parsed type; got: %v", err)
+	}
+	want := ParsedType{
+		Type:    "application",
+		Subtype: "vnd.api+json",
+		Suffix:  "json",
+		Params:  map[string]string{"charset": "UTF-8", "profile": "x"},
+	}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("want: %v; got: %v", want, got)
+	}
+	if got.Charset() != "UTF-8" || got.Param("PROFILE") != "x" {
+		t.Errorf("want: charset and profile; got: %v", got.Params)
+	}
+	if got.Essence() != "application/vnd.api+json" {
+		t.Errorf("want: essence; got: %s", got.Essence())
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, value := range []string{"", "json", "application/", "*/json", "a/b; c"} {
+		_, err := Parse(value)
+		if _, ok := err.(e.Err[InvalidMediaType]); !ok {
+			t.Errorf("[%s] want: invalid media type; got: %v", value, err)
+		}
+	}
+}
+
+func TestParsedTypeString(t *testing.T) {
+	got, _ := JSON.WithParam("charset", "utf-8").Parse()
+	if got.String() != "application/json; charset=utf-8" {
+		t.Errorf("want: application/json; charset=utf-8; got: %s", got)
+	}
+	got = got.WithParam("Boundary", "a b")
+	want := MediaType(`application/json; boundary="a b"; charset=utf-8`)
+	if got.MediaType() != want {
+		t.Errorf("want: %s; got: %s", want, got)
+	}
+}
+
+func TestWithParamCopiesParams(t *testing.T) {
+	p, _ := Parse("text/plain; charset=utf-8")
+	q := p.WithParam("format", "flowed")
+	if len(p.Params) != 1 || len(q.Params) != 2 {
+		t.Errorf("want: copy; got: %v, %v", p.Params, q.Params)
+	}
+}
+
+var equalFixtures = []struct {
+	x, y  MediaType
+	equal bool
+}{
+	{"application/json", "Application/JSON", true},
+	{"text/plain; charset=UTF-8", "text/plain;charset=utf-8", true},
+	{"text/plain; format=Flowed", "text/plain; format=flowed", false},
+	{"text/plain", "text/plain; charset=utf-8", false},
+	{"text/plain", "text/html", false},
+}
+
+func TestEqual(t *testing.T) {
+	for k, f := range equalFixtures {
+		x, _ := f.x.Parse()
+		y, _ := f.y.Parse()
+		if got := x.Equal(y); got != f.equal {
+			t.Errorf("[%d] want: %v; got: %v", k, f.equal, got)
+		}
+	}
+}
+
+var matchFixtures = []struct {
+	pattern, target MediaType
+	match           bool
+}{
+	{"*/*", "image/png", true},
+	{"application/*", "application/json", true},
+	{"application/*", "text/plain", false},
+	{"application/*+json", "application/problem+json", true},
+	{"application/*+json", "application/json", false},
+	{"application/*+xml", "application/problem+json", false},
+	{"text/plain", "TEXT/PLAIN; charset=utf-8", true},
+	{"text/plain; charset=utf-8", "text/plain", false},
+	{"text/plain; charset=utf-8", "text/plain; charset=UTF-8", true},
+	{"text/plain", "text/html", false},
+	{"bogus", "text/plain", false},
+}
+
+func TestMatches(t *testing.T) {
+	for k, f := range matchFixtures {
+		if got := f.pattern.Matches(f.target); got != f.match {
+			t.Errorf("[%d] want: %v; got: %v", k, f.match, got)
+		}
+	}
+}
+
+func TestIsWildcard(t *testing.T) {
+	for _, mt := range []MediaType{"*/*", "text/*", "application/*+json"} {
+		if p, _ := mt.Parse(); !p.IsWildcard() {
+			t.Errorf("[%s] want: wildcard", mt)
+		}
+	}
+	if p, _ := JSON.Parse(); p.IsWildcard() {
+		t.Errorf("want: no wildcard")
+	}
+}
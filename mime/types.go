@@ -3,11 +3,16 @@ package mime
 type MediaType string
 
 const (
+	EVENT_STREAM = MediaType("text/event-stream")
 	GZIP         = MediaType("application/gzip")
 	JSON         = MediaType("application/json")
+	MULTIPART    = MediaType("multipart/form-data")
+	NDJSON       = MediaType("application/x-ndjson")
 	OCTET_STREAM = MediaType("application/octet-stream")
 	PLAIN_TEXT   = MediaType("text/plain")
+	PROBLEM_JSON = MediaType("application/problem+json")
 	URL_ENCODED  = MediaType("application/x-www-form-urlencoded")
+	XML          = MediaType("application/xml")
 	YAML         = MediaType("application/yaml")
 )
 
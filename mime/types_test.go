@@ -8,7 +8,8 @@ import (
 )
 
 var allTypes = []MediaType{
-	GZIP, JSON, OCTET_STREAM, PLAIN_TEXT, URL_ENCODED, YAML,
+	EVENT_STREAM, GZIP, JSON, NDJSON, OCTET_STREAM, PLAIN_TEXT,
+	PROBLEM_JSON, URL_ENCODED, YAML,
 }
 
 func TestDistinctMediaTypes(t *testing.T) {
@@ -0,0 +1,178 @@
+package servo
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// supportedCodings are the content codings Compress knows, in order of
+// preference.
+var supportedCodings = []string{"gzip", "deflate"}
+
+// NegotiateEncoding picks the content coding to use for a response out
+// of gzip and deflate, going by the request "Accept-Encoding" header.
+// It returns the coding the client likes the most, preferring gzip over
+// deflate if the client likes them the same, or an empty string if the
+// client takes neither.
+func NegotiateEncoding(acceptEncoding string) string {
+	explicit := map[string]float64{}
+	star, hasStar := 0.0, false
+	for _, element := range strings.Split(acceptEncoding, ",") {
+		parts := strings.Split(element, ";")
+		coding := strings.ToLower(strings.TrimSpace(parts[0]))
+		if coding == "" {
+			continue
+		}
+		if coding == "x-gzip" {
+			coding = "gzip"
+		}
+		q := 1.0
+		for _, param := range parts[1:] {
+			name, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if strings.EqualFold(strings.TrimSpace(name), "q") {
+				if v, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+					q = v
+				}
+			}
+		}
+		if coding == "*" {
+			star, hasStar = q, true
+		} else {
+			explicit[coding] = q
+		}
+	}
+
+	best, bestQ := "", 0.0
+	for _, coding := range supportedCodings {
+		q, ok := explicit[coding]
+		if !ok && hasStar {
+			q = star
+		}
+		if q > bestQ {
+			best, bestQ = coding, q
+		}
+	}
+	return best
+}
+
+// Compress decorates the given RouteHandler to compress response bodies
+// with gzip or deflate, whichever the client prefers according to the
+// request "Accept-Encoding" header---see NegotiateEncoding. Example.
+//
+//     server.Route("/data", Compress(func(w http.ResponseWriter, r *http.Request) {
+//         w.Header().Set("Content-Type", "application/json")
+//         json.NewEncoder(w).Encode(data)
+//     }))
+//
+// Compress writes a "Content-Encoding" header, drops any "Content-Length"
+// since it doesn't apply to the compressed body and adds "Accept-Encoding"
+// to the "Vary" header. It leaves the response alone if the client takes
+// neither gzip nor deflate, if the handler already encoded the body, i.e.
+// wrote its own "Content-Encoding" header, or if the response has no
+// body, e.g. a 204 or a reply to a HEAD request. The handler can still
+// flush the response as it goes, e.g. to stream data to the client.
+func Compress(handler RouteHandler) RouteHandler {
+	return func(w http.ResponseWriter, r *http.Request) {
+		coding := NegotiateEncoding(r.Header.Get("Accept-Encoding"))
+		cw := &compressWriter{
+			ResponseWriter: w,
+			coding:         coding,
+			head:           r.Method == http.MethodHead,
+		}
+		defer cw.close()
+		handler(cw, r)
+	}
+}
+
+type flushWriteCloser interface {
+	io.WriteCloser
+	Flush() error
+}
+
+type compressWriter struct {
+	http.ResponseWriter
+	coding      string
+	head        bool
+	encoder     flushWriteCloser
+	wroteHeader bool
+	status      int
+}
+
+func hasBody(status int) bool {
+	return status >= 200 && status != http.StatusNoContent &&
+		status != http.StatusNotModified
+}
+
+func (w *compressWriter) WriteHeader(status int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+
+	headers := w.Header()
+	headers.Add("Vary", "Accept-Encoding")
+	if w.coding != "" && !w.head && hasBody(status) &&
+		headers.Get("Content-Encoding") == "" {
+		headers.Set("Content-Encoding", w.coding)
+		headers.Del("Content-Length")
+		if w.coding == "gzip" {
+			w.encoder = gzip.NewWriter(w.ResponseWriter)
+		} else {
+			w.encoder = zlib.NewWriter(w.ResponseWriter)
+		}
+		w.status = status
+		return
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// If we're compressing, WriteHeader holds back the status line until
+// the first chunk of data comes in, so Write can still set the content
+// type. sendHeader passes on the status line to the wrapped writer.
+func (w *compressWriter) sendHeader() {
+	if w.status != 0 {
+		w.ResponseWriter.WriteHeader(w.status)
+		w.status = 0
+	}
+}
+
+func (w *compressWriter) Write(data []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.encoder == nil {
+		return w.ResponseWriter.Write(data)
+	}
+	// NOTE. Content sniffing. The http package sniffs the content type
+	// of the first chunk written, which would be compressed data by the
+	// time it gets there, so we sniff it ourselves.
+	if w.status != 0 && w.Header().Get("Content-Type") == "" {
+		w.Header().Set("Content-Type", http.DetectContentType(data))
+	}
+	w.sendHeader()
+	return w.encoder.Write(data)
+}
+
+func (w *compressWriter) Flush() {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	w.sendHeader()
+	if w.encoder != nil {
+		w.encoder.Flush()
+	}
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (w *compressWriter) close() {
+	w.sendHeader()
+	if w.encoder != nil {
+		w.encoder.Close()
+	}
+}
@@ -0,0 +1,180 @@
+package servo
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNegotiateEncoding(t *testing.T) {
+	cases := []struct {
+		accept string
+		want   string
+	}{
+		{"", ""},
+		{"identity", ""},
+		{"br", ""},
+		{"gzip", "gzip"},
+		{"x-gzip", "gzip"},
+		{"deflate", "deflate"},
+		{"deflate, gzip", "gzip"},
+		{"gzip;q=0.5, deflate", "deflate"},
+		{"GZIP; Q=0.2, deflate;q=0.1", "gzip"},
+		{"*", "gzip"},
+		{"*, gzip;q=0", "deflate"},
+		{"gzip;q=0, deflate;q=0", ""},
+		{"br, *;q=0.1", "gzip"},
+	}
+	for _, k := range cases {
+		if got := NegotiateEncoding(k.accept); got != k.want {
+			t.Errorf("[%s] want: %s; got: %s", k.accept, k.want, got)
+		}
+	}
+}
+
+func serveCompressed(handler RouteHandler, method string, acceptEncoding string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/data", nil)
+	if acceptEncoding != "" {
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+	}
+	res := httptest.NewRecorder()
+	Compress(handler)(res, req)
+	return res
+}
+
+func writeHowzit(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Length", "7")
+	io.WriteString(w, "howzit!")
+}
+
+func TestCompressGzip(t *testing.T) {
+	res := serveCompressed(writeHowzit, "GET", "gzip, deflate")
+
+	if got := res.Header().Get("Content-Encoding"); got != "gzip" {
+		t.Errorf("want: gzip; got: %s", got)
+	}
+	if got := res.Header().Get("Content-Length"); got != "" {
+		t.Errorf("want: no content length; got: %s", got)
+	}
+	if got := res.Header().Get("Vary"); got != "Accept-Encoding" {
+		t.Errorf("want: vary; got: %s", got)
+	}
+	if got := res.Header().Get("Content-Type"); !strings.HasPrefix(got, "text/plain") {
+		t.Errorf("want: sniffed text/plain; got: %s", got)
+	}
+	reader, err := gzip.NewReader(res.Body)
+	if err != nil {
+		t.Fatalf("want: gzip body; got: %v", err)
+	}
+	if got, _ := io.ReadAll(reader); string(got) != "howzit!" {
+		t.Errorf("want: howzit!; got: %s", got)
+	}
+}
+
+func TestCompressSniffsAfterWriteHeader(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		io.WriteString(w, "<html><body>howzit!</body></html>")
+	}
+	res := serveCompressed(handler, "GET", "gzip").Result()
+
+	if res.StatusCode != http.StatusCreated {
+		t.Errorf("want: 201; got: %d", res.StatusCode)
+	}
+	if got := res.Header.Get("Content-Type"); !strings.HasPrefix(got, "text/html") {
+		t.Errorf("want: sniffed text/html; got: %s", got)
+	}
+	if got := res.Header.Get("Content-Encoding"); got != "gzip" {
+		t.Errorf("want: gzip; got: %s", got)
+	}
+}
+
+func TestCompressDeflate(t *testing.T) {
+	res := serveCompressed(writeHowzit, "GET", "deflate")
+
+	if got := res.Header().Get("Content-Encoding"); got != "deflate" {
+		t.Errorf("want: deflate; got: %s", got)
+	}
+	reader, err := zlib.NewReader(res.Body)
+	if err != nil {
+		t.Fatalf("want: zlib body; got: %v", err)
+	}
+	if got, _ := io.ReadAll(reader); string(got) != "howzit!" {
+		t.Errorf("want: howzit!; got: %s", got)
+	}
+}
+
+func TestCompressLeavesResponseAlone(t *testing.T) {
+	encoded := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "br")
+		io.WriteString(w, "brotli")
+	}
+	noContent := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}
+	cases := []struct {
+		handler        RouteHandler
+		method         string
+		acceptEncoding string
+		wantCoding     string
+		wantBody       string
+	}{
+		{writeHowzit, "GET", "", "", "howzit!"},
+		{writeHowzit, "GET", "br", "", "howzit!"},
+		{writeHowzit, "HEAD", "gzip", "", "howzit!"},
+		{encoded, "GET", "gzip", "br", "brotli"},
+		{noContent, "GET", "gzip", "", ""},
+	}
+	for k, c := range cases {
+		res := serveCompressed(c.handler, c.method, c.acceptEncoding)
+		if got := res.Header().Get("Content-Encoding"); got != c.wantCoding {
+			t.Errorf("[%d] want: %s; got: %s", k, c.wantCoding, got)
+		}
+		if got := res.Body.String(); got != c.wantBody {
+			t.Errorf("[%d] want: %s; got: %s", k, c.wantBody, got)
+		}
+	}
+}
+
+func TestCompressFlushes(t *testing.T) {
+	chunks := make(chan string)
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(Compress(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/plain")
+			io.WriteString(w, "first")
+			w.(http.Flusher).Flush()
+			<-done
+			io.WriteString(w, "second")
+		})))
+	defer server.Close()
+
+	req, _ := http.NewRequest("GET", server.URL, nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("want: response; got: %v", err)
+	}
+	defer res.Body.Close()
+
+	reader, err := gzip.NewReader(res.Body)
+	if err != nil {
+		t.Fatalf("want: gzip body; got: %v", err)
+	}
+	go func() {
+		buf := make([]byte, 5)
+		io.ReadFull(reader, buf)
+		chunks <- string(buf)
+	}()
+	if got := <-chunks; got != "first" {
+		t.Errorf("want: first; got: %s", got)
+	}
+	close(done)
+	if rest, _ := io.ReadAll(reader); string(rest) != "second" {
+		t.Errorf("want: second; got: %s", rest)
+	}
+}
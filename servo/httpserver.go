@@ -3,6 +3,7 @@ package servo
 import (
 	"context"
 	"fmt"
+	"net"
 	"net/http"
 	"time"
 )
@@ -23,7 +24,9 @@ type HttpServer interface {
 	// Start the server in the calling thread if foreground is true or
 	// asynchronously otherwise.
 	Start(foreground bool)
-	// Stop the server asynchronously.
+	// Stop the server asynchronously. Requests in flight get the grace
+	// period to finish, except for event streams---see EventRoute---which
+	// end straight away.
 	Stop()
 	// Collect any server startup or shutdown errors, blocking the caller
 	// until the server has exited. Only call this method after calling
@@ -57,11 +60,14 @@ type hsrv struct {
 // wait shutdownGracePeriod seconds for route handlers to complete on
 // server shutdown.
 func NewHttpServer(port uint16, shutdownGracePeriod uint8) HttpServer {
+	ctx, stop := context.WithCancel(context.Background())
 	server := &http.Server{
 		Addr:    fmt.Sprintf(":%d", port),
 		Handler: http.NewServeMux(),
+		BaseContext: func(net.Listener) context.Context {
+			return context.WithValue(context.Background(), stopSignalKey{}, ctx)
+		},
 	}
-	ctx, stop := context.WithCancel(context.Background())
 
 	return &hsrv{
 		ctx:                 ctx,
@@ -73,6 +79,23 @@ func NewHttpServer(port uint16, shutdownGracePeriod uint8) HttpServer {
 	}
 }
 
+// stopSignalKey is the request context key to look up the server's stop
+// signal. We don't make it the parent of request contexts since that'd
+// cancel all the requests in flight as soon as you call Stop, whereas
+// only long-lived streams should end then, the rest should get the grace
+// period to finish.
+type stopSignalKey struct{}
+
+// stopSignal returns a channel that gets closed when Stop gets called on
+// the server handling the given request. If the request doesn't come
+// from an HttpServer, the channel is nil, i.e. never closes.
+func stopSignal(r *http.Request) <-chan struct{} {
+	if ctx, ok := r.Context().Value(stopSignalKey{}).(context.Context); ok {
+		return ctx.Done()
+	}
+	return nil
+}
+
 func (s *hsrv) Route(path string, handler RouteHandler) {
 	if mux, ok := s.svr.Handler.(*http.ServeMux); ok {
 		mux.HandleFunc(path, handler)
@@ -87,8 +110,12 @@ func (s *hsrv) serve() {
 func (s *hsrv) shutdownHandler() {
 	<-s.ctx.Done()
 
-	ctx, signalShutdown := context.WithTimeout(s.ctx, s.shutdownGracePeriod)
+	ctx, signalShutdown := context.WithTimeout(
+		context.Background(), s.shutdownGracePeriod)
 	defer signalShutdown()
+	// NOTE. Shutdown context. It can't derive from s.ctx since that's
+	// already done by now, which would make Shutdown give up on requests
+	// in flight straight away instead of waiting for the grace period.
 
 	s.shutdownOutcome <- s.svr.Shutdown(ctx)
 	close(s.shutdownOutcome)
@@ -111,3 +111,46 @@ func TestRequestRouting(t *testing.T) {
 		t.Errorf("want: %s; got: %v", howzit, got)
 	}
 }
+
+func TestStopWaitsForRequestsInFlight(t *testing.T) {
+	started := make(chan struct{})
+	target := buildHttpServer(8284)
+	target.Route("/", sayHowzit)
+	target.Route("/slow", func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		time.Sleep(300 * time.Millisecond)
+		sayHowzit(w, r)
+	})
+	target.Start(false)
+
+	var err error
+	for k := 0; k < 10; k++ {
+		time.Sleep(500 * time.Millisecond) // cater for server startup time
+		if _, err = getBody("http://localhost:8284/"); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		target.Stop()
+		t.Fatalf("want: server up; got: %v", err)
+	}
+
+	replies := make(chan string, 1)
+	go func() {
+		got, err := getBody("http://localhost:8284/slow")
+		if err != nil {
+			got = err.Error()
+		}
+		replies <- got
+	}()
+	<-started
+	target.Stop()
+	outcome := target.ExitOutcome()
+
+	if outcome.StopError != nil {
+		t.Errorf("want: shutdown within grace period; got: %v", outcome.StopError)
+	}
+	if got := <-replies; got != howzit {
+		t.Errorf("want: %s; got: %s", howzit, got)
+	}
+}
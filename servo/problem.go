@@ -0,0 +1,66 @@
+package servo
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/c0c0n3/resto/hyper"
+	"github.com/c0c0n3/resto/mime"
+	"github.com/c0c0n3/resto/util/err"
+)
+
+// ProblemOf turns a typed error into an RFC 9457 problem with the given
+// status code. The problem type and title are the name of the error
+// type, e.g. "server.NotFound" for an err.Err[server.NotFound], whereas
+// the problem detail is the error message without the type prefix.
+// Since the type is a relative URI reference, you may want to replace
+// it with an absolute URI that points to your API docs.
+func ProblemOf[T ~string](status int, e err.Err[T]) *hyper.Problem {
+	var t T
+	typeName := fmt.Sprintf("%T", t)
+	detail := strings.TrimPrefix(e.Error(), typeName+": ")
+	return &hyper.Problem{
+		Type:   typeName,
+		Title:  typeName,
+		Status: status,
+		Detail: detail,
+	}
+}
+
+// WriteProblem sends the client the given problem as an
+// "application/problem+json" response. The response status code is
+// that of the problem or 500 if the problem has none.
+func WriteProblem(w http.ResponseWriter, problem *hyper.Problem) error {
+	data, e := json.Marshal(problem)
+	if e != nil {
+		return e
+	}
+	status := http.StatusInternalServerError
+	if problem != nil && problem.Status != 0 {
+		status = problem.Status
+	}
+
+	w.Header().Set("Content-Type", mime.PROBLEM_JSON.String())
+	w.Header().Set("Content-Length", fmt.Sprintf("%d", len(data)))
+	w.WriteHeader(status)
+	_, e = w.Write(data)
+	return e
+}
+
+// ReplyWithErr sends the client the given typed error as a problem
+// response with the given status code---see ProblemOf. Example.
+//
+//     server.Route("/data", func(w http.ResponseWriter, r *http.Request) {
+//         if r.Method != "GET" {
+//             e := err.Mk[MethodNotAllowed]("%s", r.Method)
+//             ReplyWithErr(w, 405, e)
+//             return
+//         }
+//         ...
+//     })
+//
+func ReplyWithErr[T ~string](w http.ResponseWriter, status int, e err.Err[T]) error {
+	return WriteProblem(w, ProblemOf(status, e))
+}
@@ -0,0 +1,50 @@
+package servo
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/c0c0n3/resto/hyper"
+	"github.com/c0c0n3/resto/util/err"
+)
+
+type OutOfCredit string
+
+func TestReplyWithErr(t *testing.T) {
+	rec := httptest.NewRecorder()
+	e := err.Mk[OutOfCredit]("balance: %d", 30)
+	if err := ReplyWithErr(rec, 403, e); err != nil {
+		t.Fatalf("want: problem response; got: %v", err)
+	}
+
+	if rec.Code != 403 {
+		t.Errorf("want: 403; got: %d", rec.Code)
+	}
+	if got := rec.Header().Get("Content-Type"); got != "application/problem+json" {
+		t.Errorf("want: application/problem+json; got: %s", got)
+	}
+	got := &hyper.Problem{}
+	if err := json.Unmarshal(rec.Body.Bytes(), got); err != nil {
+		t.Fatalf("want: problem; got: %v", err)
+	}
+	want := hyper.Problem{
+		Type:   "servo.OutOfCredit",
+		Title:  "servo.OutOfCredit",
+		Status: 403,
+		Detail: "balance: 30",
+	}
+	if !reflect.DeepEqual(want, *got) {
+		t.Errorf("want: %v; got: %v", want, *got)
+	}
+}
+
+func TestWriteProblemWithNoStatus(t *testing.T) {
+	rec := httptest.NewRecorder()
+	WriteProblem(rec, &hyper.Problem{Title: "boom"})
+
+	if rec.Code != 500 {
+		t.Errorf("want: 500; got: %d", rec.Code)
+	}
+}
@@ -0,0 +1,270 @@
+package servo
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/c0c0n3/resto/hyper"
+	"github.com/c0c0n3/resto/mime"
+)
+
+// ReplayBuffer keeps the latest events sent on an event stream so a
+// client that reconnects can get the ones it missed. EventRoute looks
+// up the events that come after the client's "Last-Event-ID" and sends
+// them before handing over to your EventHandler. Implementations must be
+// safe for concurrent use since all the clients of a route share the
+// same buffer.
+type ReplayBuffer interface {
+	// Add keeps the given event. Events with no ID can't be replayed,
+	// so they get dropped. Since many clients may send the same event,
+	// an event with the same ID as one already in the buffer gets
+	// dropped too.
+	Add(event hyper.Event)
+	// Since returns the events that came after the one with the given
+	// ID, oldest first. It returns false if there's no such event in
+	// the buffer, e.g. because it got evicted to make room for newer
+	// ones.
+	Since(lastEventId string) ([]hyper.Event, bool)
+}
+
+type ringReplayBuffer struct {
+	mutex  sync.Mutex
+	events []hyper.Event
+	ids    map[string]bool
+	size   int
+}
+
+// NewReplayBuffer builds an in-memory ReplayBuffer that keeps the latest
+// capacity events, evicting the oldest as new ones come in. A capacity
+// less than 1 is the same as 1.
+func NewReplayBuffer(capacity int) ReplayBuffer {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &ringReplayBuffer{
+		events: make([]hyper.Event, 0, capacity),
+		ids:    make(map[string]bool),
+		size:   capacity,
+	}
+}
+
+func (b *ringReplayBuffer) Add(event hyper.Event) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if event.Id == "" || b.ids[event.Id] {
+		return
+	}
+	if len(b.events) == b.size {
+		delete(b.ids, b.events[0].Id)
+		copy(b.events, b.events[1:])
+		b.events = b.events[:len(b.events)-1]
+	}
+	b.events = append(b.events, event)
+	b.ids[event.Id] = true
+}
+
+func (b *ringReplayBuffer) Since(lastEventId string) ([]hyper.Event, bool) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	for k, event := range b.events {
+		if event.Id == lastEventId {
+			missed := make([]hyper.Event, len(b.events)-k-1)
+			copy(missed, b.events[k+1:])
+			return missed, true
+		}
+	}
+	return nil, false
+}
+
+// EventStreamConfig tells EventRoute how to run event streams.
+type EventStreamConfig struct {
+	// How often to send a comment line to keep the connection from
+	// timing out when there are no events to send. Zero or less means
+	// no keep-alives.
+	KeepAlive time.Duration
+	// The reconnection delay to ask clients to use, if positive.
+	Retry time.Duration
+	// The buffer to replay missed events from when a client reconnects,
+	// nil for no replay.
+	Replay ReplayBuffer
+}
+
+// DefaultEventStreamConfig returns an EventStreamConfig with keep-alives
+// every 15 seconds and no replay.
+func DefaultEventStreamConfig() EventStreamConfig {
+	return EventStreamConfig{KeepAlive: 15 * time.Second}
+}
+
+// EventStream sends Server-Sent Events to a client. It's safe for
+// concurrent use.
+type EventStream struct {
+	ctx         context.Context
+	mutex       sync.Mutex
+	w           http.ResponseWriter
+	flusher     http.Flusher
+	replay      ReplayBuffer
+	lastEventId string
+	replayed    bool
+}
+
+// Context returns a context that's done when the client goes away or
+// the server stops. Your EventHandler should return as soon as it's
+// done.
+func (s *EventStream) Context() context.Context {
+	return s.ctx
+}
+
+// LastEventId returns the ID of the last event the client got before
+// reconnecting, if any. Use it to catch up the client if the replay
+// buffer couldn't, e.g. from a database---see Replayed.
+func (s *EventStream) LastEventId() string {
+	return s.lastEventId
+}
+
+// Replayed tells if the client got the events it missed out of the
+// replay buffer before your EventHandler got called. It's false if the
+// client didn't send a "Last-Event-ID", there's no replay buffer or the
+// buffer doesn't have the client's last event any more, in which case
+// it's up to you to catch up the client.
+func (s *EventStream) Replayed() bool {
+	return s.replayed
+}
+
+// Send sends the given event to the client right away, keeping it in
+// the replay buffer too if there's one. It returns an error if the
+// event is invalid, the stream is done or the client can't be reached.
+func (s *EventStream) Send(event hyper.Event) error {
+	data, err := event.MarshalText()
+	if err != nil {
+		return err
+	}
+	if s.replay != nil {
+		s.replay.Add(event)
+	}
+	return s.write(data)
+}
+
+func (s *EventStream) write(data []byte) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.ctx.Err(); err != nil {
+		return err
+	}
+	if _, err := s.w.Write(data); err != nil {
+		return err
+	}
+	s.flusher.Flush()
+	return nil
+}
+
+func (s *EventStream) keepAlive(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+			if s.write([]byte(": keep-alive\n\n")) != nil {
+				return
+			}
+		}
+	}
+}
+
+// EventHandler sends events to a client through the given EventStream
+// until it's done or the stream's context is.
+type EventHandler func(stream *EventStream, r *http.Request)
+
+// EventRoute turns the given EventHandler into a RouteHandler that
+// streams Server-Sent Events to the client. Example.
+//
+//     config := DefaultEventStreamConfig()
+//     config.Replay = NewReplayBuffer(100)
+//     server.Route("/status", EventRoute(config,
+//         func(stream *EventStream, r *http.Request) {
+//             for {
+//                 select {
+//                 case <-stream.Context().Done():
+//                     return
+//                 case s := <-statusUpdates:
+//                     stream.Send(hyper.Event{Id: s.Id, Data: s.Text})
+//                 }
+//             }
+//         }))
+//
+// The RouteHandler writes the "text/event-stream" headers and then, if
+// the client sent a "Last-Event-ID" header and there's a replay buffer,
+// it sends the events the client missed before calling the EventHandler.
+// While the EventHandler runs, keep-alive comments go out on a timer.
+// The stream's context is done when the client disconnects or, if the
+// route belongs to an HttpServer, when you call Stop on the server, so
+// open streams don't hold up shutdown.
+func EventRoute(config EventStreamConfig, handler EventHandler) RouteHandler {
+	return func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "streaming not supported", http.StatusInternalServerError)
+			return
+		}
+
+		ctx, cancel := context.WithCancel(r.Context())
+		defer cancel()
+		go func() {
+			select {
+			case <-stopSignal(r):
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+
+		stream := &EventStream{
+			ctx:         ctx,
+			w:           w,
+			flusher:     flusher,
+			replay:      config.Replay,
+			lastEventId: r.Header.Get("Last-Event-ID"),
+		}
+		headers := w.Header()
+		headers.Set("Content-Type", mime.EVENT_STREAM.String())
+		headers.Set("Cache-Control", "no-cache")
+		headers.Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+		if config.Retry > 0 {
+			io.WriteString(w, "retry: "+strconv.FormatInt(config.Retry.Milliseconds(), 10)+"\n\n")
+		}
+		flusher.Flush()
+
+		if config.Replay != nil && stream.lastEventId != "" {
+			missed, found := config.Replay.Since(stream.lastEventId)
+			for _, event := range missed {
+				if err := stream.Send(event); err != nil {
+					return
+				}
+			}
+			stream.replayed = found
+		}
+
+		var keepAlive sync.WaitGroup
+		if config.KeepAlive > 0 {
+			keepAlive.Add(1)
+			go func() {
+				defer keepAlive.Done()
+				stream.keepAlive(config.KeepAlive)
+			}()
+		}
+		handler(stream, r)
+
+		stream.mutex.Lock()
+		cancel() // no more writes after we return
+		stream.mutex.Unlock()
+		keepAlive.Wait()
+	}
+}
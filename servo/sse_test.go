@@ -0,0 +1,238 @@
+package servo
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/c0c0n3/resto/hyper"
+)
+
+var errEnough = errors.New("enough")
+
+// readEvents connects to the event stream at the given URL and reads n
+// events, then hangs up.
+func readEvents(t *testing.T, url string, lastEventId string, n int) (*http.Response, []hyper.Event) {
+	ctx, hangUp := context.WithCancel(context.Background())
+	defer hangUp()
+	req, _ := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if lastEventId != "" {
+		req.Header.Set("Last-Event-ID", lastEventId)
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("want: event stream; got: %v", err)
+	}
+	defer res.Body.Close()
+
+	got := []hyper.Event{}
+	decoder := &hyper.EventDecoder{
+		Each: func(event hyper.Event) error {
+			got = append(got, event)
+			if len(got) == n {
+				return errEnough
+			}
+			return nil
+		},
+	}
+	if err := decoder.Deserialize(res.Body); err != errEnough {
+		t.Fatalf("want: %d events; got: %v, %v", n, got, err)
+	}
+	return res, got
+}
+
+func TestEventRoute(t *testing.T) {
+	done := make(chan struct{})
+	config := EventStreamConfig{Retry: 2 * time.Second}
+	server := httptest.NewServer(http.HandlerFunc(EventRoute(config,
+		func(stream *EventStream, r *http.Request) {
+			stream.Send(hyper.Event{Id: "1", Data: "a"})
+			stream.Send(hyper.Event{Id: "2", Type: "tick", Data: "b"})
+			<-stream.Context().Done()
+			close(done)
+		})))
+	defer server.Close()
+
+	res, got := readEvents(t, server.URL, "", 2)
+
+	if got[0].Data != "a" || got[1].Type != "tick" || got[1].Id != "2" {
+		t.Errorf("want: a, tick b; got: %v", got)
+	}
+	if res.Header.Get("Content-Type") != "text/event-stream" ||
+		res.Header.Get("Cache-Control") != "no-cache" {
+		t.Errorf("want: event stream headers; got: %v", res.Header)
+	}
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Errorf("want: stream done on disconnect; got: still running")
+	}
+}
+
+func TestEventRouteKeepAlive(t *testing.T) {
+	config := EventStreamConfig{KeepAlive: 10 * time.Millisecond}
+	server := httptest.NewServer(http.HandlerFunc(EventRoute(config,
+		func(stream *EventStream, r *http.Request) {
+			<-stream.Context().Done()
+		})))
+	defer server.Close()
+
+	res, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatalf("want: event stream; got: %v", err)
+	}
+	defer res.Body.Close()
+
+	lines := bufio.NewScanner(res.Body)
+	for k := 0; k < 2; k++ {
+		lines.Scan()
+		if got := lines.Text(); got != ": keep-alive" {
+			t.Fatalf("want: keep-alive; got: %s", got)
+		}
+		lines.Scan() // blank line
+	}
+}
+
+func TestEventRouteReplay(t *testing.T) {
+	replay := NewReplayBuffer(10)
+	for _, id := range []string{"1", "2", "3"} {
+		replay.Add(hyper.Event{Id: id, Data: id})
+	}
+	config := EventStreamConfig{Replay: replay}
+	lastEventIds := make(chan string, 1)
+	replayed := make(chan bool, 1)
+	server := httptest.NewServer(http.HandlerFunc(EventRoute(config,
+		func(stream *EventStream, r *http.Request) {
+			lastEventIds <- stream.LastEventId()
+			replayed <- stream.Replayed()
+			stream.Send(hyper.Event{Id: "4", Data: "4"})
+			<-stream.Context().Done()
+		})))
+	defer server.Close()
+
+	_, got := readEvents(t, server.URL, "1", 3)
+
+	data := []string{}
+	for _, event := range got {
+		data = append(data, event.Data)
+	}
+	if strings.Join(data, " ") != "2 3 4" {
+		t.Errorf("want: 2 3 4; got: %v", data)
+	}
+	if id := <-lastEventIds; id != "1" {
+		t.Errorf("want: 1; got: %s", id)
+	}
+	if !<-replayed {
+		t.Errorf("want: replayed; got: not replayed")
+	}
+	if missed, _ := replay.Since("3"); len(missed) != 1 || missed[0].Id != "4" {
+		t.Errorf("want: 4 in replay buffer; got: %v", missed)
+	}
+
+	readEvents(t, server.URL, "evicted", 1)
+	<-lastEventIds
+	if <-replayed {
+		t.Errorf("want: not replayed; got: replayed")
+	}
+}
+
+func TestEventStreamSendInvalidEvent(t *testing.T) {
+	errs := make(chan error, 1)
+	handler := EventRoute(EventStreamConfig{},
+		func(stream *EventStream, r *http.Request) {
+			errs <- stream.Send(hyper.Event{Id: "a\nb"})
+		})
+	handler(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+	if err := <-errs; err == nil {
+		t.Errorf("want: invalid event error; got: nil")
+	}
+}
+
+func TestEventStreamSendAfterDone(t *testing.T) {
+	streams := make(chan *EventStream, 1)
+	handler := EventRoute(EventStreamConfig{},
+		func(stream *EventStream, r *http.Request) {
+			streams <- stream
+		})
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest("GET", "/", nil))
+
+	if err := (<-streams).Send(hyper.Event{Data: "late"}); err == nil {
+		t.Errorf("want: error; got: nil")
+	}
+	if strings.Contains(rec.Body.String(), "late") {
+		t.Errorf("want: no late event; got: %s", rec.Body.String())
+	}
+}
+
+func TestReplayBuffer(t *testing.T) {
+	replay := NewReplayBuffer(2)
+	replay.Add(hyper.Event{Data: "no id"})
+	replay.Add(hyper.Event{Id: "1"})
+	replay.Add(hyper.Event{Id: "2"})
+	replay.Add(hyper.Event{Id: "2", Data: "dup"})
+
+	if missed, ok := replay.Since("1"); !ok || len(missed) != 1 || missed[0].Data != "" {
+		t.Errorf("want: event 2; got: %v, %v", missed, ok)
+	}
+	replay.Add(hyper.Event{Id: "3"})
+	if _, ok := replay.Since("1"); ok {
+		t.Errorf("want: 1 evicted; got: still there")
+	}
+	if missed, ok := replay.Since("3"); !ok || len(missed) != 0 {
+		t.Errorf("want: nothing missed; got: %v, %v", missed, ok)
+	}
+	replay.Add(hyper.Event{Id: "1"})
+	if missed, ok := replay.Since("3"); !ok || len(missed) != 1 || missed[0].Id != "1" {
+		t.Errorf("want: event 1 again; got: %v, %v", missed, ok)
+	}
+}
+
+func TestStopEndsEventStreams(t *testing.T) {
+	target := buildHttpServer(8283)
+	ended := make(chan struct{})
+	target.Route("/events", EventRoute(DefaultEventStreamConfig(),
+		func(stream *EventStream, r *http.Request) {
+			stream.Send(hyper.Event{Data: "hi"})
+			<-stream.Context().Done()
+			close(ended)
+		}))
+	target.Start(false)
+
+	var res *http.Response
+	var err error
+	for k := 0; k < 10; k++ {
+		time.Sleep(200 * time.Millisecond) // cater for server startup time
+		if res, err = http.Get("http://localhost:8283/events"); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		target.Stop()
+		t.Fatalf("want: event stream; got: %v", err)
+	}
+	defer res.Body.Close()
+	bufio.NewReader(res.Body).ReadString('\n') // stream is up
+
+	start := time.Now()
+	target.Stop()
+	outcome := target.ExitOutcome()
+
+	if outcome.StopError != nil {
+		t.Errorf("want: clean shutdown; got: %v", outcome.StopError)
+	}
+	if elapsed := time.Since(start); elapsed >= time.Second {
+		t.Errorf("want: shutdown before grace period; got: %v", elapsed)
+	}
+	select {
+	case <-ended:
+	default:
+		t.Errorf("want: stream ended; got: still running")
+	}
+}